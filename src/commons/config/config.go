@@ -9,8 +9,74 @@ import (
 )
 
 type Config struct {
-	JWTSecret string
-	Port      int
+	JWTSecret                      string
+	JWTAlgorithm                   string
+	JWTKeyID                       string
+	JWTPrivateKeyFile              string
+	JWTVerificationKeys            string
+	JWTIssuer                      string
+	JWTAudience                    string
+	RevocationStore                string
+	RevocationPurgeIntervalMinutes int
+	AdminSeedCPF                   string
+	AdminSeedFirstName             string
+	AdminSeedLastName              string
+	AdminSeedPassword              string
+	AdminSeedPasswordFile          string
+	PolicyFile                     string
+	PasswordResetTTLMinutes        int
+	LoginMaxAttempts               int
+	LoginMaxAttemptsPerIP          int
+	LoginLockoutBaseSeconds        int
+	LoginLockoutMaxSeconds         int
+	ProxyHeader                    string
+	TrustedProxies                 string
+	TOTPIssuer                     string
+	Argon2Time                     int
+	Argon2MemoryKiB                int
+	Argon2Threads                  int
+	Argon2KeyLength                int
+	Argon2MaxConcurrent            int
+	Argon2MaxQueue                 int
+	Argon2QueueTimeoutMs           int
+	PasswordPepperID               string
+	PasswordPeppers                string
+	PasswordPeppersFile            string
+	PasswordMinLength              int
+	PasswordMaxLength              int
+	PasswordMinCharacterClasses    int
+	PasswordBreachedFile           string
+	PasswordHistorySize            int
+	PasswordMaxAgeDays             int
+	SessionCookieMode              string
+	SessionCookieSecure            bool
+	SessionCookieSameSite          string
+	SessionCookieDomain            string
+	AppBaseURL                     string
+	EmailVerificationTTLMinutes    int
+	Mailer                         string
+	MailFrom                       string
+	MailDir                        string
+	SMTPHost                       string
+	SMTPPort                       int
+	SMTPUsername                   string
+	SMTPPassword                   string
+	PasswordlessCodeTTLMinutes     int
+	PasswordlessMaxRequests        int
+	PasswordlessWindowMinutes      int
+	LDAPURL                        string
+	LDAPStartTLS                   bool
+	LDAPInsecureSkipVerify         bool
+	LDAPBindDN                     string
+	LDAPBindPassword               string
+	LDAPBaseDN                     string
+	LDAPUserFilter                 string
+	LDAPAttributeCPF               string
+	LDAPAttributeFirstName         string
+	LDAPAttributeLastName          string
+	LDAPAttributeEmail             string
+	LDAPTimeoutSeconds             int
+	Port                           int
 }
 
 func LoadConfig() *Config {
@@ -21,7 +87,105 @@ func LoadConfig() *Config {
 
 	return &Config{
 		JWTSecret: getEnv("JWT_SECRET", "api_secret"),
-		Port:      getEnvAsInt("PORT", 3333),
+		// Com JWT_PRIVATE_KEY_FILE definido, os tokens são assinados com a chave assimétrica (RS256, ES256 ou EdDSA);
+		// sem JWT_ALGORITHM o algoritmo é deduzido do tipo da chave
+		JWTAlgorithm:      getEnv("JWT_ALGORITHM", ""),
+		JWTKeyID:          getEnv("JWT_KEY_ID", "default"),
+		JWTPrivateKeyFile: getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		// JWTVerificationKeys lista chaves públicas adicionais no formato "kid=arquivo.pem,kid2=arquivo2.pem"
+		JWTVerificationKeys: getEnv("JWT_VERIFICATION_KEYS", ""),
+		// Para o OpenID Connect, JWTIssuer deve ser a URL pública do servidor e a assinatura deve ser assimétrica,
+		// pois os clientes validam os ID tokens pelo JWKS; com JWT_SECRET o OpenID Connect fica desativado
+		JWTIssuer:   getEnv("JWT_ISSUER", "server"),
+		JWTAudience: getEnv("JWT_AUDIENCE", "server"),
+		// RevocationStore aceita "database" (padrão) ou "memory"
+		RevocationStore:                getEnv("REVOCATION_STORE", "database"),
+		RevocationPurgeIntervalMinutes: getEnvAsInt("REVOCATION_PURGE_INTERVAL_MINUTES", 60),
+		// ADMIN_SEED_* criam o administrador inicial na inicialização; o cadastro público nunca concede papéis
+		AdminSeedCPF:          getEnv("ADMIN_SEED_CPF", ""),
+		AdminSeedFirstName:    getEnv("ADMIN_SEED_FIRST_NAME", ""),
+		AdminSeedLastName:     getEnv("ADMIN_SEED_LAST_NAME", ""),
+		AdminSeedPassword:     getEnv("ADMIN_SEED_PASSWORD", ""),
+		AdminSeedPasswordFile: getEnv("ADMIN_SEED_PASSWORD_FILE", ""),
+		// PolicyFile aponta para um arquivo .json/.yaml de políticas de acesso; vazio usa as regras padrão
+		PolicyFile:              getEnv("POLICY_FILE", ""),
+		PasswordResetTTLMinutes: getEnvAsInt("PASSWORD_RESET_TTL_MINUTES", 30),
+		// Após LOGIN_MAX_ATTEMPTS falhas por conta (ou LOGIN_MAX_ATTEMPTS_PER_IP por IP) o login é bloqueado,
+		// começando em LOGIN_LOCKOUT_BASE_SECONDS e dobrando a cada nova falha até LOGIN_LOCKOUT_MAX_SECONDS
+		LoginMaxAttempts:        getEnvAsInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginMaxAttemptsPerIP:   getEnvAsInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		LoginLockoutBaseSeconds: getEnvAsInt("LOGIN_LOCKOUT_BASE_SECONDS", 30),
+		LoginLockoutMaxSeconds:  getEnvAsInt("LOGIN_LOCKOUT_MAX_SECONDS", 3600),
+		// Atrás de um proxy reverso, PROXY_HEADER (ex.: X-Real-IP) é o header com o IP do cliente usado no bloqueio
+		// por IP; ele só é lido nas conexões vindas de TRUSTED_PROXIES (IPs ou CIDRs separados por vírgula)
+		ProxyHeader:    getEnv("PROXY_HEADER", ""),
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+		// TOTPIssuer é o nome exibido nos aplicativos autenticadores
+		TOTPIssuer: getEnv("TOTP_ISSUER", "server"),
+		// Parâmetros de custo do Argon2id; são gravados em cada hash e podem ser alterados a qualquer momento
+		Argon2Time:      getEnvAsInt("ARGON2_TIME", 1),
+		Argon2MemoryKiB: getEnvAsInt("ARGON2_MEMORY_KIB", 64*1024),
+		Argon2Threads:   getEnvAsInt("ARGON2_THREADS", 4),
+		Argon2KeyLength: getEnvAsInt("ARGON2_KEY_LENGTH", 32),
+		// Cada cálculo aloca ARGON2_MEMORY_KIB; acima de ARGON2_MAX_CONCURRENT os pedidos esperam na fila
+		// e, com a fila cheia ou após o timeout, recebem 503. ARGON2_MAX_CONCURRENT=0 desativa o limite
+		Argon2MaxConcurrent:  getEnvAsInt("ARGON2_MAX_CONCURRENT", 4),
+		Argon2MaxQueue:       getEnvAsInt("ARGON2_MAX_QUEUE", 32),
+		Argon2QueueTimeoutMs: getEnvAsInt("ARGON2_QUEUE_TIMEOUT_MS", 2000),
+		// PASSWORD_PEPPER_ID é a versão do pepper usada nos novos hashes; as versões anteriores devem continuar
+		// em PASSWORD_PEPPERS ("v1=segredo,v2=segredo") ou no arquivo PASSWORD_PEPPERS_FILE (uma por linha)
+		// até que todos os usuários façam login novamente
+		PasswordPepperID:    getEnv("PASSWORD_PEPPER_ID", ""),
+		PasswordPeppers:     getEnv("PASSWORD_PEPPERS", ""),
+		PasswordPeppersFile: getEnv("PASSWORD_PEPPERS_FILE", ""),
+		// Política de senhas; PASSWORD_MIN_CHARACTER_CLASSES conta minúsculas, maiúsculas, números e símbolos
+		PasswordMinLength:           getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:           getEnvAsInt("PASSWORD_MAX_LENGTH", 128),
+		PasswordMinCharacterClasses: getEnvAsInt("PASSWORD_MIN_CHARACTER_CLASSES", 3),
+		// PasswordBreachedFile aponta para um arquivo de hashes SHA-1 ordenados (export do Have I Been Pwned);
+		// vazio desativa a consulta de senhas vazadas
+		PasswordBreachedFile: getEnv("PASSWORD_BREACHED_FILE", ""),
+		// PASSWORD_HISTORY_SIZE senhas, incluindo a atual, não podem ser repetidas (0 desativa);
+		// com PASSWORD_MAX_AGE_DAYS > 0 a senha expira e o login só libera a troca de senha
+		PasswordHistorySize: getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+		PasswordMaxAgeDays:  getEnvAsInt("PASSWORD_MAX_AGE_DAYS", 0),
+		// SESSION_COOKIE_MODE define a entrega dos tokens em cookies HttpOnly para navegadores: "disabled",
+		// "optional" (padrão; o cliente escolhe com o header "X-Auth-Mode: cookie") ou "always"
+		SessionCookieMode:     getEnv("SESSION_COOKIE_MODE", "optional"),
+		SessionCookieSecure:   getEnvAsBool("SESSION_COOKIE_SECURE", true),
+		SessionCookieSameSite: getEnv("SESSION_COOKIE_SAMESITE", "Strict"),
+		SessionCookieDomain:   getEnv("SESSION_COOKIE_DOMAIN", ""),
+		// APP_BASE_URL é o endereço público da api, usado nos links enviados por e-mail
+		AppBaseURL:                  getEnv("APP_BASE_URL", "http://localhost:3333"),
+		EmailVerificationTTLMinutes: getEnvAsInt("EMAIL_VERIFICATION_TTL_MINUTES", 24*60),
+		// MAILER escolhe o envio de e-mails: "smtp", "file" (padrão; grava arquivos .eml em MAIL_DIR) ou "memory"
+		Mailer:       getEnv("MAILER", "file"),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailDir:      getEnv("MAIL_DIR", "mail"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		// Login sem senha, liberado pelo papel backoffice: o link e o código valem PASSWORDLESS_CODE_TTL_MINUTES
+		// e cada usuário pode pedir até PASSWORDLESS_MAX_REQUESTS envios a cada PASSWORDLESS_WINDOW_MINUTES
+		PasswordlessCodeTTLMinutes: getEnvAsInt("PASSWORDLESS_CODE_TTL_MINUTES", 5),
+		PasswordlessMaxRequests:    getEnvAsInt("PASSWORDLESS_MAX_REQUESTS", 3),
+		PasswordlessWindowMinutes:  getEnvAsInt("PASSWORDLESS_WINDOW_MINUTES", 15),
+		// Login pelo diretório corporativo, desativado sem LDAP_URL: a conta de serviço localiza a entrada pelo
+		// LDAP_USER_FILTER (%s é o identificador) e os atributos LDAP_ATTR_* provisionam o usuário no primeiro login
+		LDAPURL:                getEnv("LDAP_URL", ""),
+		LDAPStartTLS:           getEnvAsBool("LDAP_START_TLS", false),
+		LDAPInsecureSkipVerify: getEnvAsBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		LDAPBindDN:             getEnv("LDAP_BIND_DN", ""),
+		LDAPBindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:             getEnv("LDAP_BASE_DN", ""),
+		LDAPUserFilter:         getEnv("LDAP_USER_FILTER", "(uid=%s)"),
+		LDAPAttributeCPF:       getEnv("LDAP_ATTR_CPF", "employeeNumber"),
+		LDAPAttributeFirstName: getEnv("LDAP_ATTR_FIRST_NAME", "givenName"),
+		LDAPAttributeLastName:  getEnv("LDAP_ATTR_LAST_NAME", "sn"),
+		LDAPAttributeEmail:     getEnv("LDAP_ATTR_EMAIL", "mail"),
+		LDAPTimeoutSeconds:     getEnvAsInt("LDAP_TIMEOUT_SECONDS", 5),
+		Port:                   getEnvAsInt("PORT", 3333),
 	}
 }
 
@@ -45,3 +209,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+// getEnvAsBool tenta obter e converter uma variável de ambiente para bool, ou retorna um valor padrão.
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("erro ao converter %s em bool: %v, usando valor padrão: %t", key, err, defaultValue)
+		return defaultValue
+	}
+	return value
+}
@@ -64,3 +64,21 @@ func TestGetEnvAsIntWithInvalidValue(t *testing.T) {
 		t.Errorf("Expected 1234, but got %d due to invalid int conversion", value)
 	}
 }
+
+func TestGetEnvAsBoolWithSetValue(t *testing.T) {
+	os.Setenv("TEST_BOOL_ENV", "false")
+
+	value := getEnvAsBool("TEST_BOOL_ENV", true)
+	if value {
+		t.Errorf("Expected false, but got %t", value)
+	}
+}
+
+func TestGetEnvAsBoolWithInvalidValue(t *testing.T) {
+	os.Setenv("TEST_BOOL_ENV", "invalid_bool")
+
+	value := getEnvAsBool("TEST_BOOL_ENV", true)
+	if !value {
+		t.Errorf("Expected true, but got %t due to invalid bool conversion", value)
+	}
+}
@@ -1,78 +1,286 @@
 package shared
 
 import (
-	"bytes"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/argon2"
 )
 
 const (
 	// Constantes de configuração para o Argon2. Ajuste conforme necessário.
+	// Também são os parâmetros implícitos dos hashes legados no formato salt$hash.
 	SaltSize  = 16
 	KeySize   = 32
 	Time      = 1
 	Memory    = 64 * 1024
 	Threads   = 4
 	separator = "$"
+
+	argon2idPrefix = "$argon2id$"
 )
 
-type Argon2Manager struct{}
+// Argon2Params reúne os parâmetros de custo do Argon2id. Eles são gravados em cada hash,
+// então podem ser alterados sem invalidar as senhas existentes.
+type Argon2Params struct {
+	Time    uint32
+	Memory  uint32 // em KiB
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// DefaultArgon2Params retorna os parâmetros padrão, equivalentes às constantes do pacote.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Time:    Time,
+		Memory:  Memory,
+		Threads: Threads,
+		KeyLen:  KeySize,
+		SaltLen: SaltSize,
+	}
+}
+
+type Argon2Manager struct {
+	params   Argon2Params
+	limiter  *hashLimiter
+	pepperID string
+	peppers  map[string][]byte
+}
 
 func NewArgon2Manager() *Argon2Manager {
-	return &Argon2Manager{}
+	return NewArgon2ManagerWithParams(DefaultArgon2Params())
+}
+
+// NewArgon2ManagerWithParams cria um Argon2Manager que gera hashes com os parâmetros informados.
+// Campos zerados assumem os valores padrão.
+func NewArgon2ManagerWithParams(params Argon2Params) *Argon2Manager {
+	return NewArgon2ManagerWithLimits(params, Argon2Limits{})
+}
+
+// NewArgon2ManagerWithLimits cria um Argon2Manager que limita os cálculos simultâneos conforme Argon2Limits.
+func NewArgon2ManagerWithLimits(params Argon2Params, limits Argon2Limits) *Argon2Manager {
+	defaults := DefaultArgon2Params()
+	if params.Time == 0 {
+		params.Time = defaults.Time
+	}
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Threads == 0 {
+		params.Threads = defaults.Threads
+	}
+	if params.KeyLen == 0 {
+		params.KeyLen = defaults.KeyLen
+	}
+	if params.SaltLen == 0 {
+		params.SaltLen = defaults.SaltLen
+	}
+	return &Argon2Manager{params: params, limiter: newHashLimiter(limits)}
+}
+
+// Params retorna os parâmetros usados nos novos hashes.
+func (a *Argon2Manager) Params() Argon2Params {
+	return a.params
+}
+
+// Metrics retorna as métricas de fila e de latência dos cálculos de hash.
+func (a *Argon2Manager) Metrics() Argon2Metrics {
+	return a.limiter.metrics()
+}
+
+// deriveKey calcula a chave Argon2id respeitando o limite de concorrência.
+func (a *Argon2Manager) deriveKey(password []byte, salt []byte, params Argon2Params) ([]byte, error) {
+	if err := a.limiter.acquire(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	key := argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+	a.limiter.release(time.Since(start))
+	return key, nil
 }
 
 // generateSalt cria um novo salt aleatório
 func (a *Argon2Manager) generateSalt() ([]byte, error) {
-	salt := make([]byte, SaltSize)
+	salt := make([]byte, a.params.SaltLen)
 	if _, err := rand.Read(salt); err != nil {
 		return nil, err
 	}
 	return salt, nil
 }
 
-// HashPassword cria e retorna um hash Argon2 da senha fornecida
+// HashPassword cria e retorna um hash Argon2id da senha no formato PHC:
+// $argon2id$v=19$m=65536,t=1,p=4$salt$hash
+// Com um pepper configurado, a versão dele é gravada no parâmetro keyid: $argon2id$v=19$m=65536,t=1,p=4,keyid=v1$salt$hash
 func (a *Argon2Manager) HashPassword(password string) (string, error) {
 	salt, err := a.generateSalt()
 	if err != nil {
 		return "", err
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, Time, Memory, Threads, KeySize)
+	input, err := a.applyPepper(password, a.pepperID)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := a.deriveKey(input, salt, a.params)
+	if err != nil {
+		return "", err
+	}
+
+	parameters := fmt.Sprintf("m=%d,t=%d,p=%d", a.params.Memory, a.params.Time, a.params.Threads)
+	if a.pepperID != "" {
+		parameters += ",keyid=" + a.pepperID
+	}
 
-	// O hash retornado é uma combinação do salt e hash, separados por "$"
-	encodedSalt := base64.RawStdEncoding.EncodeToString(salt)
-	encodedHash := base64.RawStdEncoding.EncodeToString(hash)
-	return encodedSalt + separator + encodedHash, nil
+	return fmt.Sprintf("%sv=%d$%s$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		parameters,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
 }
 
-// VerifyPassword verifica se a senha fornecida corresponde ao hash Argon2 fornecido
+// VerifyPassword verifica se a senha fornecida corresponde ao hash Argon2 fornecido.
+// Aceita o formato PHC, com ou sem pepper, e o formato legado salt$hash.
 func (a *Argon2Manager) VerifyPassword(password, encodedHash string) (bool, error) {
-	parts := strings.Split(encodedHash, separator)
-	if len(parts) != 2 {
-		return false, errors.New("invalid hash format")
+	decoded, err := decodeArgon2Hash(encodedHash)
+	if err != nil {
+		return false, err
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[0])
+	input, err := a.applyPepper(password, decoded.pepperID)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode salt: %w", err)
+		return false, err
 	}
 
-	expectedHash, err := base64.RawStdEncoding.DecodeString(parts[1])
+	calculatedHash, err := a.deriveKey(input, decoded.salt, decoded.params)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode expected hash: %w", err)
+		return false, err
 	}
 
-	calculatedHash := argon2.IDKey([]byte(password), salt, Time, Memory, Threads, KeySize)
-
-	if !bytes.Equal(calculatedHash, expectedHash) {
+	if subtle.ConstantTimeCompare(calculatedHash, decoded.hash) != 1 {
 		return false, errors.New("hash does not match")
 	}
 
 	return true, nil
 }
+
+// NeedsRehash informa se o hash está no formato legado, foi gerado com parâmetros diferentes dos atuais
+// ou com outra versão do pepper.
+func (a *Argon2Manager) NeedsRehash(encodedHash string) bool {
+	if !strings.HasPrefix(encodedHash, argon2idPrefix) {
+		return true
+	}
+
+	decoded, err := decodeArgon2Hash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return decoded.params.Time != a.params.Time ||
+		decoded.params.Memory != a.params.Memory ||
+		decoded.params.Threads != a.params.Threads ||
+		decoded.params.KeyLen != a.params.KeyLen ||
+		decoded.params.SaltLen != a.params.SaltLen ||
+		decoded.pepperID != a.pepperID
+}
+
+// decodedArgon2Hash reúne as partes de um hash Argon2 armazenado.
+type decodedArgon2Hash struct {
+	params   Argon2Params
+	pepperID string
+	salt     []byte
+	hash     []byte
+}
+
+// decodeArgon2Hash extrai os parâmetros, a versão do pepper, o salt e o hash de um hash PHC ou legado.
+func decodeArgon2Hash(encodedHash string) (*decodedArgon2Hash, error) {
+	if !strings.HasPrefix(encodedHash, argon2idPrefix) {
+		return decodeLegacyArgon2Hash(encodedHash)
+	}
+
+	parts := strings.Split(encodedHash, separator)
+	if len(parts) != 6 {
+		return nil, errors.New("invalid hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("failed to parse version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	decoded := &decodedArgon2Hash{}
+	for _, parameter := range strings.Split(parts[3], ",") {
+		name, value, _ := strings.Cut(parameter, "=")
+
+		var err error
+		switch name {
+		case "m":
+			_, err = fmt.Sscanf(value, "%d", &decoded.params.Memory)
+		case "t":
+			_, err = fmt.Sscanf(value, "%d", &decoded.params.Time)
+		case "p":
+			_, err = fmt.Sscanf(value, "%d", &decoded.params.Threads)
+		case "keyid":
+			decoded.pepperID = value
+		default:
+			err = fmt.Errorf("unknown parameter %q", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+		}
+	}
+	if decoded.params.Memory == 0 || decoded.params.Time == 0 || decoded.params.Threads == 0 {
+		return nil, errors.New("failed to parse parameters: missing m, t or p")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode expected hash: %w", err)
+	}
+
+	decoded.salt = salt
+	decoded.hash = hash
+	decoded.params.SaltLen = uint32(len(salt))
+	decoded.params.KeyLen = uint32(len(hash))
+	return decoded, nil
+}
+
+// decodeLegacyArgon2Hash lê o formato salt$hash, gerado com as constantes do pacote e sem pepper.
+func decodeLegacyArgon2Hash(encodedHash string) (*decodedArgon2Hash, error) {
+	parts := strings.Split(encodedHash, separator)
+	if len(parts) != 2 {
+		return nil, errors.New("invalid hash format")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode expected hash: %w", err)
+	}
+
+	params := DefaultArgon2Params()
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(hash))
+	return &decodedArgon2Hash{params: params, salt: salt, hash: hash}, nil
+}
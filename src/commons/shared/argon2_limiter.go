@@ -0,0 +1,149 @@
+package shared
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrHasherBusy indica que o limite de cálculos simultâneos e a fila do Argon2 estão esgotados.
+var ErrHasherBusy = errors.New("serviço de senhas sobrecarregado, tente novamente em instantes")
+
+// HasherBusyError é retornado quando o cálculo do hash foi recusado por excesso de carga.
+type HasherBusyError struct {
+	RetryAfter time.Duration
+}
+
+func (e *HasherBusyError) Error() string {
+	return ErrHasherBusy.Error()
+}
+
+// Is permite comparar o erro com ErrHasherBusy via errors.Is.
+func (e *HasherBusyError) Is(target error) bool {
+	return target == ErrHasherBusy
+}
+
+// Argon2Limits controla quantos hashes são calculados ao mesmo tempo. Cada cálculo aloca Argon2Params.Memory,
+// então MaxConcurrent limita a memória usada. Pedidos além do limite esperam em uma fila de até MaxQueue
+// posições por no máximo QueueTimeout; MaxConcurrent zero desativa o limite.
+type Argon2Limits struct {
+	MaxConcurrent int
+	MaxQueue      int
+	QueueTimeout  time.Duration
+}
+
+// Argon2Metrics é um retrato do uso do Argon2Manager.
+type Argon2Metrics struct {
+	InFlight         int64   `json:"InFlight"`
+	Queued           int64   `json:"Queued"`
+	MaxConcurrent    int     `json:"MaxConcurrent"`
+	MaxQueue         int     `json:"MaxQueue"`
+	Completed        uint64  `json:"Completed"`
+	Rejected         uint64  `json:"Rejected"`
+	AverageLatencyMs float64 `json:"AverageLatencyMs"`
+	MaxLatencyMs     float64 `json:"MaxLatencyMs"`
+	AverageWaitMs    float64 `json:"AverageWaitMs"`
+}
+
+// hashLimiter é um semáforo com fila limitada e registro de métricas.
+type hashLimiter struct {
+	limits Argon2Limits
+	slots  chan struct{}
+
+	inFlight     int64
+	queued       int64
+	completed    uint64
+	rejected     uint64
+	latencyNanos uint64
+	maxLatency   uint64
+	waitNanos    uint64
+}
+
+func newHashLimiter(limits Argon2Limits) *hashLimiter {
+	limiter := &hashLimiter{limits: limits}
+	if limits.MaxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return limiter
+}
+
+// acquire reserva uma vaga para o cálculo, esperando na fila quando necessário.
+func (l *hashLimiter) acquire() error {
+	if l.slots == nil {
+		atomic.AddInt64(&l.inFlight, 1)
+		return nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		atomic.AddInt64(&l.inFlight, 1)
+		return nil
+	default:
+	}
+
+	if atomic.AddInt64(&l.queued, 1) > int64(l.limits.MaxQueue) {
+		atomic.AddInt64(&l.queued, -1)
+		return l.reject()
+	}
+	defer atomic.AddInt64(&l.queued, -1)
+
+	start := time.Now()
+	timer := time.NewTimer(l.limits.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		atomic.AddUint64(&l.waitNanos, uint64(time.Since(start)))
+		atomic.AddInt64(&l.inFlight, 1)
+		return nil
+	case <-timer.C:
+		return l.reject()
+	}
+}
+
+// release libera a vaga e contabiliza a duração do cálculo.
+func (l *hashLimiter) release(latency time.Duration) {
+	atomic.AddInt64(&l.inFlight, -1)
+	if l.slots != nil {
+		<-l.slots
+	}
+
+	atomic.AddUint64(&l.completed, 1)
+	atomic.AddUint64(&l.latencyNanos, uint64(latency))
+	for {
+		current := atomic.LoadUint64(&l.maxLatency)
+		if uint64(latency) <= current || atomic.CompareAndSwapUint64(&l.maxLatency, current, uint64(latency)) {
+			break
+		}
+	}
+}
+
+func (l *hashLimiter) reject() error {
+	atomic.AddUint64(&l.rejected, 1)
+	retryAfter := l.limits.QueueTimeout
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return &HasherBusyError{RetryAfter: retryAfter}
+}
+
+func (l *hashLimiter) metrics() Argon2Metrics {
+	metrics := Argon2Metrics{
+		InFlight:      atomic.LoadInt64(&l.inFlight),
+		Queued:        atomic.LoadInt64(&l.queued),
+		MaxConcurrent: l.limits.MaxConcurrent,
+		MaxQueue:      l.limits.MaxQueue,
+		Completed:     atomic.LoadUint64(&l.completed),
+		Rejected:      atomic.LoadUint64(&l.rejected),
+		MaxLatencyMs:  nanosToMillis(atomic.LoadUint64(&l.maxLatency)),
+	}
+	if metrics.Completed > 0 {
+		metrics.AverageLatencyMs = nanosToMillis(atomic.LoadUint64(&l.latencyNanos)) / float64(metrics.Completed)
+		metrics.AverageWaitMs = nanosToMillis(atomic.LoadUint64(&l.waitNanos)) / float64(metrics.Completed)
+	}
+	return metrics
+}
+
+func nanosToMillis(nanos uint64) float64 {
+	return float64(nanos) / float64(time.Millisecond)
+}
@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHashLimiter_RejectsWhenSaturated(t *testing.T) {
+	limiter := newHashLimiter(Argon2Limits{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 50 * time.Millisecond})
+
+	if err := limiter.acquire(); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+
+	// A segunda chamada ocupa a fila até o timeout
+	queued := make(chan error)
+	go func() { queued <- limiter.acquire() }()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.metrics().Queued == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Com a fila cheia, a terceira chamada é recusada imediatamente
+	err := limiter.acquire()
+	var busy *HasherBusyError
+	if !errors.As(err, &busy) || !errors.Is(err, ErrHasherBusy) {
+		t.Fatalf("Expected HasherBusyError with a full queue, got %v", err)
+	}
+	if busy.RetryAfter < time.Second {
+		t.Errorf("Expected Retry-After of at least one second, got %s", busy.RetryAfter)
+	}
+
+	if err := <-queued; !errors.Is(err, ErrHasherBusy) {
+		t.Fatalf("Expected queued call to time out, got %v", err)
+	}
+
+	limiter.release(10 * time.Millisecond)
+
+	if err := limiter.acquire(); err != nil {
+		t.Fatalf("Expected acquire to succeed after release: %v", err)
+	}
+	limiter.release(30 * time.Millisecond)
+
+	metrics := limiter.metrics()
+	if metrics.Rejected != 2 || metrics.Completed != 2 || metrics.InFlight != 0 || metrics.Queued != 0 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+	if metrics.AverageLatencyMs != 20 || metrics.MaxLatencyMs != 30 {
+		t.Errorf("Unexpected latency metrics: %+v", metrics)
+	}
+}
+
+func TestArgon2Manager_Metrics(t *testing.T) {
+	manager := NewArgon2ManagerWithLimits(
+		Argon2Params{Memory: 8 * 1024, Threads: 1},
+		Argon2Limits{MaxConcurrent: 2, MaxQueue: 4, QueueTimeout: time.Second},
+	)
+
+	hash, err := manager.HashPassword("mypassword")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if matched, err := manager.VerifyPassword("mypassword", hash); err != nil || !matched {
+		t.Fatalf("Expected password to match: %v", err)
+	}
+
+	metrics := manager.Metrics()
+	if metrics.Completed != 2 || metrics.MaxConcurrent != 2 || metrics.AverageLatencyMs <= 0 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+}
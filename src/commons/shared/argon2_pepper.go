@@ -0,0 +1,50 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+)
+
+// pepperIDPattern restringe as versões do pepper a caracteres que não conflitam com o formato PHC.
+var pepperIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SetPeppers configura os segredos de servidor (peppers) misturados às senhas via HMAC-SHA256 antes do Argon2.
+// Os novos hashes usam a versão currentID; as demais versões continuam aceitas na verificação para permitir
+// a rotação, e os hashes com outra versão são refeitos no próximo login. currentID vazio desativa o pepper
+// nos novos hashes.
+func (a *Argon2Manager) SetPeppers(currentID string, peppers map[string][]byte) error {
+	for id, secret := range peppers {
+		if !pepperIDPattern.MatchString(id) {
+			return fmt.Errorf("versão de pepper inválida: %q", id)
+		}
+		if len(secret) == 0 {
+			return fmt.Errorf("pepper %s vazio", id)
+		}
+	}
+
+	if _, exists := peppers[currentID]; currentID != "" && !exists {
+		return fmt.Errorf("pepper atual %s não configurado", currentID)
+	}
+
+	a.pepperID = currentID
+	a.peppers = peppers
+	return nil
+}
+
+// applyPepper retorna o HMAC-SHA256 da senha com o pepper da versão informada, ou a própria senha sem versão.
+func (a *Argon2Manager) applyPepper(password string, pepperID string) ([]byte, error) {
+	if pepperID == "" {
+		return []byte(password), nil
+	}
+
+	secret, exists := a.peppers[pepperID]
+	if !exists {
+		return nil, fmt.Errorf("unknown pepper version: %s", pepperID)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password))
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+)
+
+func newPepperedManager(t *testing.T, currentID string, peppers map[string][]byte) *Argon2Manager {
+	manager := NewArgon2ManagerWithParams(Argon2Params{Memory: 8 * 1024, Threads: 1})
+	if err := manager.SetPeppers(currentID, peppers); err != nil {
+		t.Fatalf("Failed to set peppers: %v", err)
+	}
+	return manager
+}
+
+func TestArgon2Manager_Pepper(t *testing.T) {
+	manager := newPepperedManager(t, "v1", map[string][]byte{"v1": []byte("pepper-one")})
+
+	hash, err := manager.HashPassword("mypassword")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if !strings.Contains(hash, ",keyid=v1$") {
+		t.Errorf("Expected pepper version in hash, got %s", hash)
+	}
+
+	if matched, err := manager.VerifyPassword("mypassword", hash); err != nil || !matched {
+		t.Fatalf("Expected peppered hash to verify: %v", err)
+	}
+
+	// Sem o pepper o hash não pode ser verificado, mesmo com a senha correta
+	if matched, err := NewArgon2Manager().VerifyPassword("mypassword", hash); err == nil || matched {
+		t.Error("Expected verification without the pepper to fail")
+	}
+
+	wrongPepper := newPepperedManager(t, "v1", map[string][]byte{"v1": []byte("another")})
+	if matched, _ := wrongPepper.VerifyPassword("mypassword", hash); matched {
+		t.Error("Expected verification with a different pepper to fail")
+	}
+}
+
+func TestArgon2Manager_PepperRotation(t *testing.T) {
+	old := newPepperedManager(t, "v1", map[string][]byte{"v1": []byte("pepper-one")})
+	unpeppered := NewArgon2ManagerWithParams(Argon2Params{Memory: 8 * 1024, Threads: 1})
+
+	oldHash, _ := old.HashPassword("mypassword")
+	plainHash, _ := unpeppered.HashPassword("mypassword")
+
+	rotated := newPepperedManager(t, "v2", map[string][]byte{
+		"v1": []byte("pepper-one"),
+		"v2": []byte("pepper-two"),
+	})
+
+	for name, hash := range map[string]string{"v1": oldHash, "sem pepper": plainHash} {
+		if matched, err := rotated.VerifyPassword("mypassword", hash); err != nil || !matched {
+			t.Errorf("%s: expected hash to verify after rotation: %v", name, err)
+		}
+		if !rotated.NeedsRehash(hash) {
+			t.Errorf("%s: expected hash to need re-peppering", name)
+		}
+	}
+
+	newHash, _ := rotated.HashPassword("mypassword")
+	if rotated.NeedsRehash(newHash) || !strings.Contains(newHash, "keyid=v2") {
+		t.Errorf("Expected new hash to use the current pepper, got %s", newHash)
+	}
+}
+
+func TestArgon2Manager_SetPeppersValidation(t *testing.T) {
+	manager := NewArgon2Manager()
+
+	if err := manager.SetPeppers("v2", map[string][]byte{"v1": []byte("secret")}); err == nil {
+		t.Error("Expected error when the current pepper is not configured")
+	}
+
+	if err := manager.SetPeppers("v$1", map[string][]byte{"v$1": []byte("secret")}); err == nil {
+		t.Error("Expected error for a pepper version with invalid characters")
+	}
+}
@@ -1,7 +1,11 @@
 package shared
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/argon2"
 )
 
 func TestArgon2Manager_HashPassword(t *testing.T) {
@@ -72,3 +76,49 @@ func TestArgon2Manager_VerifyPasswordWithIncorrectPassword(t *testing.T) {
 		t.Error("Expected password not to match hash, but they matched")
 	}
 }
+
+func TestArgon2Manager_PHCFormat(t *testing.T) {
+	manager := NewArgon2ManagerWithParams(Argon2Params{Time: 2, Memory: 8 * 1024, Threads: 1})
+
+	hash, err := manager.HashPassword("mypassword")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=2,p=1$") {
+		t.Errorf("Expected PHC formatted hash, got %s", hash)
+	}
+
+	// Um manager com outros parâmetros ainda verifica o hash, pois os parâmetros estão no próprio hash
+	if matched, err := NewArgon2Manager().VerifyPassword("mypassword", hash); err != nil || !matched {
+		t.Errorf("Expected hash to verify with different current parameters: %v", err)
+	}
+
+	if manager.NeedsRehash(hash) {
+		t.Error("Expected hash with current parameters not to need rehash")
+	}
+
+	if !NewArgon2Manager().NeedsRehash(hash) {
+		t.Error("Expected hash with outdated parameters to need rehash")
+	}
+}
+
+func TestArgon2Manager_LegacyFormat(t *testing.T) {
+	manager := NewArgon2Manager()
+
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte("mypassword"), salt, Time, Memory, Threads, KeySize)
+	legacy := base64.RawStdEncoding.EncodeToString(salt) + "$" + base64.RawStdEncoding.EncodeToString(key)
+
+	if matched, err := manager.VerifyPassword("mypassword", legacy); err != nil || !matched {
+		t.Fatalf("Expected legacy hash to verify: %v", err)
+	}
+
+	if matched, _ := manager.VerifyPassword("wrongpassword", legacy); matched {
+		t.Error("Expected wrong password not to match legacy hash")
+	}
+
+	if !manager.NeedsRehash(legacy) {
+		t.Error("Expected legacy hash to need rehash")
+	}
+}
@@ -3,6 +3,7 @@ package shared
 import (
 	"errors"
 	"github.com/google/uuid"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -12,59 +13,409 @@ const (
 	// Constants for error messages
 	errUnexpectedSigningMethod = "método inesperado de assinatura de token"
 	errUnexpectedTokenClaims   = "reivindicações de token inesperadas"
+	errUnexpectedTokenType     = "tipo de token inesperado"
+	errUnexpectedIssuer        = "emissor do token inesperado"
+	errUnexpectedAudience      = "audiência do token inesperada"
+	errMissingTokenID          = "identificador do token ausente"
+	errUnknownKeyID            = "chave de assinatura desconhecida"
+
+	// Tipos de token carregados na claim "typ"
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeChallenge = "2fa"
+	TokenTypeID        = "id"
+	TokenTypeEmail     = "email"
+
+	// ScopePasswordChange restringe o token de acesso à troca da senha expirada
+	ScopePasswordChange = "password:change"
+
+	defaultIssuer = "server"
+	defaultKeyID  = "default"
 )
 
+// ErrOpenIDDisabled indica que a chave de assinatura é um segredo compartilhado: um ID token assinado com ele
+// não pode ser validado pelos clientes sem o segredo, e por isso o OpenID Connect fica desativado.
+var ErrOpenIDDisabled = errors.New("OpenID Connect desativado: configure uma chave assimétrica em JWT_PRIVATE_KEY_FILE")
+
+// JWTConfig reúne os parâmetros de emissão e validação de tokens.
+// Sem SigningKey, os tokens são assinados com HS256 usando SecretKey.
+// VerificationKeys permite aceitar tokens assinados por chaves anteriores durante a rotação.
+type JWTConfig struct {
+	SecretKey        string
+	SigningKey       *SigningKey
+	VerificationKeys []*SigningKey
+	TokenDuration    time.Duration
+	RefreshDuration  time.Duration
+	Issuer           string
+	Audience         string
+}
+
 // JWTManager é responsável por gerar e validar tokens JWT.
 type JWTManager struct {
-	secretKey       string
+	signingKey      *SigningKey
+	keys            map[string]*SigningKey
 	tokenDuration   time.Duration
 	refreshDuration time.Duration
+	issuer          string
+	audience        string
 }
 
 // UserClaims representa as informações personalizadas contidas no token JWT.
 type UserClaims struct {
 	jwt.StandardClaims
-	UserID uuid.UUID `json:"ID"`
+	UserID      uuid.UUID `json:"ID"`
+	FamilyID    uuid.UUID `json:"fid"`
+	TokenType   string    `json:"typ"`
+	TenantID    string    `json:"tid,omitempty"`
+	Roles       []string  `json:"roles,omitempty"`
+	Permissions []string  `json:"perms,omitempty"`
+	Scope       string    `json:"scope,omitempty"`     // vazio concede o acesso completo do usuário
+	ClientID    string    `json:"client_id,omitempty"` // cliente OAuth para o qual o token foi emitido
+	AuthTime    int64     `json:"auth_time,omitempty"` // momento em que o usuário se autenticou, preservado nas renovações
+	// IssuedAtMilli complementa o "iat", que tem precisão de segundos, para que uma revogação geral
+	// não alcance os tokens emitidos logo depois dela, no mesmo segundo
+	IssuedAtMilli int64 `json:"iat_ms,omitempty"`
+}
+
+// IssuedTime retorna o momento da emissão do token, em milissegundos quando disponível.
+// Tokens emitidos sem "iat_ms" usam o início do segundo do "iat".
+func (c *UserClaims) IssuedTime() time.Time {
+	if c.IssuedAtMilli > 0 {
+		return time.UnixMilli(c.IssuedAtMilli)
+	}
+	return time.Unix(c.IssuedAt, 0)
 }
 
 // RefreshTokenClaims representa as informações personalizadas contidas no refresh token JWT.
 type RefreshTokenClaims struct {
 	jwt.StandardClaims
-	UserID uuid.UUID `json:"ID"`
+	UserID    uuid.UUID `json:"ID"`
+	FamilyID  uuid.UUID `json:"fid"`
+	TokenType string    `json:"typ"`
+}
+
+// ChallengeClaims representa o desafio emitido entre a senha e o segundo fator de autenticação.
+type ChallengeClaims struct {
+	jwt.StandardClaims
+	UserID    uuid.UUID `json:"ID"`
+	TokenType string    `json:"typ"`
+}
+
+// IDTokenClaims representa o ID token do OpenID Connect. A audiência é o client_id do cliente OAuth
+// e o "sub" é o ID do usuário; as claims de perfil só são preenchidas quando os escopos correspondentes foram concedidos.
+type IDTokenClaims struct {
+	jwt.StandardClaims
+	TokenType  string `json:"typ"`
+	Nonce      string `json:"nonce,omitempty"`
+	AuthTime   int64  `json:"auth_time"`
+	Name       string `json:"name,omitempty"`
+	GivenName  string `json:"given_name,omitempty"`
+	FamilyName string `json:"family_name,omitempty"`
+	CPF        string `json:"cpf,omitempty"`
+}
+
+// EmailVerificationClaims representa o link de confirmação de e-mail, vinculado ao endereço que estava
+// cadastrado quando o link foi emitido para que a troca do e-mail invalide links anteriores.
+type EmailVerificationClaims struct {
+	jwt.StandardClaims
+	UserID    uuid.UUID `json:"ID"`
+	Email     string    `json:"email"`
+	TokenType string    `json:"typ"`
+}
+
+// IDTokenSubject descreve o usuário e a autenticação para os quais o ID token é emitido.
+type IDTokenSubject struct {
+	UserID     uuid.UUID
+	ClientID   string
+	Nonce      string
+	AuthTime   time.Time
+	Name       string
+	GivenName  string
+	FamilyName string
+	CPF        string
+}
+
+// TokenSubject descreve o usuário para quem os tokens são emitidos e o que deve constar nas claims de acesso.
+type TokenSubject struct {
+	UserID      uuid.UUID
+	TenantID    string
+	Roles       []string
+	Permissions []string
+	Scope       string
+	ClientID    string
+	AuthTime    time.Time // zero omite a claim auth_time
+}
+
+// TokenPair agrupa o token de acesso e o refresh token emitidos juntos para uma família de tokens.
+type TokenPair struct {
+	Token            string
+	RefreshToken     string
+	RefreshTokenID   string
+	RefreshExpiresAt time.Time
+	FamilyID         uuid.UUID
 }
 
 // NewJWTManager cria e retorna um novo JWTManager.
 func NewJWTManager(secretKey string, tokenDuration time.Duration, refreshDuration time.Duration) *JWTManager {
-	return &JWTManager{secretKey: secretKey, tokenDuration: tokenDuration, refreshDuration: refreshDuration}
+	return NewJWTManagerWithConfig(JWTConfig{
+		SecretKey:       secretKey,
+		TokenDuration:   tokenDuration,
+		RefreshDuration: refreshDuration,
+	})
+}
+
+// NewJWTManagerWithConfig cria um JWTManager a partir de um JWTConfig.
+// Emissor e audiência vazios assumem o valor padrão "server".
+func NewJWTManagerWithConfig(cfg JWTConfig) *JWTManager {
+	if cfg.Issuer == "" {
+		cfg.Issuer = defaultIssuer
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = cfg.Issuer
+	}
+	if cfg.SigningKey == nil {
+		cfg.SigningKey = NewHMACSigningKey(defaultKeyID, cfg.SecretKey)
+	}
+
+	keys := map[string]*SigningKey{cfg.SigningKey.ID: cfg.SigningKey}
+	for _, key := range cfg.VerificationKeys {
+		if _, exists := keys[key.ID]; !exists {
+			keys[key.ID] = key
+		}
+	}
+
+	return &JWTManager{
+		signingKey:      cfg.SigningKey,
+		keys:            keys,
+		tokenDuration:   cfg.TokenDuration,
+		refreshDuration: cfg.RefreshDuration,
+		issuer:          cfg.Issuer,
+		audience:        cfg.Audience,
+	}
+}
+
+// Issuer retorna o emissor gravado nos tokens, que o OpenID Connect usa como identificador do provedor.
+func (manager *JWTManager) Issuer() string {
+	return manager.issuer
+}
+
+// SupportsOpenID informa se a chave de assinatura é assimétrica e publicada no JWKS, condição para emitir ID tokens.
+func (manager *JWTManager) SupportsOpenID() bool {
+	return !manager.signingKey.IsSymmetric()
+}
+
+// SigningAlgorithm retorna o algoritmo da chave de assinatura atual.
+func (manager *JWTManager) SigningAlgorithm() string {
+	return manager.signingKey.Method.Alg()
+}
+
+// TokenDuration retorna o tempo de vida dos tokens de acesso.
+func (manager *JWTManager) TokenDuration() time.Duration {
+	return manager.tokenDuration
+}
+
+// RefreshDuration retorna o tempo de vida dos refresh tokens.
+func (manager *JWTManager) RefreshDuration() time.Duration {
+	return manager.refreshDuration
 }
 
 // Generate cria e retorna um novo token JWT.
 func (manager *JWTManager) Generate(UserID uuid.UUID) (string, string, error) {
-	token, err := manager.generateToken(UserID, manager.tokenDuration)
+	pair, err := manager.GeneratePair(TokenSubject{UserID: UserID}, uuid.New())
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := manager.generateToken(UserID, manager.refreshDuration)
+	return pair.Token, pair.RefreshToken, nil
+}
+
+// GeneratePair cria um novo par de tokens pertencente à família informada.
+func (manager *JWTManager) GeneratePair(subject TokenSubject, familyID uuid.UUID) (*TokenPair, error) {
+	now := time.Now()
+
+	token, err := manager.generateAccessToken(subject, familyID, now)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+
+	refreshTokenID := uuid.NewString()
+	refreshExpiresAt := now.Add(manager.refreshDuration)
+
+	refreshToken, err := manager.generateRefreshToken(subject.UserID, familyID, refreshTokenID, now, refreshExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		Token:            token,
+		RefreshToken:     refreshToken,
+		RefreshTokenID:   refreshTokenID,
+		RefreshExpiresAt: refreshExpiresAt,
+		FamilyID:         familyID,
+	}, nil
+}
+
+// GenerateAccessToken cria apenas um token de acesso, sem família nem refresh token,
+// como os emitidos para clientes OAuth na concessão client_credentials.
+func (manager *JWTManager) GenerateAccessToken(subject TokenSubject) (string, error) {
+	return manager.generateAccessToken(subject, uuid.Nil, time.Now())
+}
+
+// GenerateIDToken cria o ID token do OpenID Connect, com a mesma duração dos tokens de acesso.
+// Com uma chave simétrica, retorna ErrOpenIDDisabled.
+func (manager *JWTManager) GenerateIDToken(subject IDTokenSubject) (string, error) {
+	if !manager.SupportsOpenID() {
+		return "", ErrOpenIDDisabled
+	}
+
+	now := time.Now()
+
+	standardClaims := manager.standardClaims(uuid.NewString(), now, now.Add(manager.tokenDuration))
+	standardClaims.Audience = subject.ClientID
+	standardClaims.Subject = subject.UserID.String()
+
+	claims := IDTokenClaims{
+		StandardClaims: standardClaims,
+		TokenType:      TokenTypeID,
+		Nonce:          subject.Nonce,
+		AuthTime:       subject.AuthTime.Unix(),
+		Name:           subject.Name,
+		GivenName:      subject.GivenName,
+		FamilyName:     subject.FamilyName,
+		CPF:            subject.CPF,
+	}
+
+	return manager.sign(claims)
+}
+
+// GenerateChallenge cria um token de desafio de curta duração que só é aceito para concluir o segundo fator.
+func (manager *JWTManager) GenerateChallenge(userID uuid.UUID, duration time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(duration)
+
+	claims := ChallengeClaims{
+		StandardClaims: manager.standardClaims(uuid.NewString(), now, expiresAt),
+		UserID:         userID,
+		TokenType:      TokenTypeChallenge,
+	}
+
+	token, err := manager.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// GenerateEmailVerification cria o token assinado do link de confirmação do e-mail do usuário.
+func (manager *JWTManager) GenerateEmailVerification(userID uuid.UUID, email string, duration time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(duration)
+
+	claims := EmailVerificationClaims{
+		StandardClaims: manager.standardClaims(uuid.NewString(), now, expiresAt),
+		UserID:         userID,
+		Email:          email,
+		TokenType:      TokenTypeEmail,
 	}
 
-	return token, refreshToken, nil
+	token, err := manager.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
 }
 
-func (manager *JWTManager) generateToken(UserID uuid.UUID, duration time.Duration) (string, error) {
+func (manager *JWTManager) generateAccessToken(subject TokenSubject, familyID uuid.UUID, now time.Time) (string, error) {
 	claims := UserClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(duration).Unix(),
-			IssuedAt:  time.Now().Unix(),
-			Issuer:    "server",
-		},
-		UserID: UserID,
+		StandardClaims: manager.standardClaims(uuid.NewString(), now, now.Add(manager.tokenDuration)),
+		UserID:         subject.UserID,
+		FamilyID:       familyID,
+		TokenType:      TokenTypeAccess,
+		TenantID:       subject.TenantID,
+		Roles:          subject.Roles,
+		Permissions:    subject.Permissions,
+		Scope:          subject.Scope,
+		ClientID:       subject.ClientID,
+		IssuedAtMilli:  now.UnixMilli(),
+	}
+	if !subject.AuthTime.IsZero() {
+		claims.AuthTime = subject.AuthTime.Unix()
+	}
+
+	return manager.sign(claims)
+}
+
+func (manager *JWTManager) generateRefreshToken(UserID uuid.UUID, familyID uuid.UUID, tokenID string, now, expiresAt time.Time) (string, error) {
+	claims := RefreshTokenClaims{
+		StandardClaims: manager.standardClaims(tokenID, now, expiresAt),
+		UserID:         UserID,
+		FamilyID:       familyID,
+		TokenType:      TokenTypeRefresh,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(manager.secretKey))
+	return manager.sign(claims)
+}
+
+func (manager *JWTManager) standardClaims(tokenID string, now, expiresAt time.Time) jwt.StandardClaims {
+	return jwt.StandardClaims{
+		Id:        tokenID,
+		Audience:  manager.audience,
+		ExpiresAt: expiresAt.Unix(),
+		IssuedAt:  now.Unix(),
+		Issuer:    manager.issuer,
+	}
+}
+
+// validateStandardClaims garante que o token foi emitido por este servidor, para esta audiência e com o tipo esperado.
+func (manager *JWTManager) validateStandardClaims(claims jwt.StandardClaims, tokenType, expectedType string) error {
+	if tokenType != expectedType {
+		return errors.New(errUnexpectedTokenType)
+	}
+	if !claims.VerifyIssuer(manager.issuer, true) {
+		return errors.New(errUnexpectedIssuer)
+	}
+	if !claims.VerifyAudience(manager.audience, true) {
+		return errors.New(errUnexpectedAudience)
+	}
+	if claims.Id == "" {
+		return errors.New(errMissingTokenID)
+	}
+	return nil
+}
+
+func (manager *JWTManager) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(manager.signingKey.Method, claims)
+	token.Header["kid"] = manager.signingKey.ID
+	return token.SignedString(manager.signingKey.private)
+}
+
+// JWKS retorna as chaves públicas aceitas na verificação, para que outros serviços validem tokens sem o segredo.
+func (manager *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range manager.keys {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// verificationKey escolhe a chave pelo kid do cabeçalho; tokens sem kid usam a chave de assinatura atual.
+func (manager *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	key := manager.signingKey
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = manager.keys[kid]
+		if !ok {
+			return nil, errors.New(errUnknownKeyID)
+		}
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New(errUnexpectedSigningMethod)
+	}
+	return key.public, nil
 }
 
 // Verify analisa o token JWT, valida-o e retorna as informações do usuário contidas no token.
@@ -77,18 +428,71 @@ func (manager *JWTManager) VerifyRefreshToken(tokenStr string) (*RefreshTokenCla
 	return manager.verifyRefreshToken(tokenStr)
 }
 
+// VerifyChallenge analisa o token de desafio do segundo fator e o valida.
+func (manager *JWTManager) VerifyChallenge(tokenStr string) (*ChallengeClaims, error) {
+	claims, err := manager.verifyToken(tokenStr, &ChallengeClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	challengeClaims, ok := claims.(*ChallengeClaims)
+	if !ok {
+		return nil, errors.New(errUnexpectedTokenClaims)
+	}
+
+	if err := manager.validateStandardClaims(challengeClaims.StandardClaims, challengeClaims.TokenType, TokenTypeChallenge); err != nil {
+		return nil, err
+	}
+
+	return challengeClaims, nil
+}
+
+// VerifyEmailVerification analisa o token do link de confirmação de e-mail e o valida.
+func (manager *JWTManager) VerifyEmailVerification(tokenStr string) (*EmailVerificationClaims, error) {
+	claims, err := manager.verifyToken(tokenStr, &EmailVerificationClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	emailClaims, ok := claims.(*EmailVerificationClaims)
+	if !ok {
+		return nil, errors.New(errUnexpectedTokenClaims)
+	}
+
+	if err := manager.validateStandardClaims(emailClaims.StandardClaims, emailClaims.TokenType, TokenTypeEmail); err != nil {
+		return nil, err
+	}
+
+	return emailClaims, nil
+}
+
+// VerifyIDToken analisa um ID token emitido por este servidor para o cliente informado e o valida.
+func (manager *JWTManager) VerifyIDToken(tokenStr string, clientID string) (*IDTokenClaims, error) {
+	claims, err := manager.verifyToken(tokenStr, &IDTokenClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	idClaims, ok := claims.(*IDTokenClaims)
+	if !ok {
+		return nil, errors.New(errUnexpectedTokenClaims)
+	}
+
+	if idClaims.TokenType != TokenTypeID {
+		return nil, errors.New(errUnexpectedTokenType)
+	}
+	if !idClaims.VerifyIssuer(manager.issuer, true) {
+		return nil, errors.New(errUnexpectedIssuer)
+	}
+	if !idClaims.VerifyAudience(clientID, true) {
+		return nil, errors.New(errUnexpectedAudience)
+	}
+
+	return idClaims, nil
+}
+
 func (manager *JWTManager) verifyToken(tokenStr string, claims jwt.Claims) (jwt.Claims, error) {
-	token, err := jwt.ParseWithClaims(
-		tokenStr,
-		claims,
-		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				return nil, errors.New(errUnexpectedSigningMethod)
-			}
-			return []byte(manager.secretKey), nil
-		},
-	)
+	token, err := jwt.ParseWithClaims(tokenStr, claims, manager.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -108,6 +512,10 @@ func (manager *JWTManager) verifyRefreshToken(tokenStr string) (*RefreshTokenCla
 		return nil, errors.New(errUnexpectedTokenClaims)
 	}
 
+	if err := manager.validateStandardClaims(userClaims.StandardClaims, userClaims.TokenType, TokenTypeRefresh); err != nil {
+		return nil, err
+	}
+
 	return userClaims, nil
 }
 
@@ -122,5 +530,9 @@ func (manager *JWTManager) verify(tokenStr string) (*UserClaims, error) {
 		return nil, errors.New(errUnexpectedTokenClaims)
 	}
 
+	if err := manager.validateStandardClaims(userClaims.StandardClaims, userClaims.TokenType, TokenTypeAccess); err != nil {
+		return nil, err
+	}
+
 	return userClaims, nil
 }
@@ -0,0 +1,227 @@
+package shared
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	errInvalidPEM           = "arquivo PEM inválido"
+	errUnsupportedKeyType   = "tipo de chave não suportado"
+	errKeyAlgorithmMismatch = "algoritmo incompatível com a chave"
+)
+
+// SigningKey representa uma chave de assinatura ou de verificação de tokens identificada por um kid.
+// Chaves de verificação não possuem a parte privada.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// JWK representa uma chave pública no formato JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet representa o documento publicado em /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACSigningKey cria uma chave simétrica HS256 a partir de um segredo compartilhado.
+func NewHMACSigningKey(id, secret string) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}
+}
+
+// NewSigningKey cria uma chave de assinatura a partir de uma chave privada RSA, ECDSA ou Ed25519.
+// O algoritmo é deduzido do tipo da chave quando alg está vazio.
+func NewSigningKey(id, alg string, privateKey crypto.Signer) (*SigningKey, error) {
+	method, err := signingMethodFor(alg, privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{ID: id, Method: method, private: privateKey, public: privateKey.Public()}, nil
+}
+
+// NewVerificationKey cria uma chave apenas de verificação a partir de uma chave pública.
+func NewVerificationKey(id, alg string, publicKey crypto.PublicKey) (*SigningKey, error) {
+	method, err := signingMethodFor(alg, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{ID: id, Method: method, public: publicKey}, nil
+}
+
+// LoadSigningKey lê uma chave privada em formato PEM (PKCS#8, PKCS#1 ou SEC 1).
+func LoadSigningKey(id, alg, path string) (*SigningKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler a chave privada %s: %w", path, err)
+	}
+
+	return NewSigningKey(id, alg, privateKey)
+}
+
+// LoadVerificationKey lê uma chave pública ou certificado em formato PEM.
+func LoadVerificationKey(id, alg, path string) (*SigningKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := parsePublicKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler a chave pública %s: %w", path, err)
+	}
+
+	return NewVerificationKey(id, alg, publicKey)
+}
+
+// CanSign informa se a chave possui a parte privada.
+func (k *SigningKey) CanSign() bool {
+	return k.private != nil
+}
+
+// IsSymmetric informa se a chave é um segredo compartilhado (HMAC).
+func (k *SigningKey) IsSymmetric() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// JWK converte a parte pública da chave para o formato JWK. Chaves simétricas nunca são publicadas.
+func (k *SigningKey) JWK() (JWK, bool) {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch key := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(key.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		jwk.X = encode(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encode(key)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// signingMethodFor valida o algoritmo informado contra o tipo da chave, ou deduz o algoritmo quando vazio.
+func signingMethodFor(alg string, publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	var expected jwt.SigningMethod
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		// Chaves RSA aceitam as variantes RS* e PS*; RS256 é o padrão
+		if alg == "" {
+			return jwt.SigningMethodRS256, nil
+		}
+		switch method := jwt.GetSigningMethod(alg).(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			return method, nil
+		}
+		return nil, fmt.Errorf("%s: %s", errKeyAlgorithmMismatch, alg)
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			expected = jwt.SigningMethodES256
+		case elliptic.P384():
+			expected = jwt.SigningMethodES384
+		case elliptic.P521():
+			expected = jwt.SigningMethodES512
+		default:
+			return nil, errors.New(errUnsupportedKeyType)
+		}
+	case ed25519.PublicKey:
+		expected = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New(errUnsupportedKeyType)
+	}
+
+	if alg != "" && alg != expected.Alg() {
+		return nil, fmt.Errorf("%s: %s", errKeyAlgorithmMismatch, alg)
+	}
+	return expected, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: %s", errInvalidPEM, path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New(errUnsupportedKeyType)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New(errUnsupportedKeyType)
+}
+
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New(errUnsupportedKeyType)
+}
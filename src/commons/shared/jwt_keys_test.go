@@ -0,0 +1,225 @@
+package shared
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write PEM file: %v", err)
+	}
+	return path
+}
+
+func writeKeyPair(t *testing.T, signer crypto.Signer) (string, string) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatalf("Failed to marshal private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	return writePEM(t, "private.pem", "PRIVATE KEY", privateDER), writePEM(t, "public.pem", "PUBLIC KEY", publicDER)
+}
+
+func TestJWTManager_AsymmetricAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		alg    string
+		kty    string
+		signer crypto.Signer
+	}{
+		{"RS256", "RSA", rsaKey},
+		{"ES256", "EC", ecKey},
+		{"EdDSA", "OKP", edKey},
+	}
+
+	for _, test := range tests {
+		t.Run(test.alg, func(t *testing.T) {
+			privatePath, _ := writeKeyPair(t, test.signer)
+
+			signingKey, err := LoadSigningKey("key-1", test.alg, privatePath)
+			if err != nil {
+				t.Fatalf("Failed to load signing key: %v", err)
+			}
+
+			manager := NewJWTManagerWithConfig(JWTConfig{SigningKey: signingKey, TokenDuration: time.Minute, RefreshDuration: time.Hour})
+
+			userID := uuid.New()
+			token, _, err := manager.Generate(userID)
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+
+			claims, err := manager.Verify(token)
+			if err != nil {
+				t.Fatalf("Failed to verify token: %v", err)
+			}
+			if claims.UserID != userID {
+				t.Errorf("Expected UserID to be %s but got %s", userID, claims.UserID)
+			}
+
+			jwks := manager.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "key-1" || jwks.Keys[0].Kty != test.kty || jwks.Keys[0].Alg != test.alg {
+				t.Errorf("Unexpected JWKS: %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestJWTManager_KeyRotation(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	oldPrivatePath, oldPublicPath := writeKeyPair(t, oldKey)
+	newPrivatePath, _ := writeKeyPair(t, newKey)
+
+	oldSigningKey, _ := LoadSigningKey("2024-01", "", oldPrivatePath)
+	oldManager := NewJWTManagerWithConfig(JWTConfig{SigningKey: oldSigningKey, TokenDuration: time.Minute, RefreshDuration: time.Hour})
+	oldToken, _, _ := oldManager.Generate(uuid.New())
+
+	newSigningKey, _ := LoadSigningKey("2024-02", "", newPrivatePath)
+	newManager := NewJWTManagerWithConfig(JWTConfig{SigningKey: newSigningKey, TokenDuration: time.Minute, RefreshDuration: time.Hour})
+
+	if _, err := newManager.Verify(oldToken); err == nil {
+		t.Fatal("Expected token signed by an unknown key to be rejected")
+	}
+
+	oldVerificationKey, err := LoadVerificationKey("2024-01", "", oldPublicPath)
+	if err != nil {
+		t.Fatalf("Failed to load verification key: %v", err)
+	}
+
+	rotatedManager := NewJWTManagerWithConfig(JWTConfig{
+		SigningKey:       newSigningKey,
+		VerificationKeys: []*SigningKey{oldVerificationKey},
+		TokenDuration:    time.Minute,
+		RefreshDuration:  time.Hour,
+	})
+
+	if _, err := rotatedManager.Verify(oldToken); err != nil {
+		t.Fatalf("Expected token signed by the previous key to be accepted: %v", err)
+	}
+
+	if len(rotatedManager.JWKS().Keys) != 2 {
+		t.Errorf("Expected both keys to be published, got %d", len(rotatedManager.JWKS().Keys))
+	}
+}
+
+func TestJWTManager_HMACKeyIsNotPublished(t *testing.T) {
+	manager := NewJWTManager("testSecret", 30*time.Minute, 24*time.Hour)
+
+	if len(manager.JWKS().Keys) != 0 {
+		t.Error("Expected HMAC secret not to be published in JWKS")
+	}
+}
+
+func TestJWTManager_IDTokenRequiresAsymmetricKey(t *testing.T) {
+	manager := NewJWTManager("testSecret", time.Hour, 24*time.Hour)
+
+	if manager.SupportsOpenID() {
+		t.Error("Expected OpenID Connect to be disabled with an HMAC secret")
+	}
+	if _, err := manager.GenerateIDToken(IDTokenSubject{UserID: uuid.New(), ClientID: "spa"}); !errors.Is(err, ErrOpenIDDisabled) {
+		t.Errorf("Expected ID token to be refused with an HMAC secret, got %v", err)
+	}
+}
+
+// publicKeyFromJWK reconstrói a chave pública apenas a partir do JWK publicado, como faria um cliente OpenID Connect
+func publicKeyFromJWK(t *testing.T, jwk JWK) interface{} {
+	decode := func(value string) []byte {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			t.Fatalf("Invalid base64url in JWK: %v", err)
+		}
+		return data
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(decode(jwk.N)), E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64())}
+	case "EC":
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(decode(jwk.X)), Y: new(big.Int).SetBytes(decode(jwk.Y))}
+	case "OKP":
+		return ed25519.PublicKey(decode(jwk.X))
+	}
+	t.Fatalf("Unexpected key type in JWKS: %s", jwk.Kty)
+	return nil
+}
+
+func TestJWTManager_IDTokenVerifiesAgainstJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, signer := range []crypto.Signer{rsaKey, ecKey, edKey} {
+		signingKey, err := NewSigningKey("key-1", "", signer)
+		if err != nil {
+			t.Fatalf("Failed to create signing key: %v", err)
+		}
+		manager := NewJWTManagerWithConfig(JWTConfig{SigningKey: signingKey, TokenDuration: time.Hour, RefreshDuration: 24 * time.Hour, Issuer: "https://auth.example.com"})
+
+		t.Run(manager.SigningAlgorithm(), func(t *testing.T) {
+			userID := uuid.New()
+			idToken, err := manager.GenerateIDToken(IDTokenSubject{UserID: userID, ClientID: "spa", Nonce: "abc", AuthTime: time.Now()})
+			if err != nil {
+				t.Fatalf("Failed to generate ID token: %v", err)
+			}
+
+			// O cliente conhece apenas o documento publicado em /.well-known/jwks.json
+			published, _ := json.Marshal(manager.JWKS())
+			var jwks JWKSet
+			if err := json.Unmarshal(published, &jwks); err != nil {
+				t.Fatalf("Failed to decode JWKS: %v", err)
+			}
+
+			claims := &IDTokenClaims{}
+			token, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+				for _, jwk := range jwks.Keys {
+					if jwk.Kid == token.Header["kid"] && jwk.Alg == token.Method.Alg() {
+						return publicKeyFromJWK(t, jwk), nil
+					}
+				}
+				return nil, errors.New("kid not published")
+			})
+			if err != nil || !token.Valid {
+				t.Fatalf("Expected ID token to verify against the published JWKS, got %v", err)
+			}
+			if claims.Subject != userID.String() || claims.Audience != "spa" || claims.Nonce != "abc" || claims.Issuer != "https://auth.example.com" {
+				t.Errorf("Unexpected ID token claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestLoadSigningKey_AlgorithmMismatch(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	privatePath, _ := writeKeyPair(t, ecKey)
+
+	if _, err := LoadSigningKey("key-1", "RS256", privatePath); err == nil {
+		t.Error("Expected error when algorithm does not match the key type")
+	}
+}
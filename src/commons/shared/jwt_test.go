@@ -1,6 +1,9 @@
 package shared
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"testing"
 	"time"
 
@@ -79,3 +82,195 @@ func TestJWTManager_InvalidRefreshToken(t *testing.T) {
 		t.Error("Expected error due to invalid refresh token but got nil")
 	}
 }
+
+func TestJWTManager_GeneratePair(t *testing.T) {
+	manager := NewJWTManager("testSecret", 30*time.Minute, 24*time.Hour)
+
+	userID := uuid.New()
+	familyID := uuid.New()
+
+	first, err := manager.GeneratePair(TokenSubject{UserID: userID}, familyID)
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+
+	second, err := manager.GeneratePair(TokenSubject{UserID: userID}, familyID)
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+
+	if first.RefreshToken == second.RefreshToken || first.RefreshTokenID == second.RefreshTokenID {
+		t.Error("Expected rotated refresh tokens to be unique")
+	}
+
+	claims, err := manager.VerifyRefreshToken(second.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to verify refresh token: %v", err)
+	}
+
+	if claims.FamilyID != familyID {
+		t.Errorf("Expected FamilyID to be %s but got %s", familyID, claims.FamilyID)
+	}
+
+	if claims.Id != second.RefreshTokenID {
+		t.Errorf("Expected token ID to be %s but got %s", second.RefreshTokenID, claims.Id)
+	}
+}
+
+func TestJWTManager_RejectsWrongTokenType(t *testing.T) {
+	manager := NewJWTManager("testSecret", 30*time.Minute, 24*time.Hour)
+
+	token, refreshToken, _ := manager.Generate(uuid.New())
+
+	if _, err := manager.Verify(refreshToken); err == nil {
+		t.Error("Expected refresh token to be rejected as an access token")
+	}
+
+	if _, err := manager.VerifyRefreshToken(token); err == nil {
+		t.Error("Expected access token to be rejected as a refresh token")
+	}
+}
+
+func TestJWTManager_IssuerAndAudience(t *testing.T) {
+	manager := NewJWTManagerWithConfig(JWTConfig{
+		SecretKey:       "testSecret",
+		TokenDuration:   30 * time.Minute,
+		RefreshDuration: 24 * time.Hour,
+		Issuer:          "auth-server",
+		Audience:        "api",
+	})
+
+	token, _, _ := manager.Generate(uuid.New())
+
+	claims, err := manager.Verify(token)
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+
+	if claims.Issuer != "auth-server" || claims.Audience != "api" {
+		t.Errorf("Expected issuer/audience auth-server/api but got %s/%s", claims.Issuer, claims.Audience)
+	}
+
+	if claims.Id == "" || claims.TokenType != TokenTypeAccess {
+		t.Errorf("Expected access token with jti, got type %q and jti %q", claims.TokenType, claims.Id)
+	}
+
+	otherAudience := NewJWTManagerWithConfig(JWTConfig{
+		SecretKey:     "testSecret",
+		TokenDuration: 30 * time.Minute,
+		Issuer:        "auth-server",
+		Audience:      "another-api",
+	})
+
+	if _, err := otherAudience.Verify(token); err == nil {
+		t.Error("Expected token for another audience to be rejected")
+	}
+}
+
+func TestJWTManager_Challenge(t *testing.T) {
+	manager := NewJWTManager("testSecret", 30*time.Minute, 24*time.Hour)
+	userID := uuid.New()
+
+	challenge, expiresAt, err := manager.GenerateChallenge(userID, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate challenge: %v", err)
+	}
+
+	if time.Until(expiresAt) > 5*time.Minute {
+		t.Errorf("Expected challenge to expire within 5 minutes, got %s", expiresAt)
+	}
+
+	claims, err := manager.VerifyChallenge(challenge)
+	if err != nil || claims.UserID != userID {
+		t.Fatalf("Expected challenge to verify for the user, got %v", err)
+	}
+
+	if _, err := manager.Verify(challenge); err == nil {
+		t.Error("Expected challenge token to be rejected as access token")
+	}
+
+	token, _, _ := manager.Generate(userID)
+	if _, err := manager.VerifyChallenge(token); err == nil {
+		t.Error("Expected access token to be rejected as challenge")
+	}
+}
+
+func TestJWTManager_EmailVerification(t *testing.T) {
+	manager := NewJWTManager("testSecret", 30*time.Minute, 24*time.Hour)
+	userID := uuid.New()
+
+	token, expiresAt, err := manager.GenerateEmailVerification(userID, "ana@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to generate email verification: %v", err)
+	}
+	if time.Until(expiresAt) > time.Hour {
+		t.Errorf("Expected email verification to expire within an hour, got %s", expiresAt)
+	}
+
+	claims, err := manager.VerifyEmailVerification(token)
+	if err != nil || claims.UserID != userID || claims.Email != "ana@example.com" {
+		t.Fatalf("Expected email verification to verify for the user and address, got %+v, %v", claims, err)
+	}
+
+	if _, err := manager.Verify(token); err == nil {
+		t.Error("Expected email verification to be rejected as access token")
+	}
+
+	challenge, _, _ := manager.GenerateChallenge(userID, time.Minute)
+	if _, err := manager.VerifyEmailVerification(challenge); err == nil {
+		t.Error("Expected challenge to be rejected as email verification")
+	}
+
+	expired, _, _ := manager.GenerateEmailVerification(userID, "ana@example.com", -time.Minute)
+	if _, err := manager.VerifyEmailVerification(expired); err == nil {
+		t.Error("Expected expired email verification to be rejected")
+	}
+}
+
+func TestJWTManager_GenerateAccessToken(t *testing.T) {
+	manager := NewJWTManager("testSecret", 30*time.Minute, 24*time.Hour)
+
+	token, err := manager.GenerateAccessToken(TokenSubject{ClientID: "partner", Permissions: []string{"users:read"}, Scope: "users:read"})
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+
+	claims, err := manager.Verify(token)
+	if err != nil {
+		t.Fatalf("Expected access token to verify, got %v", err)
+	}
+	if claims.ClientID != "partner" || claims.Scope != "users:read" || claims.UserID != uuid.Nil {
+		t.Errorf("Unexpected claims for client token: %+v", claims)
+	}
+}
+
+func TestJWTManager_IDToken(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signingKey, err := NewSigningKey("key-1", "", ecKey)
+	if err != nil {
+		t.Fatalf("Failed to create signing key: %v", err)
+	}
+	manager := NewJWTManagerWithConfig(JWTConfig{SigningKey: signingKey, TokenDuration: time.Hour, RefreshDuration: 24 * time.Hour, Issuer: "https://auth.example.com"})
+	userID := uuid.New()
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	token, err := manager.GenerateIDToken(IDTokenSubject{UserID: userID, ClientID: "spa", Nonce: "n-0S6_WzA2Mj", AuthTime: authTime, GivenName: "Ana"})
+	if err != nil {
+		t.Fatalf("Failed to generate ID token: %v", err)
+	}
+
+	claims, err := manager.VerifyIDToken(token, "spa")
+	if err != nil {
+		t.Fatalf("Expected ID token to verify, got %v", err)
+	}
+	if claims.Subject != userID.String() || claims.Nonce != "n-0S6_WzA2Mj" || claims.AuthTime != authTime.Unix() || claims.GivenName != "Ana" || claims.Issuer != "https://auth.example.com" {
+		t.Errorf("Unexpected ID token claims: %+v", claims)
+	}
+
+	if _, err := manager.VerifyIDToken(token, "other"); err == nil {
+		t.Error("Expected ID token for another client to be rejected")
+	}
+	if _, err := manager.Verify(token); err == nil {
+		t.Error("Expected ID token to be rejected as access token")
+	}
+}
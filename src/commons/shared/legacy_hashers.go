@@ -0,0 +1,118 @@
+package shared
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var errHashMismatch = errors.New("hash does not match")
+
+// BcryptVerifier verifica hashes bcrypt ($2a$, $2b$ e $2y$).
+type BcryptVerifier struct{}
+
+func (BcryptVerifier) VerifyPassword(password, encodedHash string) (bool, error) {
+	// A biblioteca só reconhece $2a$ e $2b$; $2y$ é equivalente
+	if strings.HasPrefix(encodedHash, "$2y$") {
+		encodedHash = "$2b$" + encodedHash[4:]
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, errHashMismatch
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ScryptVerifier verifica hashes no formato $scrypt$ln=16,r=8,p=1$salt$hash, com salt e hash em base64.
+type ScryptVerifier struct{}
+
+func (ScryptVerifier) VerifyPassword(password, encodedHash string) (bool, error) {
+	parts := strings.Split(encodedHash, separator)
+	if len(parts) != 5 {
+		return false, errors.New("invalid hash format")
+	}
+
+	var logN, r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &r, &p); err != nil {
+		return false, fmt.Errorf("failed to parse parameters: %w", err)
+	}
+	if logN <= 0 || logN > 30 {
+		return false, errors.New("invalid scrypt cost")
+	}
+
+	salt, err := decodeAdaptedBase64(parts[3])
+	if err != nil {
+		return false, fmt.Errorf("failed to decode salt: %w", err)
+	}
+
+	expectedHash, err := decodeAdaptedBase64(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("failed to decode expected hash: %w", err)
+	}
+
+	calculatedHash, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, len(expectedHash))
+	if err != nil {
+		return false, err
+	}
+
+	return compareHashes(calculatedHash, expectedHash)
+}
+
+// PBKDF2Verifier verifica hashes PBKDF2-SHA256 nos formatos $pbkdf2-sha256$iter$salt$hash (passlib,
+// salt e hash em base64) e pbkdf2_sha256$iter$salt$hash (Django, salt em texto e hash em base64).
+type PBKDF2Verifier struct{}
+
+func (PBKDF2Verifier) VerifyPassword(password, encodedHash string) (bool, error) {
+	parts := strings.Split(strings.TrimPrefix(encodedHash, separator), separator)
+	if len(parts) != 4 {
+		return false, errors.New("invalid hash format")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false, errors.New("invalid pbkdf2 iterations")
+	}
+
+	var salt, expectedHash []byte
+	if parts[0] == "pbkdf2_sha256" {
+		salt = []byte(parts[2])
+		expectedHash, err = base64.StdEncoding.DecodeString(parts[3])
+	} else {
+		salt, err = decodeAdaptedBase64(parts[2])
+		if err != nil {
+			return false, fmt.Errorf("failed to decode salt: %w", err)
+		}
+		expectedHash, err = decodeAdaptedBase64(parts[3])
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to decode expected hash: %w", err)
+	}
+
+	calculatedHash := pbkdf2.Key([]byte(password), salt, iterations, len(expectedHash), sha256.New)
+	return compareHashes(calculatedHash, expectedHash)
+}
+
+// decodeAdaptedBase64 aceita base64 padrão com ou sem padding e a variante do passlib, que usa "." no lugar de "+".
+func decodeAdaptedBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(strings.ReplaceAll(value, ".", "+"), "=")
+	return base64.RawStdEncoding.DecodeString(value)
+}
+
+func compareHashes(calculated, expected []byte) (bool, error) {
+	if subtle.ConstantTimeCompare(calculated, expected) != 1 {
+		return false, errHashMismatch
+	}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package shared
+
+import (
+	"strings"
+)
+
+// PasswordHasher gera e verifica hashes de senha. NeedsRehash informa se um hash válido deve ser
+// refeito no próximo login, por estar em um formato legado ou com parâmetros desatualizados.
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+	VerifyPassword(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// PasswordVerifier verifica senhas contra hashes de um formato que não é mais gerado.
+type PasswordVerifier interface {
+	VerifyPassword(password, encodedHash string) (bool, error)
+}
+
+type prefixedVerifier struct {
+	prefix   string
+	verifier PasswordVerifier
+}
+
+// PasswordHasherRegistry gera hashes com o hasher principal e escolhe o verificador pelo prefixo do hash.
+// Hashes sem prefixo registrado são verificados pelo hasher principal.
+type PasswordHasherRegistry struct {
+	primary   PasswordHasher
+	verifiers []prefixedVerifier
+}
+
+// NewPasswordHasherRegistry cria um registro que gera novos hashes com o hasher principal.
+func NewPasswordHasherRegistry(primary PasswordHasher) *PasswordHasherRegistry {
+	return &PasswordHasherRegistry{primary: primary}
+}
+
+// NewDefaultPasswordHasher cria um registro com o Argon2id como principal e os verificadores
+// de bcrypt, scrypt e PBKDF2-SHA256 usados por sistemas legados.
+func NewDefaultPasswordHasher(primary PasswordHasher) *PasswordHasherRegistry {
+	registry := NewPasswordHasherRegistry(primary)
+	registry.Register("$2a$", BcryptVerifier{})
+	registry.Register("$2b$", BcryptVerifier{})
+	registry.Register("$2y$", BcryptVerifier{})
+	registry.Register("$scrypt$", ScryptVerifier{})
+	registry.Register("$pbkdf2-sha256$", PBKDF2Verifier{})
+	registry.Register("pbkdf2_sha256$", PBKDF2Verifier{})
+	return registry
+}
+
+// Register associa um verificador aos hashes que começam com o prefixo informado.
+func (r *PasswordHasherRegistry) Register(prefix string, verifier PasswordVerifier) {
+	r.verifiers = append(r.verifiers, prefixedVerifier{prefix: prefix, verifier: verifier})
+}
+
+// HashPassword gera o hash com o hasher principal.
+func (r *PasswordHasherRegistry) HashPassword(password string) (string, error) {
+	return r.primary.HashPassword(password)
+}
+
+// VerifyPassword verifica a senha com o verificador correspondente ao prefixo do hash.
+func (r *PasswordHasherRegistry) VerifyPassword(password, encodedHash string) (bool, error) {
+	if verifier, ok := r.legacyVerifier(encodedHash); ok {
+		return verifier.VerifyPassword(password, encodedHash)
+	}
+	return r.primary.VerifyPassword(password, encodedHash)
+}
+
+// NeedsRehash retorna true para hashes de verificadores legados, que devem migrar para o hasher principal.
+func (r *PasswordHasherRegistry) NeedsRehash(encodedHash string) bool {
+	if _, ok := r.legacyVerifier(encodedHash); ok {
+		return true
+	}
+	return r.primary.NeedsRehash(encodedHash)
+}
+
+func (r *PasswordHasherRegistry) legacyVerifier(encodedHash string) (PasswordVerifier, bool) {
+	for _, entry := range r.verifiers {
+		if strings.HasPrefix(encodedHash, entry.prefix) {
+			return entry.verifier, true
+		}
+	}
+	return nil, false
+}
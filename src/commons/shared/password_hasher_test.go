@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+func legacyHashes(t *testing.T, password string) map[string]string {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to generate bcrypt hash: %v", err)
+	}
+
+	salt := []byte("0123456789abcdef")
+	scryptKey, _ := scrypt.Key([]byte(password), salt, 1<<10, 8, 1, 32)
+	pbkdf2Key := pbkdf2.Key([]byte(password), salt, 1000, 32, sha256.New)
+	djangoKey := pbkdf2.Key([]byte(password), []byte("djangosalt"), 1000, 32, sha256.New)
+
+	passlib := func(b []byte) string {
+		return strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(b), "+", ".")
+	}
+
+	return map[string]string{
+		"bcrypt":         string(bcryptHash),
+		"bcrypt $2y$":    "$2y$" + string(bcryptHash)[4:],
+		"scrypt":         fmt.Sprintf("$scrypt$ln=10,r=8,p=1$%s$%s", passlib(salt), passlib(scryptKey)),
+		"pbkdf2 passlib": fmt.Sprintf("$pbkdf2-sha256$1000$%s$%s", passlib(salt), passlib(pbkdf2Key)),
+		"pbkdf2 django":  "pbkdf2_sha256$1000$djangosalt$" + base64.StdEncoding.EncodeToString(djangoKey),
+	}
+}
+
+func TestPasswordHasherRegistry_LegacyHashes(t *testing.T) {
+	registry := NewDefaultPasswordHasher(NewArgon2ManagerWithParams(Argon2Params{Memory: 8 * 1024, Threads: 1}))
+
+	for name, hash := range legacyHashes(t, "mypassword") {
+		t.Run(name, func(t *testing.T) {
+			if matched, err := registry.VerifyPassword("mypassword", hash); err != nil || !matched {
+				t.Fatalf("Expected legacy hash to verify: %v", err)
+			}
+
+			if matched, _ := registry.VerifyPassword("wrongpassword", hash); matched {
+				t.Error("Expected wrong password not to match")
+			}
+
+			if !registry.NeedsRehash(hash) {
+				t.Error("Expected legacy hash to need rehash")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherRegistry_Primary(t *testing.T) {
+	primary := NewArgon2ManagerWithParams(Argon2Params{Memory: 8 * 1024, Threads: 1})
+	registry := NewDefaultPasswordHasher(primary)
+
+	hash, err := registry.HashPassword("mypassword")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		t.Errorf("Expected new hashes to use Argon2id, got %s", hash)
+	}
+
+	if matched, err := registry.VerifyPassword("mypassword", hash); err != nil || !matched {
+		t.Fatalf("Expected hash to verify: %v", err)
+	}
+
+	if registry.NeedsRehash(hash) {
+		t.Error("Expected current Argon2id hash not to need rehash")
+	}
+}
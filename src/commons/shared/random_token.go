@@ -0,0 +1,37 @@
+package shared
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// GenerateRandomToken cria um token aleatório com o número de bytes informado, codificado em base64 para URLs.
+func GenerateRandomToken(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// GenerateNumericCode cria um código aleatório com a quantidade de dígitos informada, mantendo os zeros à esquerda.
+// Por ter pouca entropia, o código deve expirar rápido e ter as tentativas limitadas.
+func GenerateNumericCode(digits int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// HashToken retorna o SHA-256 do token em hexadecimal. Usado para guardar tokens de alta entropia
+// sem armazenar o valor original; senhas devem continuar usando o Argon2Manager.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
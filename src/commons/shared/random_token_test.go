@@ -0,0 +1,51 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRandomToken(t *testing.T) {
+	first, err := GenerateRandomToken(32)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	second, _ := GenerateRandomToken(32)
+
+	if first == "" || first == second {
+		t.Error("Expected unique non-empty tokens")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	if HashToken("token") != HashToken("token") {
+		t.Error("Expected hash to be deterministic")
+	}
+
+	if HashToken("token") == HashToken("other") {
+		t.Error("Expected different tokens to have different hashes")
+	}
+
+	if len(HashToken("token")) != 64 {
+		t.Errorf("Expected 64 hex characters, got %d", len(HashToken("token")))
+	}
+}
+
+func TestGenerateNumericCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := GenerateNumericCode(6)
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		if len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+			t.Fatalf("Expected 6 digits, got %q", code)
+		}
+		seen[code] = true
+	}
+
+	if len(seen) < 45 {
+		t.Errorf("Expected mostly unique codes, got %d distinct out of 50", len(seen))
+	}
+}
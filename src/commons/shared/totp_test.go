@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Vetores de teste SHA1 do apêndice B da RFC 6238
+func TestHOTP_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		got := hotp(key, uint64(TOTPStep(time.Unix(tt.unix, 0))), 8)
+		if got != tt.expected {
+			t.Errorf("T=%d: expected %s, got %s", tt.unix, tt.expected, got)
+		}
+	}
+}
+
+func TestTOTPCodeAndVerify(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(59, 0)
+
+	code, err := TOTPCode(secret, now)
+	if err != nil || code != "287082" {
+		t.Fatalf("Expected 287082, got %s (%v)", code, err)
+	}
+
+	step, ok := VerifyTOTP(secret, code, now.Add(TOTPPeriod), 0)
+	if !ok || step != TOTPStep(now) {
+		t.Fatalf("Expected code from previous period to be accepted")
+	}
+
+	if _, ok := VerifyTOTP(secret, code, now, step); ok {
+		t.Error("Expected an already used code to be rejected")
+	}
+
+	if _, ok := VerifyTOTP(secret, code, now.Add(3*TOTPPeriod), 0); ok {
+		t.Error("Expected code outside of the skew window to be rejected")
+	}
+
+	if _, ok := VerifyTOTP(secret, "000000", now, 0); ok {
+		t.Error("Expected wrong code to be rejected")
+	}
+}
+
+func TestGenerateTOTPSecretAndURI(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+
+	if _, err := TOTPCode(secret, time.Now()); err != nil {
+		t.Fatalf("Expected generated secret to be valid base32: %v", err)
+	}
+
+	uri := TOTPURI("server", "12345678900", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/server:12345678900?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("Unexpected otpauth URI: %s", uri)
+	}
+}
@@ -1,10 +1,38 @@
 package shared
 
 import (
+	"errors"
+	"net/mail"
 	"strconv"
 	"strings"
 )
 
+// maxEmailLength is the longest address accepted by SMTP (RFC 5321).
+const maxEmailLength = 254
+
+// ErrInvalidEmail is returned by NormalizeEmail for malformed addresses.
+var ErrInvalidEmail = errors.New("E-mail com formato inválido")
+
+// NormalizeEmail trims and lowercases an email address so that it can be compared and stored uniquely.
+// Display names ("Ana <ana@example.com>") and addresses without a domain are rejected.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || len(email) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 1 || !strings.Contains(email[at+1:], ".") {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
 // IsValidCPF checks if the given CPF string is valid.
 func IsValidCPF(cpf string) bool {
 	cpf = strings.Join(strings.Fields(cpf), "")
@@ -28,3 +28,28 @@ func TestIsValidCPF(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected string
+		valid    bool
+	}{
+		{"ana@example.com", "ana@example.com", true},
+		{"  Ana.Souza@Example.COM ", "ana.souza@example.com", true}, // Válido (normalizado)
+		{"ana+tag@mail.example.com.br", "ana+tag@mail.example.com.br", true},
+		{"Ana <ana@example.com>", "", false}, // Inválido (nome de exibição)
+		{"ana@localhost", "", false},         // Inválido (domínio sem ponto)
+		{"ana.example.com", "", false},       // Inválido (sem @)
+		{"@example.com", "", false},          // Inválido (sem parte local)
+		{"ana@@example.com", "", false},      // Inválido (dois @)
+		{"", "", false},                      // Inválido (string vazia)
+	}
+
+	for _, test := range tests {
+		result, err := NormalizeEmail(test.email)
+		if (err == nil) != test.valid || result != test.expected {
+			t.Errorf("Expected NormalizeEmail(%q) to be %q (valid=%v), but got %q, %v", test.email, test.expected, test.valid, result, err)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package api
 import (
 	"github.com/gofiber/contrib/swagger"
 	"log"
+	"server/src/commons/shared"
 	"server/src/layers/app/di"
 	"server/src/layers/app/handlers"
 	"server/src/layers/app/middleware"
+	"server/src/layers/domain/models"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,6 +16,13 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
+// passwordChangeRoutes são as únicas rotas aceitas para tokens emitidos com a senha expirada
+var passwordChangeRoutes = []string{
+	"POST /me/password",
+	"POST /sign-out",
+	"POST /sign-out/all",
+}
+
 type FiberServer struct {
 	App       *fiber.App
 	Container *di.Container
@@ -21,7 +30,11 @@ type FiberServer struct {
 
 func NewFiberServer(container *di.Container) *FiberServer {
 	app := fiber.New(fiber.Config{
-		// You can define some initial configurations here, if necessary.
+		// O IP do cliente, usado no bloqueio de tentativas, só é lido do header quando a conexão vem de um proxy confiável
+		ProxyHeader:             container.Proxy.Header,
+		EnableTrustedProxyCheck: len(container.Proxy.TrustedProxies) > 0,
+		TrustedProxies:          container.Proxy.TrustedProxies,
+		EnableIPValidation:      container.Proxy.Header != "",
 	})
 
 	// global middlewares
@@ -53,28 +66,224 @@ func (server *FiberServer) SetupRoutes() {
 
 	server.setupAuthRoutes()
 	server.setupUserRoutes()
+	server.setupOAuthRoutes()
+	server.setupWellKnownRoutes()
+	server.setupMetricsRoutes()
 }
 
 func (server *FiberServer) setupAuthRoutes() {
 	authHandler := handlers.NewAuthHandler(
 		server.Container.AuthHandler.CreateUser,
 		server.Container.AuthHandler.CreateToken,
+		server.Container.AuthHandler.RefreshToken,
+		server.Container.AuthHandler.SignOutUser,
+		server.Container.SessionCookies,
 	)
 
+	jwtMiddleware := server.jwtMiddleware()
+
 	server.App.Post("/sign-up", authHandler.SignUp)
 	server.App.Post("/sign-in", authHandler.SignIn)
+	server.App.Post("/token/refresh", authHandler.Refresh)
+	server.App.Post("/sign-out", jwtMiddleware, authHandler.SignOut)
+	server.App.Post("/sign-out/all", jwtMiddleware, authHandler.SignOutAll)
+
+	server.setupPasswordlessRoutes()
 }
 
 func (server *FiberServer) setupUserRoutes() {
-	jwtMiddleware := middleware.NewJWTMiddleware(server.Container.JWT)
-	secureGroup := server.App.Group("/users", jwtMiddleware)
+	authMiddleware := server.authMiddleware()
+	secureGroup := server.App.Group("/users", authMiddleware)
 
 	userHandler := handlers.NewUserHandler(
 		server.Container.UserHandler.GetUser,
+		server.Container.UserHandler.UpdateUser,
+		server.Container.UserHandler.AssignRoles,
+		server.Container.UserHandler.Unlock,
+	)
+
+	// profile:read libera apenas o próprio registro e users:read os demais; as políticas decidem qual se aplica
+	secureGroup.Get("/:id", middleware.RequireAnyPermission(models.PermissionProfileRead, models.PermissionUsersRead), userHandler.Get)
+	secureGroup.Get("/", middleware.RequirePermission(models.PermissionUsersRead), userHandler.GetAll)
+	secureGroup.Put("/:id/roles", middleware.RequirePermission(models.PermissionRolesWrite), userHandler.SetRoles)
+	secureGroup.Post("/:id/unlock", middleware.RequirePermission(models.PermissionUsersWrite), userHandler.UnlockUser)
+
+	meGroup := server.App.Group("/me", authMiddleware)
+	meGroup.Get("/", middleware.RequirePermission(models.PermissionProfileRead), userHandler.Me)
+	meGroup.Patch("/", middleware.RequirePermission(models.PermissionProfileWrite), userHandler.UpdateMe)
+
+	server.setupPasswordRoutes(meGroup)
+	server.setupTwoFactorRoutes(meGroup)
+	server.setupAPIKeyRoutes(meGroup)
+	server.setupSessionRoutes(secureGroup, meGroup)
+	server.setupEmailRoutes(meGroup)
+}
+
+// setupEmailRoutes registra a troca do e-mail no grupo /me e a confirmação pública pelo link enviado: o GET só
+// serve a página que pede a confirmação, e o e-mail é confirmado pelo POST que ela envia.
+func (server *FiberServer) setupEmailRoutes(meGroup fiber.Router) {
+	emailHandler := handlers.NewEmailHandler(
+		server.Container.EmailHandler.Change,
+		server.Container.EmailHandler.Resend,
+		server.Container.EmailHandler.Verify,
 	)
 
-	secureGroup.Get("/:id", userHandler.Get)
-	secureGroup.Get("/", userHandler.GetAll)
+	requireUserSession := middleware.RequireUserSession()
+
+	meGroup.Put("/email", requireUserSession, emailHandler.ChangeMe)
+	meGroup.Post("/email/verification", requireUserSession, emailHandler.ResendMe)
+	server.App.Get("/email/verify", emailHandler.VerifyPage)
+	server.App.Post("/email/verify", emailHandler.VerifyToken)
+}
+
+// setupSessionRoutes registra as sessões e o histórico de login do usuário autenticado no grupo /me
+// e os equivalentes administrativos de qualquer usuário no grupo /users.
+func (server *FiberServer) setupSessionRoutes(usersGroup fiber.Router, meGroup fiber.Router) {
+	sessionHandler := handlers.NewSessionHandler(
+		server.Container.SessionHandler.List,
+		server.Container.SessionHandler.Revoke,
+		server.Container.SessionHandler.History,
+		server.Container.SessionHandler.Track,
+	)
+
+	requireUserSession := middleware.RequireUserSession()
+
+	meGroup.Get("/sessions", requireUserSession, sessionHandler.ListMe)
+	meGroup.Delete("/sessions/:sessionId", requireUserSession, sessionHandler.RevokeMe)
+	meGroup.Get("/sign-ins", requireUserSession, sessionHandler.HistoryMe)
+
+	usersGroup.Get("/:id/sessions", middleware.RequirePermission(models.PermissionSessionsRead), sessionHandler.ListUser)
+	usersGroup.Delete("/:id/sessions/:sessionId", middleware.RequirePermission(models.PermissionSessionsWrite), sessionHandler.RevokeUser)
+	usersGroup.Get("/:id/sign-ins", middleware.RequirePermission(models.PermissionSessionsRead), sessionHandler.HistoryUser)
+}
+
+// setupPasswordRoutes registra a troca de senha no grupo /me, já autenticado, e as rotas públicas de redefinição.
+func (server *FiberServer) setupPasswordRoutes(meGroup fiber.Router) {
+	passwordHandler := handlers.NewPasswordHandler(
+		server.Container.PasswordHandler.ChangePassword,
+		server.Container.PasswordHandler.RequestReset,
+		server.Container.PasswordHandler.ConfirmReset,
+	)
+
+	meGroup.Post("/password", middleware.RequireUserSession(), passwordHandler.Change)
+	server.App.Post("/password/reset", passwordHandler.RequestResetToken)
+	server.App.Post("/password/reset/confirm", passwordHandler.ConfirmResetToken)
+}
+
+// setupTwoFactorRoutes registra o cadastro do segundo fator no grupo /me e a conclusão do login em /sign-in/2fa.
+func (server *FiberServer) setupTwoFactorRoutes(meGroup fiber.Router) {
+	twoFactorHandler := handlers.NewTwoFactorHandler(
+		server.Container.TwoFactorHandler.Enroll,
+		server.Container.TwoFactorHandler.Confirm,
+		server.Container.TwoFactorHandler.Complete,
+		server.Container.SessionCookies,
+	)
+
+	meGroup.Post("/2fa/enroll", middleware.RequireUserSession(), twoFactorHandler.EnrollMe)
+	meGroup.Post("/2fa/confirm", middleware.RequireUserSession(), twoFactorHandler.ConfirmMe)
+	server.App.Post("/sign-in/2fa", twoFactorHandler.SignIn)
+}
+
+// setupPasswordlessRoutes registra o login sem senha por link ou código enviado ao e-mail confirmado.
+func (server *FiberServer) setupPasswordlessRoutes() {
+	passwordlessHandler := handlers.NewPasswordlessHandler(
+		server.Container.Passwordless.Request,
+		server.Container.Passwordless.Redeem,
+		server.Container.SessionCookies,
+	)
+
+	server.App.Post("/sign-in/passwordless", passwordlessHandler.RequestCode)
+	server.App.Get("/sign-in/passwordless/link", passwordlessHandler.LinkPage)
+	server.App.Post("/sign-in/passwordless/verify", passwordlessHandler.SignIn)
+}
+
+// setupAPIKeyRoutes registra no grupo /me o gerenciamento das chaves de api, que exige uma sessão do usuário.
+func (server *FiberServer) setupAPIKeyRoutes(meGroup fiber.Router) {
+	apiKeyHandler := handlers.NewAPIKeyHandler(
+		server.Container.APIKeyHandler.Create,
+		server.Container.APIKeyHandler.List,
+		server.Container.APIKeyHandler.Revoke,
+		server.Container.APIKeyHandler.Authenticate,
+	)
+
+	requireUserSession := middleware.RequireUserSession()
+
+	meGroup.Post("/api-keys", requireUserSession, apiKeyHandler.CreateMe)
+	meGroup.Get("/api-keys", requireUserSession, apiKeyHandler.ListMe)
+	meGroup.Delete("/api-keys/:id", requireUserSession, apiKeyHandler.RevokeMe)
+}
+
+// setupOAuthRoutes registra o servidor de autorização OAuth 2.0. A autorização exige uma sessão do usuário,
+// o cadastro de clientes exige clients:write e os endpoints de tokens, introspecção e revogação autenticam os próprios clientes.
+func (server *FiberServer) setupOAuthRoutes() {
+	oauthHandler := handlers.NewOAuthHandler(
+		server.Container.OAuthHandler.RegisterClient,
+		server.Container.OAuthHandler.ListClients,
+		server.Container.OAuthHandler.DeleteClient,
+		server.Container.OAuthHandler.Authorize,
+		server.Container.OAuthHandler.Token,
+		server.Container.OAuthHandler.UserInfo,
+		server.Container.OAuthHandler.AuthenticateClient,
+		server.Container.OAuthHandler.Introspect,
+		server.Container.OAuthHandler.RevokeToken,
+	)
+
+	authMiddleware := server.authMiddleware()
+	requireUserSession := middleware.RequireUserSession()
+
+	clientsGroup := server.App.Group("/oauth/clients", authMiddleware, middleware.RequirePermission(models.PermissionClientsWrite))
+	clientsGroup.Post("/", oauthHandler.CreateClient)
+	clientsGroup.Get("/", oauthHandler.GetClients)
+	clientsGroup.Delete("/:clientId", oauthHandler.RemoveClient)
+
+	server.App.Get("/oauth/authorize", authMiddleware, requireUserSession, oauthHandler.AuthorizeRequest)
+	server.App.Post("/oauth/authorize", authMiddleware, requireUserSession, oauthHandler.AuthorizeDecision)
+	server.App.Post("/oauth/token", oauthHandler.IssueToken)
+
+	// Introspecção e revogação aceitam as credenciais de um cliente oauth ou, na ausência delas, uma chave de api
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(server.Container.APIKeyHandler.Authenticate, nil)
+	clientMiddleware := middleware.NewOAuthClientMiddleware(server.Container.OAuthHandler.AuthenticateClient, apiKeyMiddleware)
+	server.App.Post("/oauth/introspect", clientMiddleware, oauthHandler.IntrospectToken)
+	server.App.Post("/oauth/revoke", clientMiddleware, oauthHandler.RevokeTokenRequest)
+
+	// O /userinfo aceita apenas tokens JWT, pois depende dos escopos do OpenID Connect concedidos ao cliente
+	jwtMiddleware := server.jwtMiddleware()
+	server.App.Get("/userinfo", jwtMiddleware, oauthHandler.GetUserInfo)
+	server.App.Post("/userinfo", jwtMiddleware, oauthHandler.GetUserInfo)
+}
+
+func (server *FiberServer) setupWellKnownRoutes() {
+	wellKnownHandler := handlers.NewWellKnownHandler(server.Container.JWT)
+
+	server.App.Get("/.well-known/jwks.json", wellKnownHandler.JWKS)
+	server.App.Get("/.well-known/openid-configuration", wellKnownHandler.OpenIDConfiguration)
+}
+
+func (server *FiberServer) setupMetricsRoutes() {
+	metricsHandler := handlers.NewMetricsHandler(server.Container.PasswordHasher)
+
+	// As métricas expõem a carga do servidor e não são públicas; coletores usam uma chave de api com metrics:read
+	metricsGroup := server.App.Group("/metrics", server.authMiddleware(), middleware.RequirePermission(models.PermissionMetricsRead))
+	metricsGroup.Get("/password-hasher", metricsHandler.PasswordHasher)
+}
+
+// jwtMiddleware cria o middleware de autenticação que consulta o armazenamento de revogações,
+// aceita o token do cookie da sessão e limita os tokens de senha expirada às rotas de troca de senha.
+func (server *FiberServer) jwtMiddleware() fiber.Handler {
+	return middleware.NewJWTMiddlewareWithConfig(middleware.JWTMiddlewareConfig{
+		Manager:     server.Container.JWT,
+		Revocations: server.Container.Revocations,
+		RestrictedScopes: map[string][]string{
+			shared.ScopePasswordChange: passwordChangeRoutes,
+		},
+		SessionCookies: server.Container.SessionCookies,
+		Sessions:       &server.Container.SessionHandler.Track,
+	})
+}
+
+// authMiddleware aceita chaves de api e, na ausência delas, tokens JWT.
+func (server *FiberServer) authMiddleware() fiber.Handler {
+	return middleware.NewAPIKeyMiddleware(server.Container.APIKeyHandler.Authenticate, server.jwtMiddleware())
 }
 
 func (server *FiberServer) Run(port int) {
@@ -1,24 +1,57 @@
 package di
 
 import (
+	"errors"
 	"gorm.io/gorm"
+	"net"
+	"os"
 	"server/src/commons/config"
 	"server/src/commons/shared"
 	"server/src/layers/app/handlers"
+	"server/src/layers/app/middleware"
+	"server/src/layers/domain/authn"
+	"server/src/layers/domain/introspection"
+	"server/src/layers/domain/lockout"
+	"server/src/layers/domain/models"
+	domainnotification "server/src/layers/domain/notification"
+	"server/src/layers/domain/passwordpolicy"
+	"server/src/layers/domain/policy"
 	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/breachlist"
+	"server/src/layers/infrastructure/ldap"
+	"server/src/layers/infrastructure/notification"
 	"server/src/layers/infrastructure/persistence"
 	"server/src/layers/service/commands"
 	"server/src/layers/service/queries"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 )
 
 type Container struct {
-	AuthHandler  handlers.AuthHandler
-	UserHandler  handlers.UserHandler
-	JWT          *shared.JWTManager
-	Argon2Config Argon2Config
+	AuthHandler      handlers.AuthHandler
+	UserHandler      handlers.UserHandler
+	PasswordHandler  handlers.PasswordHandler
+	TwoFactorHandler handlers.TwoFactorHandler
+	APIKeyHandler    handlers.APIKeyHandler
+	OAuthHandler     handlers.OAuthHandler
+	JWT              *shared.JWTManager
+	Revocations      repository.TokenRevocationRepository
+	Argon2Config     shared.Argon2Params
+	PasswordHasher   *shared.Argon2Manager
+	SessionCookies   middleware.SessionCookies
+	SessionHandler   handlers.SessionHandler
+	EmailHandler     handlers.EmailHandler
+	Passwordless     handlers.PasswordlessHandler
+	Proxy            ProxySettings
+}
+
+// ProxySettings define de onde vem o IP do cliente atrás de um proxy reverso. O header só é aceito nas
+// conexões vindas de TrustedProxies; sem Header, vale o endereço da conexão.
+type ProxySettings struct {
+	Header         string
+	TrustedProxies []string
 }
 
 // InitializeContainer configura todas as dependências para o aplicativo.
@@ -28,24 +61,254 @@ func InitializeContainer() *Container {
 	db := connectToDatabase()
 	//defer persistence.Close(db)
 
-	jwtManager := shared.NewJWTManager(cfg.JWTSecret, 24*time.Hour, (7*24)*time.Hour)
-	argonManager := shared.NewArgon2Manager()
+	jwtManager := initializeJWTManager(cfg)
+	argonManager := initializeArgon2Manager(cfg)
+	passwordHasher := shared.NewDefaultPasswordHasher(argonManager)
+	passwordPolicy := initializePasswordPolicy(cfg)
+	passwordMaxAge := time.Duration(cfg.PasswordMaxAgeDays) * 24 * time.Hour
+	sessionCookies := initializeSessionCookies(cfg, jwtManager)
+	mailNotifier := notification.NewMailNotifier(initializeMailer(cfg))
+	emailVerification := initializeEmailVerification(cfg, jwtManager, mailNotifier)
 
 	userRepo := persistence.NewUserRepository(db)
+	refreshTokenRepo := persistence.NewRefreshTokenRepository(db)
+	revocationRepo := initializeRevocationStore(cfg, db)
+	sessionRepo := persistence.NewSessionRepository(db)
+	historyRepo := persistence.NewSignInHistoryRepository(db)
+	authenticators := initializeAuthenticators(cfg, passwordHasher, userRepo)
+	seedAdmin(cfg, passwordHasher, passwordPolicy, userRepo)
 
-	userHandler := initializeUserHandler(userRepo)
-	authHandler := initializeAuthHandler(argonManager, jwtManager, userRepo)
+	policyEngine := initializePolicyEngine(cfg)
+	limiter := initializeLoginLimiter(cfg, db)
 
-	argonConfig := DefaultArgon2Config()
+	userHandler := initializeUserHandler(jwtManager, userRepo, refreshTokenRepo, revocationRepo, policyEngine, limiter)
+	authHandler := initializeAuthHandler(cfg, passwordHasher, passwordPolicy, passwordMaxAge, sessionCookies, emailVerification, authenticators, jwtManager, limiter, userRepo, refreshTokenRepo, revocationRepo, sessionRepo, historyRepo)
+	twoFactorHandler := initializeTwoFactorHandler(cfg, db, passwordHasher, sessionCookies, &authHandler.CreateToken, userRepo)
+	apiKeyHandler := initializeAPIKeyHandler(db, userRepo)
+	oauthHandler := initializeOAuthHandler(db, passwordMaxAge, jwtManager, userRepo, refreshTokenRepo, revocationRepo)
+	sessionHandler := initializeSessionHandler(sessionRepo, historyRepo, refreshTokenRepo)
+	emailHandler := initializeEmailHandler(emailVerification, jwtManager, userRepo)
+	passwordlessHandler := initializePasswordlessHandler(cfg, db, sessionCookies, mailNotifier, &authHandler.CreateToken, userRepo)
+	passwordHandler := initializePasswordHandler(cfg, db, passwordHasher, passwordPolicy, mailNotifier, jwtManager, userRepo, refreshTokenRepo, revocationRepo)
 
 	return &Container{
-		AuthHandler:  authHandler,
-		UserHandler:  userHandler,
-		JWT:          jwtManager,
-		Argon2Config: argonConfig,
+		AuthHandler:      authHandler,
+		UserHandler:      userHandler,
+		PasswordHandler:  passwordHandler,
+		TwoFactorHandler: twoFactorHandler,
+		APIKeyHandler:    apiKeyHandler,
+		OAuthHandler:     oauthHandler,
+		JWT:              jwtManager,
+		Revocations:      revocationRepo,
+		Argon2Config:     argonManager.Params(),
+		PasswordHasher:   argonManager,
+		SessionCookies:   sessionCookies,
+		SessionHandler:   sessionHandler,
+		EmailHandler:     emailHandler,
+		Passwordless:     passwordlessHandler,
+		Proxy:            initializeProxySettings(cfg),
+	}
+}
+
+// initializeProxySettings valida PROXY_HEADER e TRUSTED_PROXIES. Um header aceito de qualquer origem permitiria
+// que o cliente escolhesse o próprio IP e escapasse do bloqueio por IP, por isso ele exige os proxies confiáveis.
+func initializeProxySettings(cfg *config.Config) ProxySettings {
+	settings := ProxySettings{
+		Header:         strings.TrimSpace(cfg.ProxyHeader),
+		TrustedProxies: splitList(cfg.TrustedProxies),
+	}
+
+	if settings.Header != "" && len(settings.TrustedProxies) == 0 {
+		log.Fatal("PROXY_HEADER exige TRUSTED_PROXIES com os endereços dos proxies")
+	}
+	for _, proxy := range settings.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			log.Fatalf("endereço inválido em TRUSTED_PROXIES: %s", proxy)
+		}
+	}
+	return settings
+}
+
+// initializeSessionCookies configura a entrega dos tokens em cookies, com a mesma duração dos tokens.
+func initializeSessionCookies(cfg *config.Config, jwtManager *shared.JWTManager) middleware.SessionCookies {
+	mode := cfg.SessionCookieMode
+	switch mode {
+	case middleware.SessionCookieModeDisabled, middleware.SessionCookieModeOptional, middleware.SessionCookieModeAlways:
+	default:
+		log.Warnf("SESSION_COOKIE_MODE inválido: %s, usando %s", mode, middleware.SessionCookieModeOptional)
+		mode = middleware.SessionCookieModeOptional
+	}
+
+	return middleware.SessionCookies{
+		Mode:            mode,
+		Secure:          cfg.SessionCookieSecure,
+		SameSite:        cfg.SessionCookieSameSite,
+		Domain:          cfg.SessionCookieDomain,
+		TokenDuration:   jwtManager.TokenDuration(),
+		RefreshDuration: jwtManager.RefreshDuration(),
 	}
 }
 
+// initializeRevocationStore escolhe o armazenamento de revogações e agenda a limpeza das entradas expiradas.
+func initializeRevocationStore(cfg *config.Config, db *gorm.DB) repository.TokenRevocationRepository {
+	var store repository.TokenRevocationRepository
+	if cfg.RevocationStore == "memory" {
+		store = persistence.NewMemoryTokenRevocationRepository()
+	} else {
+		store = persistence.NewTokenRevocationRepository(db)
+	}
+
+	interval := time.Duration(cfg.RevocationPurgeIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	persistence.StartRevocationPurge(store, interval)
+
+	return store
+}
+
+// initializeArgon2Manager configura o custo dos hashes de senha e o limite de cálculos simultâneos.
+// Hashes gerados com outros parâmetros continuam válidos e são refeitos no próximo login.
+func initializeArgon2Manager(cfg *config.Config) *shared.Argon2Manager {
+	params := shared.Argon2Params{
+		Time:    uint32(cfg.Argon2Time),
+		Memory:  uint32(cfg.Argon2MemoryKiB),
+		Threads: uint8(cfg.Argon2Threads),
+		KeyLen:  uint32(cfg.Argon2KeyLength),
+	}
+
+	limits := shared.Argon2Limits{
+		MaxConcurrent: cfg.Argon2MaxConcurrent,
+		MaxQueue:      cfg.Argon2MaxQueue,
+		QueueTimeout:  time.Duration(cfg.Argon2QueueTimeoutMs) * time.Millisecond,
+	}
+
+	manager := shared.NewArgon2ManagerWithLimits(params, limits)
+
+	peppers, err := loadPeppers(cfg)
+	if err != nil {
+		log.Fatalf("falha ao carregar os peppers de senha: %v", err)
+	}
+	if err := manager.SetPeppers(cfg.PasswordPepperID, peppers); err != nil {
+		log.Fatalf("configuração de pepper inválida: %v", err)
+	}
+
+	return manager
+}
+
+// loadPeppers lê os peppers no formato "versão=segredo" do arquivo de segredos (um por linha)
+// e da lista PASSWORD_PEPPERS. Linhas vazias ou iniciadas por # são ignoradas.
+func loadPeppers(cfg *config.Config) (map[string][]byte, error) {
+	entries := splitList(cfg.PasswordPeppers)
+
+	if cfg.PasswordPeppersFile != "" {
+		content, err := os.ReadFile(cfg.PasswordPeppersFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
+		}
+	}
+
+	peppers := make(map[string][]byte)
+	for _, entry := range entries {
+		id, secret, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, errors.New("pepper inválido, use o formato versão=segredo")
+		}
+		peppers[strings.TrimSpace(id)] = []byte(secret)
+	}
+	return peppers, nil
+}
+
+// initializePasswordPolicy configura as regras aplicadas às novas senhas, incluindo a consulta
+// offline ao arquivo de senhas vazadas quando configurado.
+func initializePasswordPolicy(cfg *config.Config) *passwordpolicy.Policy {
+	passwordPolicy := &passwordpolicy.Policy{
+		MinLength:  cfg.PasswordMinLength,
+		MaxLength:  cfg.PasswordMaxLength,
+		MinClasses: cfg.PasswordMinCharacterClasses,
+	}
+
+	if cfg.PasswordBreachedFile != "" {
+		breached, err := breachlist.Open(cfg.PasswordBreachedFile)
+		if err != nil {
+			log.Fatalf("falha ao abrir o arquivo de senhas vazadas: %v", err)
+		}
+		passwordPolicy.Breached = breached
+	}
+
+	return passwordPolicy
+}
+
+// initializeLoginLimiter configura o controle de tentativas de login por conta e por IP.
+func initializeLoginLimiter(cfg *config.Config, db *gorm.DB) *lockout.Limiter {
+	baseDelay := time.Duration(cfg.LoginLockoutBaseSeconds) * time.Second
+	maxDelay := time.Duration(cfg.LoginLockoutMaxSeconds) * time.Second
+
+	return lockout.NewLimiter(
+		persistence.NewLoginAttemptRepository(db),
+		lockout.Rule{MaxAttempts: cfg.LoginMaxAttempts, BaseDelay: baseDelay, MaxDelay: maxDelay},
+		lockout.Rule{MaxAttempts: cfg.LoginMaxAttemptsPerIP, BaseDelay: baseDelay, MaxDelay: maxDelay},
+	)
+}
+
+// initializeJWTManager configura a emissão de tokens, carregando as chaves PEM quando configuradas.
+func initializeJWTManager(cfg *config.Config) *shared.JWTManager {
+	jwtConfig := shared.JWTConfig{
+		SecretKey:       cfg.JWTSecret,
+		TokenDuration:   24 * time.Hour,
+		RefreshDuration: (7 * 24) * time.Hour,
+		Issuer:          cfg.JWTIssuer,
+		Audience:        cfg.JWTAudience,
+	}
+
+	if cfg.JWTPrivateKeyFile != "" {
+		signingKey, err := shared.LoadSigningKey(cfg.JWTKeyID, cfg.JWTAlgorithm, cfg.JWTPrivateKeyFile)
+		if err != nil {
+			log.Fatalf("falha ao carregar a chave de assinatura: %v", err)
+		}
+		jwtConfig.SigningKey = signingKey
+	} else if cfg.JWTAlgorithm != "" && cfg.JWTAlgorithm != "HS256" {
+		log.Fatalf("o algoritmo %s exige JWT_PRIVATE_KEY_FILE", cfg.JWTAlgorithm)
+	}
+
+	for _, entry := range splitList(cfg.JWTVerificationKeys) {
+		kid, path, found := strings.Cut(entry, "=")
+		if !found {
+			log.Fatalf("chave de verificação inválida, use o formato kid=arquivo.pem: %s", entry)
+		}
+
+		verificationKey, err := shared.LoadVerificationKey(kid, "", path)
+		if err != nil {
+			log.Fatalf("falha ao carregar a chave de verificação %s: %v", kid, err)
+		}
+		jwtConfig.VerificationKeys = append(jwtConfig.VerificationKeys, verificationKey)
+	}
+
+	jwtManager := shared.NewJWTManagerWithConfig(jwtConfig)
+	if !jwtManager.SupportsOpenID() {
+		log.Warn("OpenID Connect desativado: os tokens são assinados com JWT_SECRET; configure JWT_PRIVATE_KEY_FILE para emitir ID tokens")
+	}
+	return jwtManager
+}
+
+// initializePolicyEngine carrega as regras de acesso do arquivo configurado ou usa as regras padrão.
+func initializePolicyEngine(cfg *config.Config) *policy.Engine {
+	rules := policy.DefaultRules()
+	if cfg.PolicyFile != "" {
+		loaded, err := policy.LoadFile(cfg.PolicyFile)
+		if err != nil {
+			log.Fatalf("falha ao carregar as políticas de acesso: %v", err)
+		}
+		rules = loaded
+	}
+	return policy.NewEngine(rules, policy.LogDecisionLogger{})
+}
+
 // connectToDatabase estabelece uma conexão com o banco de dados.
 func connectToDatabase() *gorm.DB {
 	db, err := persistence.Connect()
@@ -55,42 +318,332 @@ func connectToDatabase() *gorm.DB {
 	return db
 }
 
+// seedAdmin cria o administrador inicial configurado em ADMIN_SEED_*, antes de o servidor aceitar requisições.
+// A senha vem de ADMIN_SEED_PASSWORD_FILE (a primeira linha) ou de ADMIN_SEED_PASSWORD.
+func seedAdmin(cfg *config.Config, hasher shared.PasswordHasher, passwordPolicy *passwordpolicy.Policy, repo repository.UserRepository) {
+	if cfg.AdminSeedCPF == "" {
+		return
+	}
+
+	password := cfg.AdminSeedPassword
+	if cfg.AdminSeedPasswordFile != "" {
+		content, err := os.ReadFile(cfg.AdminSeedPasswordFile)
+		if err != nil {
+			log.Fatalf("falha ao ler a senha do administrador inicial: %v", err)
+		}
+		password, _, _ = strings.Cut(string(content), "\n")
+		password = strings.TrimRight(password, "\r")
+	}
+
+	command := commands.BootstrapAdminCommand{
+		CPF:       cfg.AdminSeedCPF,
+		FirstName: cfg.AdminSeedFirstName,
+		LastName:  cfg.AdminSeedLastName,
+		Password:  password,
+	}
+	if err := command.Validate(); err != nil {
+		log.Fatalf("administrador inicial inválido: %v", err)
+	}
+
+	handler := commands.BootstrapAdminHandler{Repo: repo, Hasher: hasher, PasswordPolicy: passwordPolicy}
+	user, created, err := handler.Handle(command)
+	if err != nil {
+		log.Fatalf("falha ao criar o administrador inicial: %v", err)
+	}
+	if created {
+		log.Infof("administrador inicial %s criado", user.ID)
+	}
+}
+
 // initializeUserHandler cria um novo UserHandler com suas dependências necessárias.
-func initializeUserHandler(repo repository.UserRepository) handlers.UserHandler {
-	getUserQueryHandler := queries.GetUserQueryHandler{Repo: repo}
+func initializeUserHandler(jwtManager *shared.JWTManager, repo repository.UserRepository, tokenRepo repository.RefreshTokenRepository, revocationRepo repository.TokenRevocationRepository, policyEngine *policy.Engine, limiter *lockout.Limiter) handlers.UserHandler {
+	getUserQueryHandler := queries.GetUserQueryHandler{Repo: repo, Policy: policyEngine}
+	updateUserHandler := commands.UpdateUserHandler{Repo: repo, Policy: policyEngine}
+	assignRolesHandler := commands.AssignRolesHandler{Repo: repo, Tokens: tokenRepo, Revocations: revocationRepo, JWT: jwtManager}
+	unlockUserHandler := commands.UnlockUserHandler{Repo: repo, Limiter: limiter}
 
-	return *handlers.NewUserHandler(getUserQueryHandler)
+	return *handlers.NewUserHandler(getUserQueryHandler, updateUserHandler, assignRolesHandler, unlockUserHandler)
 }
 
 // initializeAuthHandler cria um novo AuthHandler com suas dependências necessárias.
-func initializeAuthHandler(argonManager *shared.Argon2Manager, jwtManager *shared.JWTManager, repo repository.UserRepository) handlers.AuthHandler {
+func initializeAuthHandler(cfg *config.Config, hasher shared.PasswordHasher, passwordPolicy *passwordpolicy.Policy, passwordMaxAge time.Duration, sessionCookies middleware.SessionCookies, emailVerification *commands.EmailVerification, authenticators authn.Chain, jwtManager *shared.JWTManager, limiter *lockout.Limiter, repo repository.UserRepository, tokenRepo repository.RefreshTokenRepository, revocationRepo repository.TokenRevocationRepository, sessionRepo repository.SessionRepository, historyRepo repository.SignInHistoryRepository) handlers.AuthHandler {
 	createTokenHandler := commands.CreateTokenHandler{
-		ArgonManager: argonManager,
-		JWT:          jwtManager,
-		Repo:         repo,
+		Hasher:         hasher,
+		JWT:            jwtManager,
+		Limiter:        limiter,
+		Repo:           repo,
+		Tokens:         tokenRepo,
+		PasswordMaxAge: passwordMaxAge,
+		Sessions:       sessionRepo,
+		History:        historyRepo,
+		Authenticators: authenticators,
+	}
+
+	refreshTokenHandler := commands.RefreshTokenHandler{
+		JWT:            jwtManager,
+		Repo:           repo,
+		Tokens:         tokenRepo,
+		PasswordMaxAge: passwordMaxAge,
+		Sessions:       sessionRepo,
+	}
+
+	signOutHandler := commands.SignOutHandler{
+		JWT:         jwtManager,
+		Tokens:      tokenRepo,
+		Revocations: revocationRepo,
 	}
 
 	createUserHandler := commands.CreateUserHandler{
-		ArgonManager: argonManager,
-		Repo:         repo,
+		Hasher:         hasher,
+		PasswordPolicy: passwordPolicy,
+		Repo:           repo,
+		Verification:   emailVerification,
+	}
+
+	return *handlers.NewAuthHandler(createUserHandler, createTokenHandler, refreshTokenHandler, signOutHandler, sessionCookies)
+}
+
+// initializeAuthenticators monta a cadeia de provedores do login: a senha local e, com LDAP_URL, o diretório
+// corporativo, que provisiona os usuários no primeiro login.
+func initializeAuthenticators(cfg *config.Config, hasher shared.PasswordHasher, repo repository.UserRepository) authn.Chain {
+	chain := authn.Chain{commands.NewLocalAuthenticator(repo, hasher)}
+	if cfg.LDAPURL == "" {
+		return chain
+	}
+
+	timeout := time.Duration(cfg.LDAPTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	directory := ldap.NewDirectory(ldap.Config{
+		URL:                cfg.LDAPURL,
+		StartTLS:           cfg.LDAPStartTLS,
+		InsecureSkipVerify: cfg.LDAPInsecureSkipVerify,
+		BindDN:             cfg.LDAPBindDN,
+		BindPassword:       cfg.LDAPBindPassword,
+		BaseDN:             cfg.LDAPBaseDN,
+		UserFilter:         cfg.LDAPUserFilter,
+		Attributes: ldap.Attributes{
+			CPF:       cfg.LDAPAttributeCPF,
+			FirstName: cfg.LDAPAttributeFirstName,
+			LastName:  cfg.LDAPAttributeLastName,
+			Email:     cfg.LDAPAttributeEmail,
+		},
+		Timeout: timeout,
+	})
+	return append(chain, authn.NewDirectoryAuthenticator(models.SignInMethodLDAP, directory, repo))
+}
+
+// initializeMailer escolhe o envio de e-mails configurado em MAILER.
+func initializeMailer(cfg *config.Config) domainnotification.Mailer {
+	switch cfg.Mailer {
+	case "smtp":
+		return notification.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	case "memory":
+		return notification.NewMemoryMailer()
+	case "file":
+	default:
+		log.Warnf("MAILER inválido: %s, usando file", cfg.Mailer)
+	}
+	return notification.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+}
+
+// initializeEmailVerification configura o envio do link de confirmação de e-mail pelo mailer configurado.
+func initializeEmailVerification(cfg *config.Config, jwtManager *shared.JWTManager, notifier domainnotification.EmailVerificationNotifier) *commands.EmailVerification {
+	ttl := time.Duration(cfg.EmailVerificationTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	return &commands.EmailVerification{
+		JWT:      jwtManager,
+		Notifier: notifier,
+		BaseURL:  cfg.AppBaseURL,
+		TTL:      ttl,
+	}
+}
+
+// initializeEmailHandler cria um novo EmailHandler com suas dependências necessárias.
+func initializeEmailHandler(emailVerification *commands.EmailVerification, jwtManager *shared.JWTManager, repo repository.UserRepository) handlers.EmailHandler {
+	changeHandler := commands.ChangeEmailHandler{Repo: repo, Verification: emailVerification}
+	resendHandler := commands.ResendEmailVerificationHandler{Repo: repo, Verification: emailVerification}
+	verifyHandler := commands.VerifyEmailHandler{Repo: repo, JWT: jwtManager}
+
+	return *handlers.NewEmailHandler(changeHandler, resendHandler, verifyHandler)
+}
+
+// initializePasswordlessHandler cria um novo PasswordlessHandler, que emite os tokens pelo mesmo CreateTokenHandler do login com senha.
+func initializePasswordlessHandler(cfg *config.Config, db *gorm.DB, sessionCookies middleware.SessionCookies, notifier domainnotification.LoginCodeNotifier, signIn *commands.CreateTokenHandler, repo repository.UserRepository) handlers.PasswordlessHandler {
+	codeRepo := persistence.NewLoginCodeRepository(db)
+
+	ttl := time.Duration(cfg.PasswordlessCodeTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	window := time.Duration(cfg.PasswordlessWindowMinutes) * time.Minute
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+
+	requestHandler := commands.RequestLoginCodeHandler{
+		Repo:        repo,
+		Codes:       codeRepo,
+		Notifier:    notifier,
+		BaseURL:     cfg.AppBaseURL,
+		TTL:         ttl,
+		MaxRequests: cfg.PasswordlessMaxRequests,
+		Window:      window,
+	}
+
+	redeemHandler := commands.RedeemLoginCodeHandler{
+		Repo:   repo,
+		Codes:  codeRepo,
+		SignIn: signIn,
+	}
+
+	return *handlers.NewPasswordlessHandler(requestHandler, redeemHandler, sessionCookies)
+}
+
+// initializePasswordHandler cria um novo PasswordHandler com suas dependências necessárias.
+func initializePasswordHandler(cfg *config.Config, db *gorm.DB, hasher shared.PasswordHasher, passwordPolicy *passwordpolicy.Policy, notifier domainnotification.PasswordResetNotifier, jwtManager *shared.JWTManager, repo repository.UserRepository, tokenRepo repository.RefreshTokenRepository, revocationRepo repository.TokenRevocationRepository) handlers.PasswordHandler {
+	resetRepo := persistence.NewPasswordResetRepository(db)
+	history := &commands.PasswordHistory{
+		Repo: persistence.NewPasswordHistoryRepository(db),
+		Size: cfg.PasswordHistorySize,
+	}
+
+	changePasswordHandler := commands.ChangePasswordHandler{
+		Hasher:         hasher,
+		PasswordPolicy: passwordPolicy,
+		History:        history,
+		Repo:           repo,
+	}
+
+	requestResetHandler := commands.RequestPasswordResetHandler{
+		Repo:     repo,
+		Resets:   resetRepo,
+		Notifier: notifier,
+		TTL:      time.Duration(cfg.PasswordResetTTLMinutes) * time.Minute,
+	}
+
+	confirmResetHandler := commands.ConfirmPasswordResetHandler{
+		Hasher:         hasher,
+		PasswordPolicy: passwordPolicy,
+		History:        history,
+		JWT:            jwtManager,
+		Repo:           repo,
+		Resets:         resetRepo,
+		Tokens:         tokenRepo,
+		Revocations:    revocationRepo,
+	}
+
+	return *handlers.NewPasswordHandler(changePasswordHandler, requestResetHandler, confirmResetHandler)
+}
+
+// initializeTwoFactorHandler cria um novo TwoFactorHandler com suas dependências necessárias.
+func initializeTwoFactorHandler(cfg *config.Config, db *gorm.DB, hasher shared.PasswordHasher, sessionCookies middleware.SessionCookies, signIn *commands.CreateTokenHandler, repo repository.UserRepository) handlers.TwoFactorHandler {
+	recoveryCodeRepo := persistence.NewRecoveryCodeRepository(db)
+
+	enrollHandler := commands.EnrollTwoFactorHandler{
+		Issuer: cfg.TOTPIssuer,
+		Repo:   repo,
+	}
+
+	confirmHandler := commands.ConfirmTwoFactorHandler{
+		Hasher:        hasher,
+		RecoveryCodes: recoveryCodeRepo,
+		Repo:          repo,
+	}
+
+	completeHandler := commands.CompleteTwoFactorHandler{
+		Hasher:        hasher,
+		RecoveryCodes: recoveryCodeRepo,
+		Repo:          repo,
+		SignIn:        signIn,
 	}
 
-	return *handlers.NewAuthHandler(createUserHandler, createTokenHandler)
+	return *handlers.NewTwoFactorHandler(enrollHandler, confirmHandler, completeHandler, sessionCookies)
 }
 
-type Argon2Config struct {
-	Time    uint32
-	Memory  uint32
-	Threads uint8
-	KeyLen  uint32
+// initializeSessionHandler cria um novo SessionHandler com suas dependências necessárias.
+// A última atividade das sessões é gravada no máximo uma vez por minuto.
+func initializeSessionHandler(sessionRepo repository.SessionRepository, historyRepo repository.SignInHistoryRepository, tokenRepo repository.RefreshTokenRepository) handlers.SessionHandler {
+	listHandler := queries.ListSessionsQueryHandler{Sessions: sessionRepo}
+	revokeHandler := commands.RevokeSessionHandler{Sessions: sessionRepo, Tokens: tokenRepo}
+	historyHandler := queries.SignInHistoryQueryHandler{History: historyRepo}
+	trackHandler := commands.TrackSessionHandler{Sessions: sessionRepo, Interval: time.Minute}
+
+	return *handlers.NewSessionHandler(listHandler, revokeHandler, historyHandler, trackHandler)
+}
+
+// initializeAPIKeyHandler cria um novo APIKeyHandler com suas dependências necessárias.
+func initializeAPIKeyHandler(db *gorm.DB, repo repository.UserRepository) handlers.APIKeyHandler {
+	apiKeyRepo := persistence.NewAPIKeyRepository(db)
+
+	createHandler := commands.CreateAPIKeyHandler{Repo: repo, Keys: apiKeyRepo}
+	listHandler := queries.ListAPIKeysQueryHandler{Keys: apiKeyRepo}
+	revokeHandler := commands.RevokeAPIKeyHandler{Keys: apiKeyRepo}
+	authenticateHandler := commands.AuthenticateAPIKeyHandler{Repo: repo, Keys: apiKeyRepo}
+
+	return *handlers.NewAPIKeyHandler(createHandler, listHandler, revokeHandler, authenticateHandler)
+}
+
+// initializeOAuthHandler cria um novo OAuthHandler com suas dependências necessárias.
+func initializeOAuthHandler(db *gorm.DB, passwordMaxAge time.Duration, jwtManager *shared.JWTManager, repo repository.UserRepository, tokenRepo repository.RefreshTokenRepository, revocationRepo repository.TokenRevocationRepository) handlers.OAuthHandler {
+	clientRepo := persistence.NewOAuthClientRepository(db)
+	codeRepo := persistence.NewAuthorizationCodeRepository(db)
+
+	registerClientHandler := commands.RegisterOAuthClientHandler{Clients: clientRepo}
+	listClientsHandler := queries.ListOAuthClientsQueryHandler{Clients: clientRepo}
+	deleteClientHandler := commands.DeleteOAuthClientHandler{Clients: clientRepo}
+
+	authorizeHandler := commands.AuthorizeHandler{
+		Repo:     repo,
+		Clients:  clientRepo,
+		Codes:    codeRepo,
+		Consents: persistence.NewConsentRepository(db),
+		JWT:      jwtManager,
+	}
+
+	tokenHandler := commands.OAuthTokenHandler{
+		Repo:           repo,
+		Clients:        clientRepo,
+		Codes:          codeRepo,
+		Tokens:         tokenRepo,
+		JWT:            jwtManager,
+		PasswordMaxAge: passwordMaxAge,
+	}
+
+	userInfoHandler := queries.UserInfoQueryHandler{Repo: repo}
+	authenticateClientHandler := commands.AuthenticateOAuthClientHandler{Clients: clientRepo}
+
+	apiKeyRepo := persistence.NewAPIKeyRepository(db)
+	inspector := &introspection.Inspector{
+		JWT:           jwtManager,
+		Revocations:   revocationRepo,
+		RefreshTokens: tokenRepo,
+		APIKeys:       apiKeyRepo,
+		Users:         repo,
+	}
+	introspectHandler := queries.IntrospectTokenQueryHandler{Inspector: inspector}
+	revokeTokenHandler := commands.RevokeTokenHandler{
+		Inspector:   inspector,
+		Revocations: revocationRepo,
+		Tokens:      tokenRepo,
+		Keys:        apiKeyRepo,
+	}
+
+	return *handlers.NewOAuthHandler(registerClientHandler, listClientsHandler, deleteClientHandler, authorizeHandler, tokenHandler, userInfoHandler, authenticateClientHandler, introspectHandler, revokeTokenHandler)
 }
 
-// DefaultArgon2Config fornece uma configuração padrão para Argon2.
-func DefaultArgon2Config() Argon2Config {
-	return Argon2Config{
-		Time:    1,
-		Memory:  64 * 1024,
-		Threads: 4,
-		KeyLen:  32,
+// splitList separa uma lista de configuração delimitada por vírgulas, ignorando itens vazios.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
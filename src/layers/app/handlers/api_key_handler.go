@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
+	"server/src/layers/service/queries"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler struct {
+	Create       commands.CreateAPIKeyHandler
+	List         queries.ListAPIKeysQueryHandler
+	Revoke       commands.RevokeAPIKeyHandler
+	Authenticate commands.AuthenticateAPIKeyHandler
+}
+
+// NewAPIKeyHandler retorna uma nova instância de APIKeyHandler
+func NewAPIKeyHandler(create commands.CreateAPIKeyHandler, list queries.ListAPIKeysQueryHandler, revoke commands.RevokeAPIKeyHandler, authenticate commands.AuthenticateAPIKeyHandler) *APIKeyHandler {
+	return &APIKeyHandler{
+		Create:       create,
+		List:         list,
+		Revoke:       revoke,
+		Authenticate: authenticate,
+	}
+}
+
+// CreateMe cria uma chave de api para o usuário autenticado. A chave só é exibida nesta resposta
+func (h *APIKeyHandler) CreateMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input createAPIKeyInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	createCommand := commands.CreateAPIKeyCommand{
+		UserID:    principal.UserID,
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+
+	err := createCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	key, err := h.Create.Handle(createCommand)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(key)
+}
+
+// ListMe lista as chaves de api do usuário autenticado
+func (h *APIKeyHandler) ListMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	keys, err := h.List.Handle(queries.ListAPIKeysQuery{UserID: principal.UserID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
+
+// RevokeMe revoga uma chave de api do usuário autenticado
+func (h *APIKeyHandler) RevokeMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "ID inválido"})
+	}
+
+	revokeCommand := commands.RevokeAPIKeyCommand{
+		UserID: principal.UserID,
+		KeyID:  id,
+	}
+
+	err = revokeCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.Revoke.Handle(revokeCommand); err != nil {
+		if errors.Is(err, commands.ErrAPIKeyNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type createAPIKeyInput struct {
+	Name      string     `json:"Name"`
+	Scopes    []string   `json:"Scopes"`
+	ExpiresAt *time.Time `json:"ExpiresAt"`
+}
@@ -1,20 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"server/src/layers/app/middleware"
 	"server/src/layers/service/commands"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type AuthHandler struct {
-	CreateUser  commands.CreateUserHandler
-	CreateToken commands.CreateTokenHandler
+	CreateUser   commands.CreateUserHandler
+	CreateToken  commands.CreateTokenHandler
+	RefreshToken commands.RefreshTokenHandler
+	SignOutUser  commands.SignOutHandler
+	Cookies      middleware.SessionCookies
 }
 
-func NewAuthHandler(createUser commands.CreateUserHandler, createToken commands.CreateTokenHandler) *AuthHandler {
+func NewAuthHandler(createUser commands.CreateUserHandler, createToken commands.CreateTokenHandler, refreshToken commands.RefreshTokenHandler, signOut commands.SignOutHandler, cookies middleware.SessionCookies) *AuthHandler {
 	return &AuthHandler{
-		CreateUser:  createUser,
-		CreateToken: createToken,
+		CreateUser:   createUser,
+		CreateToken:  createToken,
+		RefreshToken: refreshToken,
+		SignOutUser:  signOut,
+		Cookies:      cookies,
 	}
 }
 
@@ -30,6 +38,7 @@ func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 		Password:  input.Password,
 		FirstName: input.FirstName,
 		LastName:  input.LastName,
+		Email:     input.Email,
 	}
 
 	err := newUserCommand.Validate()
@@ -39,7 +48,7 @@ func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 
 	user, err := h.CreateUser.Handle(newUserCommand)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		return commandError(c, err, fiber.StatusBadRequest)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(user)
@@ -53,8 +62,11 @@ func (h *AuthHandler) SignIn(c *fiber.Ctx) error {
 	}
 
 	newTokenCommand := commands.CreateTokenCommand{
-		CPF:      input.CPF,
-		Password: input.Password,
+		Identifier: input.Identifier,
+		CPF:        input.CPF,
+		Password:   input.Password,
+		IP:         c.IP(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
 	}
 
 	err := newTokenCommand.Validate()
@@ -64,10 +76,88 @@ func (h *AuthHandler) SignIn(c *fiber.Ctx) error {
 
 	token, err := h.CreateToken.Handle(newTokenCommand)
 	if err != nil {
+		var twoFactor *commands.TwoFactorRequiredError
+		if errors.As(err, &twoFactor) {
+			return c.Status(fiber.StatusAccepted).JSON(twoFactor.Challenge)
+		}
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return respondWithToken(c, h.Cookies, token, h.Cookies.Requested(c))
+}
+
+// Refresh troca o refresh token do corpo ou, na sessão em cookies, o do cookie, que exige o token csrf
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var input refreshTokenInput
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+	}
+
+	fromCookie := false
+	if input.RefreshToken == "" && h.Cookies.Enabled() {
+		if refreshToken := c.Cookies(middleware.RefreshTokenCookie); refreshToken != "" {
+			if err := middleware.VerifyCSRF(c); err != nil {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": err.Error()})
+			}
+			input.RefreshToken, fromCookie = refreshToken, true
+		}
+	}
+
+	refreshTokenCommand := commands.RefreshTokenCommand{
+		RefreshToken: input.RefreshToken,
+	}
+
+	err := refreshTokenCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	token, err := h.RefreshToken.Handle(refreshTokenCommand)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return respondWithToken(c, h.Cookies, token, fromCookie || h.Cookies.Requested(c))
+}
+
+// SignOut revoga o token usado na requisição e a família de refresh tokens da sessão
+func (h *AuthHandler) SignOut(c *fiber.Ctx) error {
+	return h.signOut(c, false)
+}
+
+// SignOutAll revoga todas as sessões do usuário autenticado
+func (h *AuthHandler) SignOutAll(c *fiber.Ctx) error {
+	return h.signOut(c, true)
+}
+
+func (h *AuthHandler) signOut(c *fiber.Ctx, allSessions bool) error {
+	token, _, err := middleware.SessionToken(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	signOutCommand := commands.SignOutCommand{
+		Token:       token,
+		AllSessions: allSessions,
+	}
+
+	err = signOutCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.SignOutUser.Handle(signOutCommand); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(token)
+	if h.Cookies.Enabled() {
+		h.Cookies.Clear(c)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
 }
 
 type createUserInput struct {
@@ -75,9 +165,15 @@ type createUserInput struct {
 	Password  string `json:"Password"`
 	FirstName string `json:"FirstName"`
 	LastName  string `json:"LastName"`
+	Email     string `json:"Email"`
 }
 
 type createTokenInput struct {
-	CPF      string `json:"Cpf"`
-	Password string `json:"Password"`
+	Identifier string `json:"Identifier"`
+	CPF        string `json:"Cpf"`
+	Password   string `json:"Password"`
+}
+
+type refreshTokenInput struct {
+	RefreshToken string `json:"RefreshToken"`
 }
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailHandler struct {
+	Change commands.ChangeEmailHandler
+	Resend commands.ResendEmailVerificationHandler
+	Verify commands.VerifyEmailHandler
+}
+
+// NewEmailHandler retorna uma nova instância de EmailHandler
+func NewEmailHandler(change commands.ChangeEmailHandler, resend commands.ResendEmailVerificationHandler, verify commands.VerifyEmailHandler) *EmailHandler {
+	return &EmailHandler{
+		Change: change,
+		Resend: resend,
+		Verify: verify,
+	}
+}
+
+// ChangeMe cadastra ou troca o e-mail do usuário autenticado e envia o link de confirmação
+func (h *EmailHandler) ChangeMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input changeEmailInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	changeEmailCommand := commands.ChangeEmailCommand{
+		UserID: principal.UserID,
+		Email:  input.Email,
+	}
+
+	err := changeEmailCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	user, err := h.Change.Handle(changeEmailCommand)
+	if err != nil {
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// ResendMe envia um novo link de confirmação para o e-mail do usuário autenticado
+func (h *EmailHandler) ResendMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	if err := h.Resend.Handle(commands.ResendEmailVerificationCommand{UserID: principal.UserID}); err != nil {
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// VerifyPage é a página aberta pelo link do e-mail. Ela não confirma nada: o e-mail só é confirmado quando o usuário
+// clica no botão e a página envia o token por POST em JSON, para que leitores de links dos provedores de e-mail e
+// requisições GET de outros sites não alterem a conta. O token vem no fragmento da URL, que não chega ao servidor.
+func (h *EmailHandler) VerifyPage(c *fiber.Ctx) error {
+	return sendConfirmationPage(c, emailVerifyPage)
+}
+
+// VerifyToken confirma o e-mail pelo token do link, recebido no corpo em JSON
+func (h *EmailHandler) VerifyToken(c *fiber.Ctx) error {
+	var input verifyEmailInput
+
+	if !c.Is("json") {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"message": "o corpo deve ser application/json"})
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	verifyEmailCommand := commands.VerifyEmailCommand{
+		Token: input.Token,
+	}
+
+	err := verifyEmailCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	user, err := h.Verify.Handle(verifyEmailCommand)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"Email": user.Email, "EmailVerifiedAt": user.EmailVerifiedAt})
+}
+
+type changeEmailInput struct {
+	Email string `json:"Email"`
+}
+
+type verifyEmailInput struct {
+	Token string `json:"Token"`
+}
+
+// emailVerifyPage recebe o nonce do script
+const emailVerifyPage = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Confirmar e-mail</title>
+</head>
+<body style="font-family: sans-serif; max-width: 28rem; margin: 4rem auto; text-align: center">
+<p id="status">Confirme para validar o seu e-mail.</p>
+<button id="confirm" type="button">Confirmar e-mail</button>
+<script nonce="%s">
+const token = new URLSearchParams(location.hash.slice(1)).get("token");
+const status = document.getElementById("status");
+const button = document.getElementById("confirm");
+history.replaceState(null, "", location.pathname);
+if (!token) {
+	status.textContent = "Link inválido. Peça um novo link de confirmação.";
+	button.hidden = true;
+}
+button.addEventListener("click", async () => {
+	button.disabled = true;
+	const response = await fetch("/email/verify", {
+		method: "POST",
+		credentials: "same-origin",
+		headers: {"Content-Type": "application/json"},
+		body: JSON.stringify({Token: token}),
+	});
+	button.hidden = true;
+	if (response.status === 200) {
+		status.textContent = "E-mail confirmado. Você já pode voltar ao aplicativo.";
+	} else {
+		status.textContent = "Link inválido ou expirado. Peça um novo link de confirmação.";
+	}
+});
+</script>
+</body>
+</html>
+`
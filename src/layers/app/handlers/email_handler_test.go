@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/service/commands"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailHandler_Verify(t *testing.T) {
+	repo := repository.NewMockUserRepository()
+	manager := shared.NewJWTManager("segredo-de-teste", time.Hour, 24*time.Hour)
+	user, _ := repo.Store(&models.User{CPF: "52998224725", Email: "ana@example.com"})
+	token, _, _ := manager.GenerateEmailVerification(user.ID, user.Email, time.Hour)
+
+	handler := NewEmailHandler(commands.ChangeEmailHandler{}, commands.ResendEmailVerificationHandler{}, commands.VerifyEmailHandler{Repo: repo, JWT: manager})
+	app := fiber.New()
+	app.Get("/email/verify", handler.VerifyPage)
+	app.Post("/email/verify", handler.VerifyToken)
+
+	verified := func() bool {
+		found, _ := repo.FindByID(user.ID)
+		return found.EmailVerified()
+	}
+
+	t.Run("GET only serves the confirmation page", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/email/verify?token="+token, nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMETextHTML) {
+			t.Fatalf("Expected the HTML page, got %v %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+		}
+		if !strings.Contains(resp.Header.Get(fiber.HeaderContentSecurityPolicy), "script-src 'nonce-") || resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+			t.Errorf("Expected a nonce CSP and no-store, got %q and %q", resp.Header.Get(fiber.HeaderContentSecurityPolicy), resp.Header.Get(fiber.HeaderCacheControl))
+		}
+		if verified() {
+			t.Error("Expected GET not to verify the email")
+		}
+	})
+
+	t.Run("POST without JSON is rejected", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/email/verify", strings.NewReader("Token="+token))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusUnsupportedMediaType {
+			t.Errorf("Expected status %v, got %v", fiber.StatusUnsupportedMediaType, resp.StatusCode)
+		}
+		if verified() {
+			t.Error("Expected a form POST not to verify the email")
+		}
+	})
+
+	t.Run("POST with JSON verifies the email", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/email/verify", strings.NewReader(`{"Token":"`+token+`"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("Expected status %v, got %v", fiber.StatusOK, resp.StatusCode)
+		}
+		if !verified() {
+			t.Error("Expected the email to be verified")
+		}
+	})
+}
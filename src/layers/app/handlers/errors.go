@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"server/src/commons/shared"
+	"server/src/layers/domain/authn"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/passwordpolicy"
+	"server/src/layers/service/commands"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorStatus converte erros de autorização em 403 e mantém o status informado para os demais
+func errorStatus(err error, defaultStatus int) int {
+	if errors.Is(err, models.ErrAccessDenied) {
+		return fiber.StatusForbidden
+	}
+	return defaultStatus
+}
+
+// commandError responde 429 para tentativas bloqueadas e 503 para o serviço de senhas sobrecarregado,
+// ambos com Retry-After, 503 para o provedor de identidade indisponível, 409 para a conta do diretório cujo cpf já
+// pertence a outra conta, 400 com os motivos por campo para senhas recusadas pela política,
+// 403 para acesso negado e o status informado para os demais erros
+func commandError(c *fiber.Ctx, err error, defaultStatus int) error {
+	var rejected *passwordpolicy.ValidationError
+	if errors.As(err, &rejected) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "errors": rejected.Errors})
+	}
+
+	var throttled *commands.ThrottledError
+	if errors.As(err, &throttled) {
+		c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(throttled.RetryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	var busy *shared.HasherBusyError
+	if errors.As(err, &busy) {
+		c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(busy.RetryAfter))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if errors.Is(err, authn.ErrUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if errors.Is(err, authn.ErrAccountConflict) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(errorStatus(err, defaultStatus)).JSON(fiber.Map{"message": err.Error()})
+}
+
+// retryAfterSeconds formata a duração para o header Retry-After, arredondando para cima em segundos
+func retryAfterSeconds(d time.Duration) string {
+	seconds := int64(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"server/src/commons/shared"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MetricsHandler struct {
+	Hasher *shared.Argon2Manager
+}
+
+// NewMetricsHandler retorna uma nova instância de MetricsHandler
+func NewMetricsHandler(hasher *shared.Argon2Manager) *MetricsHandler {
+	return &MetricsHandler{
+		Hasher: hasher,
+	}
+}
+
+// PasswordHasher publica a fila, a concorrência e a latência dos cálculos de hash de senha
+func (h *MetricsHandler) PasswordHasher(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(h.Hasher.Metrics())
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"errors"
+	"server/src/layers/app/middleware"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/service/commands"
+	"server/src/layers/service/queries"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OAuthHandler struct {
+	RegisterClient     commands.RegisterOAuthClientHandler
+	ListClients        queries.ListOAuthClientsQueryHandler
+	DeleteClient       commands.DeleteOAuthClientHandler
+	Authorize          commands.AuthorizeHandler
+	Token              commands.OAuthTokenHandler
+	UserInfo           queries.UserInfoQueryHandler
+	AuthenticateClient commands.AuthenticateOAuthClientHandler
+	Introspect         queries.IntrospectTokenQueryHandler
+	RevokeToken        commands.RevokeTokenHandler
+}
+
+// NewOAuthHandler retorna uma nova instância de OAuthHandler
+func NewOAuthHandler(registerClient commands.RegisterOAuthClientHandler, listClients queries.ListOAuthClientsQueryHandler, deleteClient commands.DeleteOAuthClientHandler, authorize commands.AuthorizeHandler, token commands.OAuthTokenHandler, userInfo queries.UserInfoQueryHandler, authenticateClient commands.AuthenticateOAuthClientHandler, introspect queries.IntrospectTokenQueryHandler, revokeToken commands.RevokeTokenHandler) *OAuthHandler {
+	return &OAuthHandler{
+		RegisterClient:     registerClient,
+		ListClients:        listClients,
+		DeleteClient:       deleteClient,
+		Authorize:          authorize,
+		Token:              token,
+		UserInfo:           userInfo,
+		AuthenticateClient: authenticateClient,
+		Introspect:         introspect,
+		RevokeToken:        revokeToken,
+	}
+}
+
+// CreateClient cadastra um cliente oauth. O segredo só é exibido nesta resposta
+func (h *OAuthHandler) CreateClient(c *fiber.Ctx) error {
+	var input registerOAuthClientInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	registerCommand := commands.RegisterOAuthClientCommand{
+		Name:         input.Name,
+		RedirectURIs: input.RedirectURIs,
+		Scopes:       input.Scopes,
+		GrantTypes:   input.GrantTypes,
+		Public:       input.Public,
+	}
+
+	err := registerCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	client, err := h.RegisterClient.Handle(registerCommand)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(client)
+}
+
+// GetClients lista os clientes oauth cadastrados
+func (h *OAuthHandler) GetClients(c *fiber.Ctx) error {
+	clients, err := h.ListClients.Handle(queries.ListOAuthClientsQuery{})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(clients)
+}
+
+// RemoveClient remove um cliente oauth
+func (h *OAuthHandler) RemoveClient(c *fiber.Ctx) error {
+	deleteCommand := commands.DeleteOAuthClientCommand{
+		ClientID: c.Params("clientId"),
+	}
+
+	err := deleteCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.DeleteClient.Handle(deleteCommand); err != nil {
+		if errors.Is(err, commands.ErrOAuthClientNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AuthorizeRequest inicia o fluxo authorization code. Com consentimento prévio, redireciona ao cliente com o código;
+// caso contrário, devolve o pedido de consentimento a ser apresentado ao usuário
+func (h *OAuthHandler) AuthorizeRequest(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input authorizeInput
+
+	if err := c.QueryParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(oauth.NewError(oauth.ErrInvalidRequest, err.Error()))
+	}
+
+	authorizeCommand, err := input.command(principal, nil)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(err)
+	}
+
+	result, err := h.handleAuthorize(authorizeCommand)
+	if err != nil {
+		return c.Status(oauthErrorStatus(err, false)).JSON(err)
+	}
+	if result.ConsentRequired != nil {
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+
+	return c.Redirect(result.RedirectURL, fiber.StatusFound)
+}
+
+// AuthorizeDecision registra a decisão do usuário na tela de consentimento e devolve a URI de retorno do cliente,
+// com o código ou o erro access_denied
+func (h *OAuthHandler) AuthorizeDecision(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input authorizeInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(oauth.NewError(oauth.ErrInvalidRequest, err.Error()))
+	}
+
+	authorizeCommand, err := input.command(principal, &input.Approve)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(err)
+	}
+
+	result, err := h.handleAuthorize(authorizeCommand)
+	if err != nil {
+		return c.Status(oauthErrorStatus(err, false)).JSON(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+func (h *OAuthHandler) handleAuthorize(authorizeCommand commands.AuthorizeCommand) (*commands.AuthorizeResult, error) {
+	if err := authorizeCommand.Validate(); err != nil {
+		return nil, err
+	}
+	return h.Authorize.Handle(authorizeCommand)
+}
+
+// GetUserInfo é o endpoint /userinfo do OpenID Connect. Exige um token de acesso com o escopo openid
+// e responde as claims liberadas pelos escopos profile e cpf
+func (h *OAuthHandler) GetUserInfo(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	info, err := h.UserInfo.Handle(queries.UserInfoQuery{Requester: principal})
+	if err != nil {
+		var oauthErr *oauth.Error
+		if !errors.As(err, &oauthErr) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+		}
+
+		status := fiber.StatusUnauthorized
+		if oauthErr.Code == oauth.ErrInsufficientScope {
+			status = fiber.StatusForbidden
+		}
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="`+oauthErr.Code+`"`)
+		return c.Status(status).JSON(oauthErr)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(info)
+}
+
+// IssueToken é o endpoint de tokens do OAuth 2.0. Aceita o corpo como formulário ou JSON e
+// as credenciais do cliente no header Authorization (Basic) ou no corpo
+func (h *OAuthHandler) IssueToken(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	var input oauthTokenInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(oauth.NewError(oauth.ErrInvalidRequest, err.Error()))
+	}
+
+	clientID, clientSecret, basic := middleware.BasicClientCredentials(c)
+	if !basic {
+		clientID, clientSecret = input.ClientID, input.ClientSecret
+	}
+
+	tokenCommand := commands.OAuthTokenCommand{
+		GrantType:    input.GrantType,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         input.Code,
+		RedirectURI:  input.RedirectURI,
+		CodeVerifier: input.CodeVerifier,
+		RefreshToken: input.RefreshToken,
+		Scope:        input.Scope,
+	}
+
+	err := tokenCommand.Validate()
+	if err == nil {
+		var token *commands.OAuthTokenResponse
+		if token, err = h.Token.Handle(tokenCommand); err == nil {
+			return c.Status(fiber.StatusOK).JSON(token)
+		}
+	}
+
+	status := oauthErrorStatus(err, true)
+	if status == fiber.StatusUnauthorized && basic {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	return c.Status(status).JSON(err)
+}
+
+// IntrospectToken é o endpoint de introspecção da RFC 7662. Exige a permissão tokens:introspect,
+// concedida por escopo ao cliente oauth ou pelas permissões da chave de api
+func (h *OAuthHandler) IntrospectToken(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input tokenInput
+
+	if err := c.BodyParser(&input); err != nil || input.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(oauth.NewError(oauth.ErrInvalidRequest, "token é necessário"))
+	}
+
+	result, err := h.Introspect.Handle(queries.IntrospectTokenQuery{
+		Token:         input.Token,
+		TokenTypeHint: input.TokenTypeHint,
+		Requester:     principal,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// RevokeTokenRequest é o endpoint de revogação da RFC 7009. Responde 200 também para tokens desconhecidos
+// ou já revogados, para não revelar quais tokens existem
+func (h *OAuthHandler) RevokeTokenRequest(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input tokenInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(oauth.NewError(oauth.ErrInvalidRequest, err.Error()))
+	}
+
+	revokeCommand := commands.RevokeTokenCommand{
+		Token:         input.Token,
+		TokenTypeHint: input.TokenTypeHint,
+		Requester:     principal,
+	}
+
+	err := revokeCommand.Validate()
+	if err == nil {
+		if err = h.RevokeToken.Handle(revokeCommand); err == nil {
+			return c.SendStatus(fiber.StatusOK)
+		}
+	}
+
+	return c.Status(oauthErrorStatus(err, false)).JSON(err)
+}
+
+// oauthErrorStatus segue a RFC 6749: 401 para falhas de autenticação do cliente no endpoint de tokens,
+// 500 para erros internos e 400 para os demais
+func oauthErrorStatus(err error, tokenEndpoint bool) int {
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		return fiber.StatusInternalServerError
+	}
+
+	switch {
+	case oauthErr.Code == oauth.ErrServerError:
+		return fiber.StatusInternalServerError
+	case oauthErr.Code == oauth.ErrInvalidClient && tokenEndpoint:
+		return fiber.StatusUnauthorized
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+type registerOAuthClientInput struct {
+	Name         string   `json:"Name"`
+	RedirectURIs []string `json:"RedirectURIs"`
+	Scopes       []string `json:"Scopes"`
+	GrantTypes   []string `json:"GrantTypes"`
+	Public       bool     `json:"Public"`
+}
+
+type authorizeInput struct {
+	ResponseType        string `json:"response_type" query:"response_type" form:"response_type"`
+	ClientID            string `json:"client_id" query:"client_id" form:"client_id"`
+	RedirectURI         string `json:"redirect_uri" query:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" query:"scope" form:"scope"`
+	State               string `json:"state" query:"state" form:"state"`
+	CodeChallenge       string `json:"code_challenge" query:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method" form:"code_challenge_method"`
+	Nonce               string `json:"nonce" query:"nonce" form:"nonce"`
+	Prompt              string `json:"prompt" query:"prompt" form:"prompt"`
+	MaxAge              string `json:"max_age" query:"max_age" form:"max_age"`
+	Approve             bool   `json:"approve" form:"approve"`
+}
+
+func (input authorizeInput) command(principal *models.Principal, approve *bool) (commands.AuthorizeCommand, error) {
+	var maxAge *int
+	if input.MaxAge != "" {
+		seconds, err := strconv.Atoi(input.MaxAge)
+		if err != nil || seconds < 0 {
+			return commands.AuthorizeCommand{}, oauth.NewError(oauth.ErrInvalidRequest, "max_age inválido")
+		}
+		maxAge = &seconds
+	}
+
+	return commands.AuthorizeCommand{
+		UserID:              principal.UserID,
+		AuthTime:            principal.AuthTime,
+		ResponseType:        input.ResponseType,
+		ClientID:            input.ClientID,
+		RedirectURI:         input.RedirectURI,
+		Scope:               input.Scope,
+		State:               input.State,
+		CodeChallenge:       input.CodeChallenge,
+		CodeChallengeMethod: input.CodeChallengeMethod,
+		Nonce:               input.Nonce,
+		Prompt:              input.Prompt,
+		MaxAge:              maxAge,
+		Approve:             approve,
+	}, nil
+}
+
+type oauthTokenInput struct {
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	Code         string `json:"code" form:"code"`
+	RedirectURI  string `json:"redirect_uri" form:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier" form:"code_verifier"`
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+	Scope        string `json:"scope" form:"scope"`
+}
+
+type tokenInput struct {
+	Token         string `json:"token" form:"token"`
+	TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"fmt"
+	"server/src/commons/shared"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendConfirmationPage responde uma página de confirmação aberta por um link de e-mail. O primeiro argumento do
+// formato é o nonce do script, o único que a política de conteúdo permite executar; a página só pode falar com a
+// própria origem e não fica em cache nem é repassada no Referer, já que o token está na URL.
+func sendConfirmationPage(c *fiber.Ctx, page string, args ...interface{}) error {
+	nonce, err := shared.GenerateRandomToken(16)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "erro ao gerar a página"})
+	}
+
+	c.Set(fiber.HeaderContentSecurityPolicy, fmt.Sprintf("default-src 'none'; script-src 'nonce-%s'; style-src 'unsafe-inline'; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'", nonce))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderReferrerPolicy, "no-referrer")
+	c.Type("html", "utf-8")
+	return c.SendString(fmt.Sprintf(page, append([]interface{}{nonce}, args...)...))
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordHandler struct {
+	ChangePassword commands.ChangePasswordHandler
+	RequestReset   commands.RequestPasswordResetHandler
+	ConfirmReset   commands.ConfirmPasswordResetHandler
+}
+
+// NewPasswordHandler retorna uma nova instância de PasswordHandler
+func NewPasswordHandler(changePassword commands.ChangePasswordHandler, requestReset commands.RequestPasswordResetHandler, confirmReset commands.ConfirmPasswordResetHandler) *PasswordHandler {
+	return &PasswordHandler{
+		ChangePassword: changePassword,
+		RequestReset:   requestReset,
+		ConfirmReset:   confirmReset,
+	}
+}
+
+// Change troca a senha do usuário autenticado, exigindo a senha atual
+func (h *PasswordHandler) Change(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input changePasswordInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	changePasswordCommand := commands.ChangePasswordCommand{
+		UserID:          principal.UserID,
+		CurrentPassword: input.CurrentPassword,
+		NewPassword:     input.NewPassword,
+	}
+
+	err := changePasswordCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.ChangePassword.Handle(changePasswordCommand); err != nil {
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RequestResetToken solicita um token de redefinição de senha. A resposta é a mesma para cpfs cadastrados ou não
+func (h *PasswordHandler) RequestResetToken(c *fiber.Ctx) error {
+	var input requestPasswordResetInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	requestResetCommand := commands.RequestPasswordResetCommand{
+		CPF: input.CPF,
+	}
+
+	err := requestResetCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.RequestReset.Handle(requestResetCommand); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ConfirmResetToken define a nova senha a partir de um token de redefinição
+func (h *PasswordHandler) ConfirmResetToken(c *fiber.Ctx) error {
+	var input confirmPasswordResetInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	confirmResetCommand := commands.ConfirmPasswordResetCommand{
+		Token:       input.Token,
+		NewPassword: input.NewPassword,
+	}
+
+	err := confirmResetCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.ConfirmReset.Handle(confirmResetCommand); err != nil {
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type changePasswordInput struct {
+	CurrentPassword string `json:"CurrentPassword"`
+	NewPassword     string `json:"NewPassword"`
+}
+
+type requestPasswordResetInput struct {
+	CPF string `json:"Cpf"`
+}
+
+type confirmPasswordResetInput struct {
+	Token       string `json:"Token"`
+	NewPassword string `json:"NewPassword"`
+}
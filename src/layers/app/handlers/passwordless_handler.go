@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordlessHandler struct {
+	Request commands.RequestLoginCodeHandler
+	Redeem  commands.RedeemLoginCodeHandler
+	Cookies middleware.SessionCookies
+}
+
+// NewPasswordlessHandler retorna uma nova instância de PasswordlessHandler
+func NewPasswordlessHandler(request commands.RequestLoginCodeHandler, redeem commands.RedeemLoginCodeHandler, cookies middleware.SessionCookies) *PasswordlessHandler {
+	return &PasswordlessHandler{
+		Request: request,
+		Redeem:  redeem,
+		Cookies: cookies,
+	}
+}
+
+// RequestCode envia o link e o código de acesso ao e-mail do usuário. A resposta é a mesma para qualquer identificador
+func (h *PasswordlessHandler) RequestCode(c *fiber.Ctx) error {
+	var input requestLoginCodeInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	requestCommand := commands.RequestLoginCodeCommand{
+		Identifier: input.Identifier,
+	}
+
+	err := requestCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.Request.Handle(requestCommand); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// LinkPage é a página aberta pelo link do e-mail. Ela não consome o token: o login só é concluído quando o usuário
+// confirma e a página envia o token por POST em JSON. Assim, leitores de links dos provedores de e-mail não gastam o
+// token, e outro site não consegue iniciar o login no navegador da vítima. O token vem no fragmento da URL, que o
+// navegador não envia ao servidor nem repassa no Referer.
+func (h *PasswordlessHandler) LinkPage(c *fiber.Ctx) error {
+	return sendConfirmationPage(c, passwordlessLinkPage, middleware.AuthModeHeader, middleware.AuthModeCookie)
+}
+
+// SignIn conclui o login pelo token do link ou pelo identificador e código. O corpo precisa ser JSON: formulários
+// de outros sites não conseguem enviá-lo sem o preflight do CORS.
+func (h *PasswordlessHandler) SignIn(c *fiber.Ctx) error {
+	var input redeemLoginCodeInput
+
+	if !c.Is("json") {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"message": "o corpo deve ser application/json"})
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	redeemCommand := commands.RedeemLoginCodeCommand{
+		Token:      input.Token,
+		Identifier: input.Identifier,
+		Code:       input.Code,
+		IP:         c.IP(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+	}
+
+	err := redeemCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	token, err := h.Redeem.Handle(redeemCommand)
+	if err != nil {
+		var twoFactor *commands.TwoFactorRequiredError
+		if errors.As(err, &twoFactor) {
+			return c.Status(fiber.StatusAccepted).JSON(twoFactor.Challenge)
+		}
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return respondWithToken(c, h.Cookies, token, h.Cookies.Requested(c))
+}
+
+type requestLoginCodeInput struct {
+	Identifier string `json:"Identifier"`
+}
+
+type redeemLoginCodeInput struct {
+	Token      string `json:"Token"`
+	Identifier string `json:"Identifier"`
+	Code       string `json:"Code"`
+}
+
+// passwordlessLinkPage recebe o nonce do script e o header que pede a sessão em cookies
+const passwordlessLinkPage = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Entrar</title>
+</head>
+<body style="font-family: sans-serif; max-width: 28rem; margin: 4rem auto; text-align: center">
+<p id="status">Confirme para entrar na sua conta.</p>
+<button id="confirm" type="button">Entrar</button>
+<script nonce="%s">
+const token = new URLSearchParams(location.hash.slice(1)).get("token");
+const status = document.getElementById("status");
+const button = document.getElementById("confirm");
+history.replaceState(null, "", location.pathname);
+if (!token) {
+	status.textContent = "Link inválido. Peça um novo código de acesso.";
+	button.hidden = true;
+}
+button.addEventListener("click", async () => {
+	button.disabled = true;
+	const response = await fetch("/sign-in/passwordless/verify", {
+		method: "POST",
+		credentials: "same-origin",
+		headers: {"Content-Type": "application/json", "%s": "%s"},
+		body: JSON.stringify({Token: token}),
+	});
+	button.hidden = true;
+	if (response.status === 200) {
+		status.textContent = "Login concluído. Você já pode voltar ao aplicativo.";
+	} else if (response.status === 202) {
+		status.textContent = "Sua conta exige o segundo fator. Conclua o login no aplicativo.";
+	} else {
+		status.textContent = "Link inválido ou expirado. Peça um novo código de acesso.";
+	}
+});
+</script>
+</body>
+</html>
+`
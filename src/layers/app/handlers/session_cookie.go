@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// respondWithToken responde o TokenResponse no corpo ou, na sessão em cookies, grava os tokens em cookies
+// HttpOnly e responde apenas o usuário e o token csrf
+func respondWithToken(c *fiber.Ctx, cookies middleware.SessionCookies, token *commands.TokenResponse, useCookies bool) error {
+	if !useCookies {
+		return c.Status(fiber.StatusOK).JSON(token)
+	}
+
+	csrfToken, err := cookies.Set(c, token.Key.Token, token.Key.RefreshToken)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "erro ao criar a sessão"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sessionResponse{
+		User:                   token.User,
+		CSRFToken:              csrfToken,
+		PasswordChangeRequired: token.PasswordChangeRequired,
+	})
+}
+
+type sessionResponse struct {
+	User                   commands.SimplifiedUser `json:"User"`
+	CSRFToken              string                  `json:"CsrfToken"`
+	PasswordChangeRequired bool                    `json:"PasswordChangeRequired,omitempty"`
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/app/middleware"
+	"server/src/layers/domain/models"
+	"server/src/layers/service/commands"
+	"server/src/layers/service/queries"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	List    queries.ListSessionsQueryHandler
+	Revoke  commands.RevokeSessionHandler
+	History queries.SignInHistoryQueryHandler
+	Track   commands.TrackSessionHandler
+}
+
+// NewSessionHandler retorna uma nova instância de SessionHandler
+func NewSessionHandler(list queries.ListSessionsQueryHandler, revoke commands.RevokeSessionHandler, history queries.SignInHistoryQueryHandler, track commands.TrackSessionHandler) *SessionHandler {
+	return &SessionHandler{
+		List:    list,
+		Revoke:  revoke,
+		History: history,
+		Track:   track,
+	}
+}
+
+// ListMe lista as sessões ativas do usuário autenticado, indicando a sessão atual
+func (h *SessionHandler) ListMe(c *fiber.Ctx) error {
+	return h.listSessions(c, true)
+}
+
+// ListUser lista as sessões ativas do usuário informado na rota
+func (h *SessionHandler) ListUser(c *fiber.Ctx) error {
+	return h.listSessions(c, false)
+}
+
+// RevokeMe encerra uma sessão do usuário autenticado
+func (h *SessionHandler) RevokeMe(c *fiber.Ctx) error {
+	return h.revokeSession(c, true)
+}
+
+// RevokeUser encerra uma sessão do usuário informado na rota
+func (h *SessionHandler) RevokeUser(c *fiber.Ctx) error {
+	return h.revokeSession(c, false)
+}
+
+// HistoryMe retorna o histórico de logins do usuário autenticado, paginado por limit e offset
+func (h *SessionHandler) HistoryMe(c *fiber.Ctx) error {
+	return h.signInHistory(c, true)
+}
+
+// HistoryUser retorna o histórico de logins do usuário informado na rota, paginado por limit e offset
+func (h *SessionHandler) HistoryUser(c *fiber.Ctx) error {
+	return h.signInHistory(c, false)
+}
+
+func (h *SessionHandler) listSessions(c *fiber.Ctx, self bool) error {
+	principal, userID, err := sessionOwner(c, self)
+	if err != nil {
+		return sessionOwnerError(c, err)
+	}
+
+	sessions, err := h.List.Handle(queries.ListSessionsQuery{UserID: userID, Requester: principal})
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sessions)
+}
+
+func (h *SessionHandler) revokeSession(c *fiber.Ctx, self bool) error {
+	principal, userID, err := sessionOwner(c, self)
+	if err != nil {
+		return sessionOwnerError(c, err)
+	}
+
+	sessionID, err := uuid.Parse(c.Params("sessionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "ID inválido"})
+	}
+
+	revokeCommand := commands.RevokeSessionCommand{
+		UserID:    userID,
+		SessionID: sessionID,
+		Requester: principal,
+	}
+
+	err = revokeCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.Revoke.Handle(revokeCommand); err != nil {
+		if errors.Is(err, commands.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *SessionHandler) signInHistory(c *fiber.Ctx, self bool) error {
+	principal, userID, err := sessionOwner(c, self)
+	if err != nil {
+		return sessionOwnerError(c, err)
+	}
+
+	// Valores ausentes ou inválidos assumem os padrões da consulta
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	page, err := h.History.Handle(queries.SignInHistoryQuery{
+		UserID:    userID,
+		Limit:     limit,
+		Offset:    offset,
+		Requester: principal,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+var errInvalidUserID = errors.New("ID inválido")
+
+// sessionOwner identifica o usuário dono das sessões: o autenticado nas rotas /me ou o informado em :id
+func sessionOwner(c *fiber.Ctx, self bool) (*models.Principal, uuid.UUID, error) {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return nil, uuid.Nil, middleware.ErrMissingToken
+	}
+	if self {
+		return principal, principal.UserID, nil
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, uuid.Nil, errInvalidUserID
+	}
+	return principal, userID, nil
+}
+
+func sessionOwnerError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errInvalidUserID) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	Enroll   commands.EnrollTwoFactorHandler
+	Confirm  commands.ConfirmTwoFactorHandler
+	Complete commands.CompleteTwoFactorHandler
+	Cookies  middleware.SessionCookies
+}
+
+// NewTwoFactorHandler retorna uma nova instância de TwoFactorHandler
+func NewTwoFactorHandler(enroll commands.EnrollTwoFactorHandler, confirm commands.ConfirmTwoFactorHandler, complete commands.CompleteTwoFactorHandler, cookies middleware.SessionCookies) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		Enroll:   enroll,
+		Confirm:  confirm,
+		Complete: complete,
+		Cookies:  cookies,
+	}
+}
+
+// EnrollMe inicia o cadastro do segundo fator do usuário autenticado
+func (h *TwoFactorHandler) EnrollMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	enrollCommand := commands.EnrollTwoFactorCommand{
+		UserID: principal.UserID,
+	}
+
+	err := enrollCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	enrollment, err := h.Enroll.Handle(enrollCommand)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(enrollment)
+}
+
+// ConfirmMe ativa o segundo fator do usuário autenticado e devolve os códigos de recuperação
+func (h *TwoFactorHandler) ConfirmMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input confirmTwoFactorInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	confirmCommand := commands.ConfirmTwoFactorCommand{
+		UserID: principal.UserID,
+		Code:   input.Code,
+	}
+
+	err := confirmCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	recoveryCodes, err := h.Confirm.Handle(confirmCommand)
+	if err != nil {
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(recoveryCodes)
+}
+
+// SignIn conclui o login iniciado em /sign-in com o código TOTP ou um código de recuperação
+func (h *TwoFactorHandler) SignIn(c *fiber.Ctx) error {
+	var input completeTwoFactorInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	completeCommand := commands.CompleteTwoFactorCommand{
+		ChallengeToken: input.ChallengeToken,
+		Code:           input.Code,
+		RecoveryCode:   input.RecoveryCode,
+		IP:             c.IP(),
+		UserAgent:      c.Get(fiber.HeaderUserAgent),
+	}
+
+	err := completeCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	token, err := h.Complete.Handle(completeCommand)
+	if err != nil {
+		return commandError(c, err, fiber.StatusBadRequest)
+	}
+
+	return respondWithToken(c, h.Cookies, token, h.Cookies.Requested(c))
+}
+
+type confirmTwoFactorInput struct {
+	Code string `json:"Code"`
+}
+
+type completeTwoFactorInput struct {
+	ChallengeToken string `json:"ChallengeToken"`
+	Code           string `json:"Code"`
+	RecoveryCode   string `json:"RecoveryCode"`
+}
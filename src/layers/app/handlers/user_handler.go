@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"github.com/google/uuid"
+	"server/src/layers/app/middleware"
+	"server/src/layers/service/commands"
 	"server/src/layers/service/queries"
 	"strconv"
 
@@ -9,13 +11,19 @@ import (
 )
 
 type UserHandler struct {
-	GetUser queries.GetUserQueryHandler
+	GetUser     queries.GetUserQueryHandler
+	UpdateUser  commands.UpdateUserHandler
+	AssignRoles commands.AssignRolesHandler
+	Unlock      commands.UnlockUserHandler
 }
 
 // NewUserHandler retorna uma nova instância de UserHandler
-func NewUserHandler(getUser queries.GetUserQueryHandler) *UserHandler {
+func NewUserHandler(getUser queries.GetUserQueryHandler, updateUser commands.UpdateUserHandler, assignRoles commands.AssignRolesHandler, unlock commands.UnlockUserHandler) *UserHandler {
 	return &UserHandler{
-		GetUser: getUser,
+		GetUser:     getUser,
+		UpdateUser:  updateUser,
+		AssignRoles: assignRoles,
+		Unlock:      unlock,
 	}
 }
 
@@ -28,14 +36,17 @@ func (h *UserHandler) Get(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "ID inválido"})
 	}
 
+	principal, _ := middleware.CurrentPrincipal(c)
+
 	query := queries.GetUserByIDQuery{
-		UserID: id,
+		UserID:    id,
+		Requester: principal,
 	}
 
 	user, err := h.GetUser.GetUserByIDHandle(query)
 
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"message": err.Error()})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(user)
@@ -59,15 +70,140 @@ func (h *UserHandler) GetAll(c *fiber.Ctx) error {
 		offset = defaultOffset
 	}
 
+	principal, _ := middleware.CurrentPrincipal(c)
+
 	query := queries.GetAllUsersQuery{
-		Limit:  limit,
-		Offset: offset,
+		Limit:     limit,
+		Offset:    offset,
+		Requester: principal,
 	}
 
 	users, err := h.GetUser.GetAllUsersHandle(query)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"message": err.Error()})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(users)
 }
+
+// Me recupera as informações do usuário autenticado
+func (h *UserHandler) Me(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	query := queries.GetUserByIDQuery{
+		UserID:    principal.UserID,
+		Requester: principal,
+	}
+
+	user, err := h.GetUser.GetUserByIDHandle(query)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// UpdateMe altera os dados de perfil do usuário autenticado
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "autenticação requerida"})
+	}
+
+	var input updateUserInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	updateUserCommand := commands.UpdateUserCommand{
+		UserID:    principal.UserID,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Requester: principal,
+	}
+
+	err := updateUserCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	user, err := h.UpdateUser.Handle(updateUserCommand)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// SetRoles substitui os papéis do usuário informado na URL
+func (h *UserHandler) SetRoles(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "ID inválido"})
+	}
+
+	var input assignRolesInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+
+	assignRolesCommand := commands.AssignRolesCommand{
+		UserID:    id,
+		Roles:     input.Roles,
+		Requester: principal,
+	}
+
+	err = assignRolesCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	user, err := h.AssignRoles.Handle(assignRolesCommand)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// UnlockUser libera a conta informada na URL bloqueada por excesso de tentativas de login
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "ID inválido"})
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+
+	unlockUserCommand := commands.UnlockUserCommand{
+		UserID:    id,
+		Requester: principal,
+	}
+
+	err = unlockUserCommand.Validate()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.Unlock.Handle(unlockUserCommand); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusNotFound)).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type assignRolesInput struct {
+	Roles []string `json:"Roles"`
+}
+
+type updateUserInput struct {
+	FirstName *string `json:"FirstName"`
+	LastName  *string `json:"LastName"`
+}
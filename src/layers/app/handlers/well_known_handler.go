@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WellKnownHandler struct {
+	JWT *shared.JWTManager
+}
+
+// NewWellKnownHandler retorna uma nova instância de WellKnownHandler
+func NewWellKnownHandler(jwt *shared.JWTManager) *WellKnownHandler {
+	return &WellKnownHandler{
+		JWT: jwt,
+	}
+}
+
+// JWKS publica as chaves públicas usadas na assinatura dos tokens
+func (h *WellKnownHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.JWT.JWKS())
+}
+
+// OpenIDConfiguration publica os metadados do provedor OpenID Connect. O emissor é o JWT_ISSUER, que deve ser
+// a URL pública do servidor para que os clientes validem o "iss" dos ID tokens; os endpoints são derivados dele.
+// Com assinatura simétrica o provedor não é anunciado, pois o JWKS não teria a chave dos ID tokens
+func (h *WellKnownHandler) OpenIDConfiguration(c *fiber.Ctx) error {
+	if !h.JWT.SupportsOpenID() {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": shared.ErrOpenIDDisabled.Error()})
+	}
+
+	issuer := h.JWT.Issuer()
+
+	baseURL := strings.TrimSuffix(issuer, "/")
+	if !strings.HasPrefix(baseURL, "https://") && !strings.HasPrefix(baseURL, "http://") {
+		baseURL = c.BaseURL()
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(openIDConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             baseURL + "/oauth/authorize",
+		TokenEndpoint:                     baseURL + "/oauth/token",
+		UserInfoEndpoint:                  baseURL + "/userinfo",
+		JWKSURI:                           baseURL + "/.well-known/jwks.json",
+		ScopesSupported:                   supportedScopes(),
+		ResponseTypesSupported:            []string{oauth.ResponseTypeCode},
+		GrantTypesSupported:               []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken, oauth.GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{h.JWT.SigningAlgorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{oauth.CodeChallengeS256},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "given_name", "family_name", "cpf"},
+	})
+}
+
+// supportedScopes lista os escopos do OpenID Connect seguidos das permissões concedidas pelos papéis
+func supportedScopes() []string {
+	var roles []string
+	for role := range models.RolePermissions {
+		roles = append(roles, role)
+	}
+
+	permissions := models.PermissionsForRoles(roles, nil)
+	sort.Strings(permissions)
+
+	return append(append([]string{}, oauth.IdentityScopes...), permissions...)
+}
+
+type openIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
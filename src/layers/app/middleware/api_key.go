@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"server/src/layers/service/commands"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// APIKeyHeader é o header alternativo ao "Authorization: ApiKey <chave>"
+	APIKeyHeader = "X-API-Key"
+
+	apiKeyScheme = "ApiKey "
+)
+
+type APIKeyMiddleware struct {
+	authenticate commands.AuthenticateAPIKeyHandler
+	fallback     fiber.Handler
+}
+
+// NewAPIKeyMiddleware cria o middleware que autentica requisições com chave de api.
+// Sem chave na requisição, a autenticação é delegada ao fallback, normalmente o JWTMiddleware.
+func NewAPIKeyMiddleware(authenticate commands.AuthenticateAPIKeyHandler, fallback fiber.Handler) fiber.Handler {
+	return (&APIKeyMiddleware{authenticate: authenticate, fallback: fallback}).Validate
+}
+
+// APIKey extrai a chave de api do header X-API-Key ou do header "Authorization: ApiKey <chave>"
+func APIKey(c *fiber.Ctx) (string, bool) {
+	if key := c.Get(APIKeyHeader); key != "" {
+		return key, true
+	}
+
+	authHeader := c.Get("Authorization")
+	if strings.HasPrefix(authHeader, apiKeyScheme) {
+		return strings.TrimPrefix(authHeader, apiKeyScheme), true
+	}
+	return "", false
+}
+
+// Validate é um middleware do Fiber que autentica a requisição pela chave de api, quando informada.
+func (m *APIKeyMiddleware) Validate(c *fiber.Ctx) error {
+	key, found := APIKey(c)
+	if !found {
+		if m.fallback != nil {
+			return m.fallback(c)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": ErrMissingToken.Error()})
+	}
+
+	principal, err := m.authenticate.Handle(commands.AuthenticateAPIKeyCommand{Key: key})
+	if errors.Is(err, commands.ErrInvalidAPIKey) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "erro interno do servidor"})
+	}
+
+	SetPrincipal(c, principal)
+
+	return c.Next()
+}
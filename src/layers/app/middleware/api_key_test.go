@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"server/src/layers/domain/models"
+	"server/src/layers/infrastructure/persistence"
+	"server/src/layers/service/commands"
+	"testing"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	db, err := persistence.Connect()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	users := persistence.NewUserRepository(db)
+	keys := persistence.NewAPIKeyRepository(db)
+
+	user, _ := users.Store(&models.User{CPF: "52998224725", FirstName: "Ana", LastName: "Lima", Roles: models.StringList{models.RoleAdmin}})
+	created, err := (&commands.CreateAPIKeyHandler{Repo: users, Keys: keys}).Handle(commands.CreateAPIKeyCommand{
+		UserID: user.ID,
+		Name:   "integração",
+		Scopes: []string{models.PermissionUsersRead},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	fallback := func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusTeapot).SendString("fallback")
+	}
+
+	app := fiber.New()
+	app.Use(NewAPIKeyMiddleware(commands.AuthenticateAPIKeyHandler{Repo: users, Keys: keys}, fallback))
+	app.Get("/users", RequirePermission(models.PermissionUsersRead), func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+	app.Put("/users/roles", RequirePermission(models.PermissionRolesWrite), func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+	app.Post("/me/password", RequireUserSession(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"X-API-Key header", "GET", "/users", APIKeyHeader, created.Key, fiber.StatusOK},
+		{"Authorization ApiKey header", "GET", "/users", "Authorization", "ApiKey " + created.Key, fiber.StatusOK},
+		{"Invalid key", "GET", "/users", APIKeyHeader, "ak_invalida", fiber.StatusUnauthorized},
+		{"Scope limits the permissions", "PUT", "/users/roles", APIKeyHeader, created.Key, fiber.StatusForbidden},
+		{"User session required", "POST", "/me/password", APIKeyHeader, created.Key, fiber.StatusForbidden},
+		{"Without key uses the fallback", "GET", "/users", "Authorization", "Bearer token", fiber.StatusTeapot},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(test.method, test.path, nil)
+			req.Header.Set(test.header, test.value)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != test.expected {
+				t.Fatalf("Expected status %v, got %v", test.expected, resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Revoked key", func(t *testing.T) {
+		revoke := commands.RevokeAPIKeyHandler{Keys: keys}
+		if err := revoke.Handle(commands.RevokeAPIKeyCommand{UserID: user.ID, KeyID: created.ID}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		req, _ := http.NewRequest("GET", "/users", nil)
+		req.Header.Set(APIKeyHeader, created.Key)
+		resp, _ := app.Test(req)
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Fatalf("Expected status %v, got %v", fiber.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+}
@@ -1,38 +1,189 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"server/src/layers/service/commands"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+var (
+	ErrMissingToken       = errors.New("autenticação requerida")
+	ErrInvalidTokenFormat = errors.New("formato de token inválido")
+)
+
+// contextKey evita colisões com outras chaves armazenadas em c.Locals
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// JWTMiddlewareConfig reúne as dependências do middleware de autenticação.
+// RestrictedScopes lista, para cada escopo restrito, as rotas ("MÉTODO /caminho") aceitas para tokens com esse escopo.
+// Com SessionCookies habilitado, o token também é lido do cookie da sessão, com a proteção contra CSRF.
+// Sessions, quando informado, recusa os tokens de sessões revogadas e registra a última atividade delas.
+type JWTMiddlewareConfig struct {
+	Manager          *shared.JWTManager
+	Revocations      repository.TokenRevocationRepository
+	RestrictedScopes map[string][]string
+	SessionCookies   SessionCookies
+	Sessions         *commands.TrackSessionHandler
+}
+
 type JWTMiddleware struct {
-	manager *shared.JWTManager
+	manager          *shared.JWTManager
+	revocations      repository.TokenRevocationRepository
+	restrictedScopes map[string][]string
+	sessionCookies   SessionCookies
+	sessions         *commands.TrackSessionHandler
 }
 
 // NewJWTMiddleware cria um novo middleware para validação de JWT.
-func NewJWTMiddleware(manager *shared.JWTManager) fiber.Handler {
-	return (&JWTMiddleware{manager: manager}).Validate
+// Quando revocations é informado, tokens revogados antes de expirar também são rejeitados.
+func NewJWTMiddleware(manager *shared.JWTManager, revocations repository.TokenRevocationRepository) fiber.Handler {
+	return NewJWTMiddlewareWithConfig(JWTMiddlewareConfig{Manager: manager, Revocations: revocations})
 }
 
-// Validate é um middleware do Fiber que valida o JWT token em cada requisição.
-func (j *JWTMiddleware) Validate(c *fiber.Ctx) error {
-	// Extrai o token do header "Authorization", que frequentemente vem como "Bearer <token>"
+// NewJWTMiddlewareWithConfig cria o middleware de autenticação a partir de um JWTMiddlewareConfig.
+// Tokens com escopos OAuth só exercem as permissões incluídas no escopo.
+func NewJWTMiddlewareWithConfig(cfg JWTMiddlewareConfig) fiber.Handler {
+	return (&JWTMiddleware{
+		manager:          cfg.Manager,
+		revocations:      cfg.Revocations,
+		restrictedScopes: cfg.RestrictedScopes,
+		sessionCookies:   cfg.SessionCookies,
+		sessions:         cfg.Sessions,
+	}).Validate
+}
+
+// BearerToken extrai o token do header "Authorization", que frequentemente vem como "Bearer <token>"
+func BearerToken(c *fiber.Ctx) (string, error) {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "autenticação requerida"})
+		return "", ErrMissingToken
 	}
 
 	splitToken := strings.Split(authHeader, "Bearer ")
 	if len(splitToken) != 2 {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "formato de token inválido"})
+		return "", ErrInvalidTokenFormat
 	}
 
-	token := splitToken[1]
-	if _, err := j.manager.Verify(token); err != nil {
+	return splitToken[1], nil
+}
+
+// Validate é um middleware do Fiber que valida o JWT token em cada requisição.
+func (j *JWTMiddleware) Validate(c *fiber.Ctx) error {
+	token, err := j.requestToken(c)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCSRFToken) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	claims, err := j.manager.Verify(token)
+	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token inválido"})
 	}
 
+	if j.revocations != nil {
+		revoked, err := j.revocations.IsRevoked(claims.Id, claims.UserID, claims.IssuedTime())
+		if err != nil {
+			log.Printf("falha ao consultar revogação do token: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "erro interno do servidor"})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token revogado"})
+		}
+	}
+
+	if j.sessions != nil {
+		err := j.sessions.Handle(commands.TrackSessionCommand{FamilyID: claims.FamilyID, At: time.Now()})
+		if errors.Is(err, commands.ErrSessionRevoked) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err != nil {
+			log.Printf("falha ao registrar a atividade da sessão: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "erro interno do servidor"})
+		}
+	}
+
+	// Escopos restritos liberam apenas as rotas listadas; os demais (tokens OAuth) limitam as permissões do token
+	permissions := claims.Permissions
+	if _, restricted := j.restrictedScopes[claims.Scope]; restricted {
+		if !j.allowsScope(claims.Scope, c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "o escopo do token não permite esta operação", "scope": claims.Scope})
+		}
+	} else if claims.Scope != "" {
+		permissions = oauth.Intersect(claims.Permissions, oauth.ParseScope(claims.Scope))
+	}
+
+	SetPrincipal(c, &models.Principal{
+		UserID:      claims.UserID,
+		TenantID:    claims.TenantID,
+		Roles:       claims.Roles,
+		Permissions: permissions,
+		TokenID:     claims.Id,
+		FamilyID:    claims.FamilyID,
+		Scope:       claims.Scope,
+		ClientID:    claims.ClientID,
+		AuthTime:    authTime(claims),
+	})
+
 	return c.Next() // Continue para o próximo middleware ou rota.
 }
+
+// requestToken lê o token do header Authorization ou, se habilitado, do cookie da sessão.
+// Tokens vindos do cookie exigem o token csrf nas requisições que alteram estado.
+func (j *JWTMiddleware) requestToken(c *fiber.Ctx) (string, error) {
+	if !j.sessionCookies.Enabled() {
+		return BearerToken(c)
+	}
+
+	token, fromCookie, err := SessionToken(c)
+	if err != nil {
+		return "", err
+	}
+	if fromCookie {
+		if err := VerifyCSRF(c); err != nil {
+			return "", err
+		}
+	}
+	return token, nil
+}
+
+// authTime usa a claim auth_time e, em tokens emitidos antes dela, a data de emissão do token
+func authTime(claims *shared.UserClaims) time.Time {
+	if claims.AuthTime != 0 {
+		return time.Unix(claims.AuthTime, 0)
+	}
+	return time.Unix(claims.IssuedAt, 0)
+}
+
+// allowsScope informa se a rota da requisição está liberada para tokens com o escopo restrito informado
+func (j *JWTMiddleware) allowsScope(scope string, c *fiber.Ctx) bool {
+	route := c.Method() + " " + c.Path()
+	for _, allowed := range j.restrictedScopes[scope] {
+		if allowed == route {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPrincipal armazena o usuário autenticado no contexto da requisição.
+func SetPrincipal(c *fiber.Ctx, principal *models.Principal) {
+	c.Locals(principalKey, principal)
+}
+
+// CurrentPrincipal retorna o usuário autenticado armazenado pelo middleware, se houver.
+func CurrentPrincipal(c *fiber.Ctx) (*models.Principal, bool) {
+	principal, ok := c.Locals(principalKey).(*models.Principal)
+	return principal, ok && principal != nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"net/http"
 	"server/src/commons/shared"
+	"server/src/layers/infrastructure/persistence"
 	"testing"
 	"time"
 )
@@ -17,9 +18,9 @@ func TestJWTMiddleware(t *testing.T) {
 	app := fiber.New()
 
 	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
-	token, _, _ := manager.Generate(mockUserID)
+	token, refreshToken, _ := manager.Generate(mockUserID)
 
-	app.Use(NewJWTMiddleware(manager))
+	app.Use(NewJWTMiddleware(manager, nil))
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Hello, World!")
@@ -79,4 +80,291 @@ func TestJWTMiddleware(t *testing.T) {
 			t.Fatalf("Expected status %v, got %v", fiber.StatusUnauthorized, resp.StatusCode)
 		}
 	})
+
+	t.Run("Refresh token used as access token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+refreshToken)
+		resp, err := app.Test(req)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Fatalf("Expected status %v, got %v", fiber.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+}
+
+func TestJWTMiddleware_RevokedToken(t *testing.T) {
+	app := fiber.New()
+
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+	revocations := persistence.NewMemoryTokenRevocationRepository()
+	token, _, _ := manager.Generate(mockUserID)
+
+	app.Use(NewJWTMiddleware(manager, revocations))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	claims, _ := manager.Verify(token)
+	revocations.Revoke(claims.Id, claims.UserID, time.Unix(claims.ExpiresAt, 0))
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("Expected status %v, got %v", fiber.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestJWTMiddleware_SignInAfterRevokeAll(t *testing.T) {
+	app := fiber.New()
+
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+	revocations := persistence.NewMemoryTokenRevocationRepository()
+
+	app.Use(NewJWTMiddleware(manager, revocations))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	before, _, _ := manager.Generate(mockUserID)
+	time.Sleep(2 * time.Millisecond)
+	revocations.RevokeAllForUser(mockUserID, time.Now(), time.Now().Add(time.Hour))
+	time.Sleep(2 * time.Millisecond)
+
+	// O novo login acontece, em geral, no mesmo segundo do "iat" da revogação
+	after, _, _ := manager.Generate(mockUserID)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{"Token issued before revoking all", before, fiber.StatusUnauthorized},
+		{"Token issued right after revoking all", after, fiber.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+test.token)
+			resp, err := app.Test(req)
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if resp.StatusCode != test.expected {
+				t.Fatalf("Expected status %v, got %v", test.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestJWTMiddleware_StoresPrincipal(t *testing.T) {
+	app := fiber.New()
+
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+	pair, _ := manager.GeneratePair(shared.TokenSubject{UserID: mockUserID, Roles: []string{"admin"}}, uuid.New())
+
+	app.Use(NewJWTMiddleware(manager, nil))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		principal, ok := CurrentPrincipal(c)
+		if !ok || principal.UserID != mockUserID || !principal.HasRole("admin") || principal.TokenID == "" {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.SendString("Hello, World!")
+	})
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.Token)
+	resp, err := app.Test(req)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status %v, got %v", fiber.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestCurrentPrincipal_WithoutAuthentication(t *testing.T) {
+	app := fiber.New()
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		if _, ok := CurrentPrincipal(c); ok {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	resp, _ := app.Test(req)
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected no principal without authentication, got status %v", resp.StatusCode)
+	}
+}
+
+func TestJWTMiddleware_RestrictedScope(t *testing.T) {
+	app := fiber.New()
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+	pair, _ := manager.GeneratePair(shared.TokenSubject{UserID: mockUserID, Scope: shared.ScopePasswordChange}, uuid.New())
+
+	app.Use(NewJWTMiddlewareWithConfig(JWTMiddlewareConfig{
+		Manager:          manager,
+		RestrictedScopes: map[string][]string{shared.ScopePasswordChange: {"POST /me/password"}},
+	}))
+	app.Post("/me/password", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/me", func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+	}{
+		{"POST", "/me/password", fiber.StatusNoContent},
+		{"GET", "/me", fiber.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, test.path, nil)
+		req.Header.Set("Authorization", "Bearer "+pair.Token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != test.expected {
+			t.Fatalf("%s %s: expected status %v, got %v", test.method, test.path, test.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestJWTMiddleware_OAuthScopeLimitsPermissions(t *testing.T) {
+	app := fiber.New()
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+	pair, _ := manager.GeneratePair(shared.TokenSubject{
+		UserID:      mockUserID,
+		Permissions: []string{"profile:read", "profile:write"},
+		Scope:       "profile:read",
+		ClientID:    "spa",
+	}, uuid.New())
+
+	app.Use(NewJWTMiddlewareWithConfig(JWTMiddlewareConfig{
+		Manager:          manager,
+		RestrictedScopes: map[string][]string{shared.ScopePasswordChange: {"POST /me/password"}},
+	}))
+	app.Get("/me", RequirePermission("profile:read"), func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+	app.Patch("/me", RequirePermission("profile:write"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Post("/me/password", RequireUserSession(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+	}{
+		{"GET", "/me", fiber.StatusOK},
+		{"PATCH", "/me", fiber.StatusForbidden},
+		{"POST", "/me/password", fiber.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, test.path, nil)
+		req.Header.Set("Authorization", "Bearer "+pair.Token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != test.expected {
+			t.Fatalf("%s %s: expected status %v, got %v", test.method, test.path, test.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestJWTMiddleware_SessionCookie(t *testing.T) {
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+	token, _, _ := manager.Generate(mockUserID)
+	csrfToken := "csrf-token"
+
+	newApp := func(mode string) *fiber.App {
+		app := fiber.New()
+		app.Use(NewJWTMiddlewareWithConfig(JWTMiddlewareConfig{
+			Manager:        manager,
+			SessionCookies: SessionCookies{Mode: mode},
+		}))
+		app.Get("/me", func(c *fiber.Ctx) error {
+			return c.SendString("Hello, World!")
+		})
+		app.Patch("/me", func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+		return app
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		method   string
+		csrf     string
+		expected int
+	}{
+		{"Safe method without csrf", SessionCookieModeOptional, "GET", "", fiber.StatusOK},
+		{"State change with csrf", SessionCookieModeOptional, "PATCH", csrfToken, fiber.StatusNoContent},
+		{"State change without csrf", SessionCookieModeOptional, "PATCH", "", fiber.StatusForbidden},
+		{"State change with wrong csrf", SessionCookieModeAlways, "PATCH", "outro", fiber.StatusForbidden},
+		{"Cookies disabled", SessionCookieModeDisabled, "GET", "", fiber.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(test.method, "/me", nil)
+			req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: token})
+			req.AddCookie(&http.Cookie{Name: CSRFTokenCookie, Value: csrfToken})
+			if test.csrf != "" {
+				req.Header.Set(CSRFTokenHeader, test.csrf)
+			}
+			resp, err := newApp(test.mode).Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != test.expected {
+				t.Fatalf("Expected status %v, got %v", test.expected, resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Authorization header takes precedence and skips csrf", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "invalido"})
+		resp, err := newApp(SessionCookieModeOptional).Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusNoContent {
+			t.Fatalf("Expected status %v, got %v", fiber.StatusNoContent, resp.StatusCode)
+		}
+	})
 }
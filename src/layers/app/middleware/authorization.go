@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission cria um middleware que exige que o usuário autenticado possua todas as permissões informadas.
+// Deve ser registrado depois do JWTMiddleware.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "autenticação requerida"})
+		}
+
+		for _, permission := range permissions {
+			if !principal.HasPermission(permission) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "permissão negada"})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireAnyPermission cria um middleware que exige ao menos uma das permissões informadas, para rotas
+// cujo acesso ao registro é decidido depois pelas políticas. Deve ser registrado depois do JWTMiddleware.
+func RequireAnyPermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "autenticação requerida"})
+		}
+
+		for _, permission := range permissions {
+			if principal.HasPermission(permission) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "permissão negada"})
+	}
+}
+
+// RequireUserSession recusa requisições autenticadas por chave de api ou por tokens emitidos a clientes OAuth,
+// reservando a rota a sessões do usuário, como as que gerenciam senhas, segundo fator, as próprias chaves
+// e os consentimentos OAuth. Deve ser registrado depois da autenticação.
+func RequireUserSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "autenticação requerida"})
+		}
+
+		if principal.APIKeyID != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "operação não permitida com chave de api"})
+		}
+		if principal.ClientID != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "operação não permitida com token de cliente oauth"})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"net/http"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"testing"
+	"time"
+)
+
+func TestRequirePermission(t *testing.T) {
+	app := fiber.New()
+
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+
+	app.Get("/users", NewJWTMiddleware(manager, nil), RequirePermission(models.PermissionUsersRead), func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	tests := []struct {
+		name        string
+		permissions []string
+		expected    int
+	}{
+		{"Without permission", models.RolePermissions[models.RoleUser], fiber.StatusForbidden},
+		{"With permission", models.RolePermissions[models.RoleAdmin], fiber.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pair, _ := manager.GeneratePair(shared.TokenSubject{UserID: mockUserID, Permissions: test.permissions}, uuid.New())
+
+			req, _ := http.NewRequest("GET", "/users", nil)
+			req.Header.Set("Authorization", "Bearer "+pair.Token)
+			resp, err := app.Test(req)
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if resp.StatusCode != test.expected {
+				t.Fatalf("Expected status %v, got %v", test.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRequireAnyPermission(t *testing.T) {
+	app := fiber.New()
+
+	manager := shared.NewJWTManager(mockSecret, time.Hour, 24*time.Hour)
+
+	app.Get("/users/:id", NewJWTMiddleware(manager, nil), RequireAnyPermission(models.PermissionProfileRead, models.PermissionUsersRead), func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	tests := []struct {
+		name     string
+		subject  shared.TokenSubject
+		expected int
+	}{
+		{"OpenID only token", shared.TokenSubject{UserID: mockUserID, Scope: "openid", ClientID: "app"}, fiber.StatusForbidden},
+		{"Metrics only token", shared.TokenSubject{UserID: mockUserID, Permissions: []string{models.PermissionMetricsRead}, Scope: models.PermissionMetricsRead}, fiber.StatusForbidden},
+		{"Profile token", shared.TokenSubject{UserID: mockUserID, Permissions: models.RolePermissions[models.RoleUser]}, fiber.StatusOK},
+		{"Users reader token", shared.TokenSubject{UserID: mockUserID, Permissions: []string{models.PermissionUsersRead}}, fiber.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pair, _ := manager.GeneratePair(test.subject, uuid.New())
+
+			req, _ := http.NewRequest("GET", "/users/"+mockUserID.String(), nil)
+			req.Header.Set("Authorization", "Bearer "+pair.Token)
+			resp, err := app.Test(req)
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if resp.StatusCode != test.expected {
+				t.Fatalf("Expected status %v, got %v", test.expected, resp.StatusCode)
+			}
+		})
+	}
+}
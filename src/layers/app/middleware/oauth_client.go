@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/service/commands"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const basicScheme = "Basic "
+
+type OAuthClientMiddleware struct {
+	authenticate commands.AuthenticateOAuthClientHandler
+	fallback     fiber.Handler
+}
+
+// NewOAuthClientMiddleware cria o middleware que autentica clientes oauth pelo header "Authorization: Basic"
+// ou pelos campos client_id e client_secret do corpo. Sem credenciais de cliente, delega ao fallback,
+// normalmente o APIKeyMiddleware.
+func NewOAuthClientMiddleware(authenticate commands.AuthenticateOAuthClientHandler, fallback fiber.Handler) fiber.Handler {
+	return (&OAuthClientMiddleware{authenticate: authenticate, fallback: fallback}).Validate
+}
+
+// BasicClientCredentials extrai client_id e client_secret do header "Authorization: Basic",
+// decodificando-os como application/x-www-form-urlencoded conforme a RFC 6749, seção 2.3.1
+func BasicClientCredentials(c *fiber.Ctx) (string, string, bool) {
+	authHeader := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(authHeader, basicScheme) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(authHeader, basicScheme))
+	if err != nil {
+		return "", "", false
+	}
+
+	rawID, rawSecret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", false
+	}
+
+	clientID, err := url.QueryUnescape(rawID)
+	if err != nil {
+		return "", "", false
+	}
+	clientSecret, err := url.QueryUnescape(rawSecret)
+	if err != nil {
+		return "", "", false
+	}
+	return clientID, clientSecret, true
+}
+
+// ClientCredentials extrai as credenciais do cliente do header Basic ou, na ausência dele, do corpo da requisição
+func ClientCredentials(c *fiber.Ctx) (string, string, bool) {
+	if clientID, clientSecret, found := BasicClientCredentials(c); found {
+		return clientID, clientSecret, true
+	}
+
+	var body clientCredentialsBody
+	if err := c.BodyParser(&body); err != nil || body.ClientID == "" {
+		return "", "", false
+	}
+	return body.ClientID, body.ClientSecret, true
+}
+
+// Validate é um middleware do Fiber que autentica o cliente oauth, quando há credenciais de cliente na requisição.
+func (m *OAuthClientMiddleware) Validate(c *fiber.Ctx) error {
+	clientID, clientSecret, found := ClientCredentials(c)
+	if !found {
+		if m.fallback != nil {
+			return m.fallback(c)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(oauth.NewError(oauth.ErrInvalidClient, "credenciais do cliente ausentes"))
+	}
+
+	principal, err := m.authenticate.Handle(commands.AuthenticateOAuthClientCommand{ClientID: clientID, ClientSecret: clientSecret})
+	if err != nil {
+		var oauthErr *oauth.Error
+		if errors.As(err, &oauthErr) && oauthErr.Code == oauth.ErrInvalidClient {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(oauthErr)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "erro interno do servidor"})
+	}
+
+	SetPrincipal(c, principal)
+
+	return c.Next()
+}
+
+type clientCredentialsBody struct {
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+}
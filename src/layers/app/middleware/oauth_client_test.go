@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"net/url"
+	"server/src/layers/domain/models"
+	"server/src/layers/infrastructure/persistence"
+	"server/src/layers/service/commands"
+	"strings"
+	"testing"
+)
+
+func TestOAuthClientMiddleware(t *testing.T) {
+	db, err := persistence.Connect()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	clients := persistence.NewOAuthClientRepository(db)
+	registered, err := (&commands.RegisterOAuthClientHandler{Clients: clients}).Handle(commands.RegisterOAuthClientCommand{
+		Name:       "gateway",
+		Scopes:     []string{models.PermissionTokensIntrospect},
+		GrantTypes: []string{"client_credentials"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	fallback := func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusTeapot).SendString("fallback")
+	}
+
+	app := fiber.New()
+	app.Use(NewOAuthClientMiddleware(commands.AuthenticateOAuthClientHandler{Clients: clients}, fallback))
+	app.Post("/oauth/introspect", RequirePermission(models.PermissionTokensIntrospect), func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+
+	basic := func(id, secret string) string {
+		req, _ := http.NewRequest("POST", "/", nil)
+		req.SetBasicAuth(id, secret)
+		return req.Header.Get("Authorization")
+	}
+	form := url.Values{"client_id": {registered.ClientID}, "client_secret": {registered.ClientSecret}}.Encode()
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		expected      int
+	}{
+		{"Basic credentials", basic(registered.ClientID, registered.ClientSecret), "", fiber.StatusOK},
+		{"Credentials in the body", "", form, fiber.StatusOK},
+		{"Wrong secret", basic(registered.ClientID, "errado"), "", fiber.StatusUnauthorized},
+		{"Without credentials uses the fallback", "ApiKey ak_chave", "", fiber.StatusTeapot},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/oauth/introspect", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != test.expected {
+				t.Fatalf("Expected status %v, got %v", test.expected, resp.StatusCode)
+			}
+			if test.expected == fiber.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
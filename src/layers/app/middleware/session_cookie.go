@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"server/src/commons/shared"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Modos de entrega dos tokens em cookies
+const (
+	SessionCookieModeDisabled = "disabled"
+	SessionCookieModeOptional = "optional"
+	SessionCookieModeAlways   = "always"
+)
+
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFTokenCookie    = "csrf_token"
+	CSRFTokenHeader    = "X-CSRF-Token"
+
+	// AuthModeHeader permite ao cliente pedir a sessão em cookies no modo "optional"
+	AuthModeHeader = "X-Auth-Mode"
+	AuthModeCookie = "cookie"
+
+	// refreshTokenPath limita o envio do cookie do refresh token ao endpoint de renovação
+	refreshTokenPath = "/token/refresh"
+	csrfTokenBytes   = 32
+)
+
+var ErrInvalidCSRFToken = errors.New("token csrf inválido")
+
+// SessionCookies configura a sessão de navegadores em cookies. Os tokens ficam em cookies HttpOnly
+// e as requisições que alteram estado precisam repetir o cookie csrf_token no header X-CSRF-Token
+// (double-submit), já que o navegador envia os cookies automaticamente.
+type SessionCookies struct {
+	Mode            string
+	Secure          bool
+	SameSite        string
+	Domain          string
+	TokenDuration   time.Duration
+	RefreshDuration time.Duration
+}
+
+// Enabled informa se os tokens podem ser entregues e lidos em cookies.
+func (s SessionCookies) Enabled() bool {
+	return s.Mode == SessionCookieModeOptional || s.Mode == SessionCookieModeAlways
+}
+
+// Requested informa se a resposta deve entregar os tokens em cookies em vez do corpo.
+func (s SessionCookies) Requested(c *fiber.Ctx) bool {
+	switch s.Mode {
+	case SessionCookieModeAlways:
+		return true
+	case SessionCookieModeOptional:
+		return strings.EqualFold(c.Get(AuthModeHeader), AuthModeCookie)
+	default:
+		return false
+	}
+}
+
+// Set grava os cookies da sessão e retorna o novo token csrf, que o cliente também pode ler do cookie csrf_token.
+func (s SessionCookies) Set(c *fiber.Ctx, accessToken, refreshToken string) (string, error) {
+	csrfToken, err := shared.GenerateRandomToken(csrfTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	c.Cookie(s.cookie(AccessTokenCookie, accessToken, "/", s.TokenDuration, true))
+	c.Cookie(s.cookie(RefreshTokenCookie, refreshToken, refreshTokenPath, s.RefreshDuration, true))
+	c.Cookie(s.cookie(CSRFTokenCookie, csrfToken, "/", s.RefreshDuration, false))
+	return csrfToken, nil
+}
+
+// Clear remove os cookies da sessão.
+func (s SessionCookies) Clear(c *fiber.Ctx) {
+	c.Cookie(s.cookie(AccessTokenCookie, "", "/", -time.Second, true))
+	c.Cookie(s.cookie(RefreshTokenCookie, "", refreshTokenPath, -time.Second, true))
+	c.Cookie(s.cookie(CSRFTokenCookie, "", "/", -time.Second, false))
+}
+
+func (s SessionCookies) cookie(name, value, path string, maxAge time.Duration, httpOnly bool) *fiber.Cookie {
+	cookie := &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   s.Domain,
+		Secure:   s.Secure,
+		HTTPOnly: httpOnly,
+		SameSite: s.SameSite,
+	}
+	// Uma expiração no passado faz o navegador descartar o cookie
+	if maxAge < 0 {
+		cookie.Expires = time.Unix(0, 0)
+	} else {
+		cookie.MaxAge = int(maxAge.Seconds())
+	}
+	return cookie
+}
+
+// SessionToken extrai o token de acesso do header Authorization ou, na ausência dele, do cookie da sessão.
+// O segundo retorno indica se o token veio do cookie.
+func SessionToken(c *fiber.Ctx) (string, bool, error) {
+	if c.Get(fiber.HeaderAuthorization) == "" {
+		if token := c.Cookies(AccessTokenCookie); token != "" {
+			return token, true, nil
+		}
+	}
+
+	token, err := BearerToken(c)
+	return token, false, err
+}
+
+// VerifyCSRF exige, nos métodos que alteram estado, que o header X-CSRF-Token repita o cookie csrf_token.
+func VerifyCSRF(c *fiber.Ctx) error {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return nil
+	}
+
+	cookie, header := c.Cookies(CSRFTokenCookie), c.Get(CSRFTokenHeader)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+		return ErrInvalidCSRFToken
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package authn
+
+import (
+	"errors"
+	"server/src/layers/domain/models"
+)
+
+var (
+	// ErrUnknownIdentity indica que o provedor não conhece o identificador; a cadeia consulta o próximo provedor
+	ErrUnknownIdentity = errors.New("identidade desconhecida")
+	// ErrInvalidCredentials indica que o provedor conhece a identidade, mas a senha não confere
+	ErrInvalidCredentials = errors.New("credenciais inválidas")
+	// ErrUnavailable indica que o provedor não pôde ser consultado
+	ErrUnavailable = errors.New("provedor de identidade indisponível")
+)
+
+// Authenticator verifica o identificador e a senha informados no login e retorna o usuário local correspondente.
+type Authenticator interface {
+	// Name identifica o provedor e é registrado como método no histórico de logins
+	Name() string
+	Authenticate(identifier, password string) (*models.User, error)
+}
+
+// Chain consulta os provedores em ordem. O primeiro que reconhecer o identificador decide o login:
+// a cadeia só segue para o próximo quando o provedor responde ErrUnknownIdentity.
+type Chain []Authenticator
+
+// Authenticate retorna o usuário autenticado e o nome do provedor que o reconheceu.
+// Sem nenhum provedor que reconheça o identificador, retorna ErrUnknownIdentity.
+func (c Chain) Authenticate(identifier, password string) (*models.User, string, error) {
+	for _, authenticator := range c {
+		user, err := authenticator.Authenticate(identifier, password)
+		if errors.Is(err, ErrUnknownIdentity) {
+			continue
+		}
+		if err != nil {
+			return nil, authenticator.Name(), err
+		}
+		return user, authenticator.Name(), nil
+	}
+	return nil, "", ErrUnknownIdentity
+}
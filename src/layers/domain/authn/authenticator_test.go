@@ -0,0 +1,177 @@
+package authn
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+)
+
+type stubAuthenticator struct {
+	name  string
+	user  *models.User
+	err   error
+	calls int
+}
+
+func (s *stubAuthenticator) Name() string { return s.name }
+
+func (s *stubAuthenticator) Authenticate(identifier, password string) (*models.User, error) {
+	s.calls++
+	return s.user, s.err
+}
+
+func TestChain_Authenticate(t *testing.T) {
+	user := &models.User{FirstName: "Ana"}
+
+	t.Run("segue para o próximo provedor quando a identidade é desconhecida", func(t *testing.T) {
+		local := &stubAuthenticator{name: "password", err: ErrUnknownIdentity}
+		ldap := &stubAuthenticator{name: "ldap", user: user}
+
+		got, provider, err := Chain{local, ldap}.Authenticate("ana", "senha")
+		if err != nil || got != user || provider != "ldap" {
+			t.Fatalf("Esperava o usuário do ldap, obteve %v, %q, %v", got, provider, err)
+		}
+	})
+
+	t.Run("para no provedor que reconhece a identidade", func(t *testing.T) {
+		local := &stubAuthenticator{name: "password", err: ErrInvalidCredentials}
+		ldap := &stubAuthenticator{name: "ldap", user: user}
+
+		_, provider, err := Chain{local, ldap}.Authenticate("ana", "errada")
+		if !errors.Is(err, ErrInvalidCredentials) || provider != "password" {
+			t.Fatalf("Esperava credenciais inválidas do provedor local, obteve %q, %v", provider, err)
+		}
+		if ldap.calls != 0 {
+			t.Error("O ldap não deveria ser consultado depois de uma senha local incorreta")
+		}
+	})
+
+	t.Run("nenhum provedor reconhece a identidade", func(t *testing.T) {
+		_, _, err := Chain{&stubAuthenticator{name: "password", err: ErrUnknownIdentity}}.Authenticate("ana", "senha")
+		if !errors.Is(err, ErrUnknownIdentity) {
+			t.Fatalf("Esperava identidade desconhecida, obteve %v", err)
+		}
+	})
+}
+
+type stubDirectory struct {
+	identity *Identity
+	err      error
+}
+
+func (s *stubDirectory) Authenticate(username, password string) (*Identity, error) {
+	return s.identity, s.err
+}
+
+// memoryUsers implementa apenas os métodos do UserRepository usados no provisionamento
+type memoryUsers struct {
+	repository.UserRepository
+	users   []*models.User
+	updates int
+}
+
+func (m *memoryUsers) Store(user *models.User) (*models.User, error) {
+	user.ID = uuid.New()
+	m.users = append(m.users, user)
+	return user, nil
+}
+
+func (m *memoryUsers) FindByCPF(cpf string) (*models.User, error) {
+	for _, user := range m.users {
+		if user.CPF == cpf {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *memoryUsers) FindByEmail(email string) (*models.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *memoryUsers) Update(user *models.User) error {
+	m.updates++
+	return nil
+}
+
+func TestDirectoryAuthenticator_ProvisionsOnFirstSignIn(t *testing.T) {
+	users := &memoryUsers{}
+	directory := &stubDirectory{identity: &Identity{
+		ExternalID: "uid=ana,ou=people,dc=example,dc=com",
+		CPF:        "52998224725",
+		FirstName:  "Ana",
+		LastName:   "Souza",
+		Email:      "Ana.Souza@Example.com",
+	}}
+	authenticator := NewDirectoryAuthenticator("ldap", directory, users)
+
+	user, err := authenticator.Authenticate("ana", "senha")
+	if err != nil {
+		t.Fatalf("Erro ao autenticar: %v", err)
+	}
+	if len(users.users) != 1 || user.AuthProvider != "ldap" || user.Password != "" {
+		t.Fatalf("Esperava um usuário externo provisionado, obteve %+v", user)
+	}
+	if user.Email != "ana.souza@example.com" || !user.EmailVerified() {
+		t.Errorf("Esperava o e-mail do diretório confirmado, obteve %q", user.Email)
+	}
+
+	// O segundo login reaproveita o usuário e sincroniza os atributos alterados no diretório
+	directory.identity.LastName = "Souza Lima"
+	again, err := authenticator.Authenticate("ana", "senha")
+	if err != nil || again.ID != user.ID {
+		t.Fatalf("Esperava o mesmo usuário, obteve %v, %v", again, err)
+	}
+	if len(users.users) != 1 || again.LastName != "Souza Lima" || users.updates != 1 {
+		t.Errorf("Esperava o sobrenome sincronizado, obteve %q (%d atualizações)", again.LastName, users.updates)
+	}
+}
+
+func TestDirectoryAuthenticator_RejectsLocalAccount(t *testing.T) {
+	local, _ := models.NewUser("52998224725", "Ana", "Local", "hash")
+	users := &memoryUsers{users: []*models.User{local}}
+	directory := &stubDirectory{identity: &Identity{CPF: "52998224725", FirstName: "Ana", LastName: "Diretório", Email: "ana@example.com"}}
+
+	user, err := NewDirectoryAuthenticator("ldap", directory, users).Authenticate("ana", "senha")
+	if !errors.Is(err, ErrAccountConflict) || user != nil {
+		t.Fatalf("Esperava o conflito com a conta local de mesmo cpf, obteve %v, %v", user, err)
+	}
+	if local.LastName != "Local" || local.Email != "" || local.EmailVerifiedAt != nil || users.updates != 0 {
+		t.Error("A conta local não deveria ser alterada pelo diretório")
+	}
+}
+
+func TestDirectoryAuthenticator_RejectsOtherProviderAccount(t *testing.T) {
+	other, _ := models.NewExternalUser("52998224725", "Ana", "Souza", "outro", "cn=ana")
+	users := &memoryUsers{users: []*models.User{other}}
+	directory := &stubDirectory{identity: &Identity{ExternalID: "uid=ana", CPF: "52998224725", FirstName: "Ana", LastName: "Souza", Email: "ana@example.com"}}
+
+	if _, err := NewDirectoryAuthenticator("ldap", directory, users).Authenticate("ana", "senha"); !errors.Is(err, ErrAccountConflict) {
+		t.Fatalf("Esperava o conflito com a conta de outro provedor, obteve %v", err)
+	}
+	if other.Email != "" || other.ExternalID != "cn=ana" || users.updates != 0 {
+		t.Error("A conta de outro provedor não deveria ser alterada pelo diretório")
+	}
+}
+
+func TestDirectoryAuthenticator_Errors(t *testing.T) {
+	users := &memoryUsers{}
+
+	_, err := NewDirectoryAuthenticator("ldap", &stubDirectory{err: ErrInvalidCredentials}, users).Authenticate("ana", "errada")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Esperava credenciais inválidas, obteve %v", err)
+	}
+
+	incomplete := &stubDirectory{identity: &Identity{CPF: "123", FirstName: "Ana", LastName: "Souza"}}
+	_, err = NewDirectoryAuthenticator("ldap", incomplete, users).Authenticate("ana", "senha")
+	if !errors.Is(err, ErrIncompleteIdentity) || len(users.users) != 0 {
+		t.Errorf("Esperava a recusa da conta sem cpf válido, obteve %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package authn
+
+import (
+	"errors"
+	"log"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// ErrIncompleteIdentity indica que a conta do diretório não tem os atributos exigidos para o provisionamento
+var ErrIncompleteIdentity = errors.New("a conta do diretório não tem cpf, nome e sobrenome válidos")
+
+// ErrAccountConflict indica que o cpf da conta do diretório já pertence a uma conta local ou de outro provedor
+var ErrAccountConflict = errors.New("o cpf da conta do diretório já pertence a outra conta; entre com a senha dessa conta")
+
+// Identity é uma conta autenticada por um diretório externo, com os atributos já mapeados para o usuário local.
+type Identity struct {
+	ExternalID string
+	CPF        string
+	FirstName  string
+	LastName   string
+	Email      string
+}
+
+// Directory verifica credenciais em um diretório externo, como o LDAP corporativo.
+// Responde ErrUnknownIdentity para contas inexistentes, ErrInvalidCredentials para senhas incorretas
+// e ErrUnavailable quando o diretório não pode ser consultado.
+type Directory interface {
+	Authenticate(username, password string) (*Identity, error)
+}
+
+// DirectoryAuthenticator autentica pelo diretório e provisiona o usuário local no primeiro login (just-in-time).
+// As contas são ligadas pelo cpf: uma conta local ou de outro provedor com o mesmo cpf é recusada com
+// ErrAccountConflict, pois o bind no diretório não prova a posse da senha nem do segundo fator dessa conta,
+// e as contas provisionadas pelo provedor têm nome, sobrenome e e-mail atualizados a cada login.
+type DirectoryAuthenticator struct {
+	Provider  string
+	Directory Directory
+	Repo      repository.UserRepository
+}
+
+// NewDirectoryAuthenticator cria um DirectoryAuthenticator registrado com o nome do provedor.
+func NewDirectoryAuthenticator(provider string, directory Directory, repo repository.UserRepository) *DirectoryAuthenticator {
+	return &DirectoryAuthenticator{Provider: provider, Directory: directory, Repo: repo}
+}
+
+// Name retorna o nome do provedor.
+func (a *DirectoryAuthenticator) Name() string {
+	return a.Provider
+}
+
+// Authenticate verifica as credenciais no diretório e retorna o usuário local correspondente.
+func (a *DirectoryAuthenticator) Authenticate(identifier, password string) (*models.User, error) {
+	identity, err := a.Directory.Authenticate(identifier, password)
+	if err != nil {
+		return nil, err
+	}
+	return a.provision(identity)
+}
+
+// provision cria o usuário local na primeira autenticação ou sincroniza os atributos de um já provisionado.
+func (a *DirectoryAuthenticator) provision(identity *Identity) (*models.User, error) {
+	user, err := a.Repo.FindByCPF(identity.CPF)
+	if err != nil || user == nil {
+		user, err = models.NewExternalUser(identity.CPF, identity.FirstName, identity.LastName, a.Provider, identity.ExternalID)
+		if err != nil {
+			log.Printf("conta %s do provedor %s não provisionada: %v", identity.ExternalID, a.Provider, err)
+			return nil, ErrIncompleteIdentity
+		}
+		a.syncEmail(user, identity.Email)
+
+		if _, err := a.Repo.Store(user); err != nil {
+			return nil, errors.New("falha ao provisionar o usuário")
+		}
+		return user, nil
+	}
+
+	// Contas locais e de outros provedores não são do diretório: nem o login nem os atributos passam por ele
+	if user.AuthProvider != a.Provider {
+		log.Printf("conta %s do provedor %s recusada: o cpf pertence ao usuário %s de outro provedor", identity.ExternalID, a.Provider, user.ID)
+		return nil, ErrAccountConflict
+	}
+
+	changed := a.syncEmail(user, identity.Email)
+	if identity.FirstName != "" && identity.FirstName != user.FirstName {
+		user.FirstName = identity.FirstName
+		changed = true
+	}
+	if identity.LastName != "" && identity.LastName != user.LastName {
+		user.LastName = identity.LastName
+		changed = true
+	}
+	if identity.ExternalID != "" && identity.ExternalID != user.ExternalID {
+		user.ExternalID = identity.ExternalID
+		changed = true
+	}
+
+	// Uma falha na sincronização não impede o login; os atributos são atualizados no próximo
+	if changed {
+		if err := a.Repo.Update(user); err != nil {
+			log.Printf("falha ao sincronizar o usuário %s com o provedor %s: %v", user.ID, a.Provider, err)
+		}
+	}
+	return user, nil
+}
+
+// syncEmail adota o e-mail do diretório como confirmado, a menos que seja inválido ou pertença a outro usuário.
+// Só é chamado para contas do próprio provedor, que responde pelo e-mail que publica.
+func (a *DirectoryAuthenticator) syncEmail(user *models.User, email string) bool {
+	email, err := shared.NormalizeEmail(email)
+	if err != nil || email == user.Email {
+		return false
+	}
+
+	if owner, err := a.Repo.FindByEmail(email); err == nil && owner != nil && owner.ID != user.ID {
+		log.Printf("e-mail do provedor %s já pertence a outro usuário e não foi sincronizado", a.Provider)
+		return false
+	}
+
+	verifiedAt := time.Now()
+	user.Email = email
+	user.EmailVerifiedAt = &verifiedAt
+	return true
+}
@@ -0,0 +1,154 @@
+package introspection
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"strings"
+	"time"
+)
+
+// ErrUnknownToken indica que o token não foi emitido por este servidor ou não pode mais ser verificado.
+var ErrUnknownToken = errors.New("token desconhecido")
+
+// Token descreve um token emitido por este servidor e o seu estado atual.
+type Token struct {
+	Active    bool
+	Type      string // oauth.TokenTypeAccessToken, oauth.TokenTypeRefreshToken ou oauth.TokenTypeAPIKey
+	ID        string // jti dos tokens JWT ou ID da chave de api
+	UserID    uuid.UUID
+	ClientID  string
+	FamilyID  uuid.UUID
+	Scope     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time // zero para chaves de api sem expiração
+	Issuer    string
+	Audience  string
+}
+
+// Inspector identifica tokens de acesso, refresh tokens e chaves de api e consulta se ainda estão ativos,
+// combinando a verificação do JWTManager com as revogações e os registros armazenados.
+type Inspector struct {
+	JWT           *shared.JWTManager
+	Revocations   repository.TokenRevocationRepository
+	RefreshTokens repository.RefreshTokenRepository
+	APIKeys       repository.APIKeyRepository
+	Users         repository.UserRepository
+}
+
+// Inspect descreve o token. O hint (token_type_hint) apenas define qual tipo de JWT é tentado primeiro.
+// Tokens que não são deste servidor, malformados ou expirados retornam ErrUnknownToken.
+func (i *Inspector) Inspect(token, hint string) (*Token, error) {
+	if strings.HasPrefix(token, models.APIKeyPrefix) {
+		return i.inspectAPIKey(token)
+	}
+
+	inspectors := []func(string) (*Token, error){i.inspectAccessToken, i.inspectRefreshToken}
+	if hint == oauth.TokenTypeRefreshToken {
+		inspectors[0], inspectors[1] = inspectors[1], inspectors[0]
+	}
+
+	for _, inspect := range inspectors {
+		described, err := inspect(token)
+		if !errors.Is(err, ErrUnknownToken) {
+			return described, err
+		}
+	}
+	return nil, ErrUnknownToken
+}
+
+func (i *Inspector) inspectAccessToken(token string) (*Token, error) {
+	claims, err := i.JWT.Verify(token)
+	if err != nil {
+		return nil, ErrUnknownToken
+	}
+
+	// Tokens sem escopo exercem todas as permissões do usuário, informadas como escopo
+	scope := claims.Scope
+	if scope == "" {
+		scope = oauth.FormatScope(claims.Permissions)
+	}
+
+	described := &Token{
+		Type:      oauth.TokenTypeAccessToken,
+		ID:        claims.Id,
+		UserID:    claims.UserID,
+		ClientID:  claims.ClientID,
+		FamilyID:  claims.FamilyID,
+		Scope:     scope,
+		IssuedAt:  claims.IssuedTime(),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+	}
+
+	revoked, err := i.Revocations.IsRevoked(claims.Id, claims.UserID, described.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	described.Active = !revoked
+	return described, nil
+}
+
+func (i *Inspector) inspectRefreshToken(token string) (*Token, error) {
+	claims, err := i.JWT.VerifyRefreshToken(token)
+	if err != nil {
+		return nil, ErrUnknownToken
+	}
+
+	stored, err := i.RefreshTokens.FindByTokenID(claims.Id)
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil, ErrUnknownToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		Active:    !stored.IsConsumed() && !stored.IsExpired(time.Now()),
+		Type:      oauth.TokenTypeRefreshToken,
+		ID:        claims.Id,
+		UserID:    stored.UserID,
+		ClientID:  stored.ClientID,
+		FamilyID:  stored.FamilyID,
+		Scope:     stored.Scope,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: stored.ExpiresAt,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+	}, nil
+}
+
+// inspectAPIKey informa como escopo apenas as permissões que o usuário ainda possui, como na autenticação da chave
+func (i *Inspector) inspectAPIKey(token string) (*Token, error) {
+	key, err := i.APIKeys.FindByHash(shared.HashToken(token))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, ErrUnknownToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	described := &Token{
+		Active:   key.IsActive(time.Now()),
+		Type:     oauth.TokenTypeAPIKey,
+		ID:       key.ID.String(),
+		UserID:   key.UserID,
+		IssuedAt: key.CreatedAt,
+	}
+	if key.ExpiresAt != nil {
+		described.ExpiresAt = *key.ExpiresAt
+	}
+
+	user, err := i.Users.FindByID(key.UserID)
+	if err != nil || user == nil {
+		described.Active = false
+		return described, nil
+	}
+	described.Scope = oauth.FormatScope(oauth.Intersect(key.Scopes, user.EffectivePermissions()))
+
+	return described, nil
+}
@@ -0,0 +1,91 @@
+package introspection
+
+import (
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func newTestInspector(t *testing.T) *Inspector {
+	db, err := persistence.Connect()
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+
+	return &Inspector{
+		JWT:           shared.NewJWTManager("test-secret", time.Hour, 24*time.Hour),
+		Revocations:   persistence.NewMemoryTokenRevocationRepository(),
+		RefreshTokens: persistence.NewRefreshTokenRepository(db),
+		APIKeys:       persistence.NewAPIKeyRepository(db),
+		Users:         persistence.NewUserRepository(db),
+	}
+}
+
+func TestInspector_AccessToken(t *testing.T) {
+	inspector := newTestInspector(t)
+	userID := uuid.New()
+
+	pair, _ := inspector.JWT.GeneratePair(shared.TokenSubject{UserID: userID, Permissions: []string{"profile:read"}}, uuid.New())
+
+	token, err := inspector.Inspect(pair.Token, "")
+	if err != nil || !token.Active || token.Type != oauth.TokenTypeAccessToken || token.UserID != userID || token.Scope != "profile:read" {
+		t.Fatalf("Token de acesso descrito incorretamente: %+v (%v)", token, err)
+	}
+
+	inspector.Revocations.Revoke(token.ID, userID, token.ExpiresAt)
+	if token, _ := inspector.Inspect(pair.Token, ""); token.Active {
+		t.Error("Um token revogado não deveria estar ativo")
+	}
+
+	if _, err := inspector.Inspect("não-é-um-token", ""); err != ErrUnknownToken {
+		t.Errorf("Esperava ErrUnknownToken, obteve %v", err)
+	}
+}
+
+func TestInspector_RefreshToken(t *testing.T) {
+	inspector := newTestInspector(t)
+	userID := uuid.New()
+
+	pair, _ := inspector.JWT.GeneratePair(shared.TokenSubject{UserID: userID}, uuid.New())
+	stored := &models.RefreshToken{UserID: userID, FamilyID: pair.FamilyID, TokenID: pair.RefreshTokenID, ExpiresAt: pair.RefreshExpiresAt, ClientID: "spa", Scope: "openid"}
+	inspector.RefreshTokens.Store(stored)
+
+	token, err := inspector.Inspect(pair.RefreshToken, oauth.TokenTypeRefreshToken)
+	if err != nil || !token.Active || token.Type != oauth.TokenTypeRefreshToken || token.ClientID != "spa" || token.Scope != "openid" {
+		t.Fatalf("Refresh token descrito incorretamente: %+v (%v)", token, err)
+	}
+
+	inspector.RefreshTokens.MarkUsed(stored.ID)
+	if token, _ := inspector.Inspect(pair.RefreshToken, ""); token.Active {
+		t.Error("Um refresh token já usado não deveria estar ativo")
+	}
+}
+
+func TestInspector_APIKey(t *testing.T) {
+	inspector := newTestInspector(t)
+
+	user, _ := inspector.Users.Store(&models.User{CPF: "11144477735", FirstName: "Ana", LastName: "Lima", Roles: models.StringList{models.RoleUser}})
+	key := models.APIKeyPrefix + "introspection-test"
+	inspector.APIKeys.Store(&models.APIKey{
+		UserID:  user.ID,
+		Name:    "integração",
+		KeyHash: shared.HashToken(key),
+		Scopes:  models.StringList{models.PermissionProfileRead, models.PermissionUsersRead},
+	})
+
+	token, err := inspector.Inspect(key, "")
+	if err != nil || !token.Active || token.Type != oauth.TokenTypeAPIKey || token.UserID != user.ID {
+		t.Fatalf("Chave de api descrita incorretamente: %+v (%v)", token, err)
+	}
+	if token.Scope != models.PermissionProfileRead {
+		t.Errorf("O escopo deveria se limitar às permissões do usuário, obteve %q", token.Scope)
+	}
+
+	if _, err := inspector.Inspect(models.APIKeyPrefix+"desconhecida", ""); err != ErrUnknownToken {
+		t.Errorf("Esperava ErrUnknownToken, obteve %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package lockout
+
+import (
+	"errors"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// Rule define quantas falhas uma chave tolera antes do bloqueio e a duração do bloqueio.
+// A partir de MaxAttempts falhas, cada nova falha dobra o bloqueio a partir de BaseDelay, até MaxDelay.
+// As falhas são esquecidas após MaxDelay sem novas tentativas malsucedidas.
+type Rule struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// delay calcula o bloqueio correspondente ao número de falhas acumuladas.
+func (r Rule) delay(failures int) time.Duration {
+	if r.MaxAttempts <= 0 || failures < r.MaxAttempts {
+		return 0
+	}
+
+	delay := r.BaseDelay
+	for i := r.MaxAttempts; i < failures && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+	return delay
+}
+
+// Limiter controla as falhas de login por conta e por endereço IP. Não há trava entre requisições: cada chave
+// é gravada com uma atualização condicional no Store, repetida quando outra requisição alterou a mesma chave
+// entre a leitura e a escrita.
+type Limiter struct {
+	Store   repository.LoginAttemptRepository
+	Account Rule
+	Client  Rule
+
+	now func() time.Time
+}
+
+// NewLimiter cria um Limiter com as regras de conta e de cliente informadas.
+func NewLimiter(store repository.LoginAttemptRepository, account Rule, client Rule) *Limiter {
+	return &Limiter{
+		Store:   store,
+		Account: account,
+		Client:  client,
+		now:     time.Now,
+	}
+}
+
+// AccountKey retorna a chave de controle de uma conta.
+func AccountKey(cpf string) string {
+	return "account:" + cpf
+}
+
+// ClientKey retorna a chave de controle de um endereço IP.
+func ClientKey(ip string) string {
+	return "ip:" + ip
+}
+
+// RetryAfter retorna por quanto tempo a conta ou o IP ainda estão bloqueados; zero indica que a tentativa é permitida.
+func (l *Limiter) RetryAfter(cpf, ip string) (time.Duration, error) {
+	return l.retryAfter(cpf, ip, l.now())
+}
+
+// Reserve confere o bloqueio e, se a tentativa for permitida, já a contabiliza como falha, na mesma atualização
+// condicional de cada chave. Assim, requisições paralelas não passam todas pela consulta antes que a primeira falha
+// seja registrada: no máximo MaxAttempts verificações de credenciais ficam em andamento. Bloqueada, nada é
+// contabilizado e o tempo restante é retornado. A tentativa que não terminar em falha de credenciais deve ser
+// devolvida com Release.
+func (l *Limiter) Reserve(cpf, ip string) (time.Duration, error) {
+	now := l.now()
+	retryAfter, err := l.retryAfter(cpf, ip, now)
+	if err != nil || retryAfter > 0 {
+		return retryAfter, err
+	}
+
+	retryAfter, err = l.reserve(AccountKey(cpf), l.Account, now)
+	if err != nil || retryAfter > 0 || ip == "" {
+		return retryAfter, err
+	}
+
+	retryAfter, err = l.reserve(ClientKey(ip), l.Client, now)
+	if err != nil || retryAfter > 0 {
+		// O IP foi bloqueado por outra requisição depois da consulta: a falha já contada para a conta é devolvida
+		if releaseErr := l.release(AccountKey(cpf), l.Account); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}
+	return retryAfter, err
+}
+
+// Release devolve uma tentativa reservada que não foi uma falha de credenciais, como um login bem-sucedido ou
+// um provedor indisponível, e recalcula o bloqueio da conta e do IP com uma falha a menos.
+func (l *Limiter) Release(cpf, ip string) error {
+	if err := l.release(AccountKey(cpf), l.Account); err != nil {
+		return err
+	}
+	if ip != "" {
+		return l.release(ClientKey(ip), l.Client)
+	}
+	return nil
+}
+
+// RecordFailure contabiliza uma falha para a conta e para o IP, aplicando o bloqueio quando necessário.
+func (l *Limiter) RecordFailure(cpf, ip string) error {
+	return l.recordFailure(cpf, ip, l.now())
+}
+
+// RecordSuccess zera as falhas da conta. As falhas do IP são mantidas para que um login válido
+// não libere tentativas contra outras contas.
+func (l *Limiter) RecordSuccess(cpf string) error {
+	return l.Unlock(cpf)
+}
+
+// Unlock remove o bloqueio e as falhas acumuladas da conta.
+func (l *Limiter) Unlock(cpf string) error {
+	return l.Store.Delete(AccountKey(cpf))
+}
+
+func (l *Limiter) retryAfter(cpf, ip string, now time.Time) (time.Duration, error) {
+	var retryAfter time.Duration
+	for _, key := range l.keys(cpf, ip) {
+		attempt, err := l.find(key)
+		if err != nil {
+			return 0, err
+		}
+		if attempt != nil && attempt.IsLocked(now) {
+			if remaining := attempt.LockedUntil.Sub(now); remaining > retryAfter {
+				retryAfter = remaining
+			}
+		}
+	}
+	return retryAfter, nil
+}
+
+func (l *Limiter) recordFailure(cpf, ip string, now time.Time) error {
+	if err := l.fail(AccountKey(cpf), l.Account, now); err != nil {
+		return err
+	}
+	if ip != "" {
+		return l.fail(ClientKey(ip), l.Client, now)
+	}
+	return nil
+}
+
+func (l *Limiter) keys(cpf, ip string) []string {
+	keys := []string{AccountKey(cpf)}
+	if ip != "" {
+		keys = append(keys, ClientKey(ip))
+	}
+	return keys
+}
+
+func (l *Limiter) find(key string) (*models.LoginAttempt, error) {
+	attempt, err := l.Store.Find(key)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return nil, nil
+	}
+	return attempt, err
+}
+
+// update aplica change às tentativas da chave e as grava com uma atualização condicional. Se outra requisição
+// gravou a chave depois da leitura, o registro é relido e change é aplicada de novo. Quando change retorna false,
+// nada é gravado.
+func (l *Limiter) update(key string, change func(attempt *models.LoginAttempt) bool) error {
+	for {
+		attempt, err := l.find(key)
+		if err != nil {
+			return err
+		}
+
+		var saved bool
+		if attempt == nil {
+			attempt = &models.LoginAttempt{Key: key}
+			if !change(attempt) {
+				return nil
+			}
+			saved, err = l.Store.Create(attempt)
+		} else {
+			if !change(attempt) {
+				return nil
+			}
+			saved, err = l.Store.Update(attempt)
+		}
+		if err != nil || saved {
+			return err
+		}
+	}
+}
+
+func (l *Limiter) fail(key string, rule Rule, now time.Time) error {
+	return l.update(key, func(attempt *models.LoginAttempt) bool {
+		addFailure(attempt, rule, now)
+		return true
+	})
+}
+
+// reserve contabiliza a falha apenas se a chave não estiver bloqueada; bloqueada, retorna o tempo restante.
+func (l *Limiter) reserve(key string, rule Rule, now time.Time) (time.Duration, error) {
+	var retryAfter time.Duration
+	err := l.update(key, func(attempt *models.LoginAttempt) bool {
+		if attempt.IsLocked(now) {
+			retryAfter = attempt.LockedUntil.Sub(now)
+			return false
+		}
+		retryAfter = 0
+		addFailure(attempt, rule, now)
+		return true
+	})
+	return retryAfter, err
+}
+
+func (l *Limiter) release(key string, rule Rule) error {
+	return l.update(key, func(attempt *models.LoginAttempt) bool {
+		if attempt.Failures == 0 {
+			return false
+		}
+
+		attempt.Failures--
+		attempt.LockedUntil = time.Time{}
+		if delay := rule.delay(attempt.Failures); delay > 0 {
+			attempt.LockedUntil = attempt.LastFailureAt.Add(delay)
+		}
+		return true
+	})
+}
+
+func addFailure(attempt *models.LoginAttempt, rule Rule, now time.Time) {
+	if !attempt.IsLocked(now) && now.Sub(attempt.LastFailureAt) > rule.MaxDelay {
+		attempt.Failures = 0
+	}
+
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	if delay := rule.delay(attempt.Failures); delay > 0 {
+		attempt.LockedUntil = now.Add(delay)
+	}
+}
@@ -0,0 +1,200 @@
+package lockout
+
+import (
+	"server/src/layers/domain/repository"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(now *time.Time) *Limiter {
+	limiter := NewLimiter(
+		repository.NewMockLoginAttemptRepository(),
+		Rule{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+		Rule{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestRule_Delay(t *testing.T) {
+	rule := Rule{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{2, 0},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 8 * time.Minute},
+		{7, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := rule.delay(tt.failures); got != tt.expected {
+			t.Errorf("failures=%d: expected %s, got %s", tt.failures, tt.expected, got)
+		}
+	}
+}
+
+func TestLimiter_LocksAccountAfterMaxAttempts(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+
+	for i := 0; i < 2; i++ {
+		limiter.RecordFailure("12345678900", "10.0.0.1")
+	}
+
+	if retryAfter, _ := limiter.RetryAfter("12345678900", "10.0.0.1"); retryAfter != 0 {
+		t.Fatalf("Expected no lock before max attempts, got %s", retryAfter)
+	}
+
+	limiter.RecordFailure("12345678900", "10.0.0.1")
+
+	retryAfter, err := limiter.RetryAfter("12345678900", "10.0.0.2")
+	if err != nil || retryAfter != time.Minute {
+		t.Fatalf("Expected account locked for 1m from any IP, got %s (%v)", retryAfter, err)
+	}
+
+	if retryAfter, _ := limiter.RetryAfter("98765432100", "10.0.0.1"); retryAfter != 0 {
+		t.Errorf("Expected other accounts from the same IP to be allowed, got %s", retryAfter)
+	}
+
+	now = now.Add(time.Minute)
+	if retryAfter, _ := limiter.RetryAfter("12345678900", "10.0.0.1"); retryAfter != 0 {
+		t.Errorf("Expected lock to expire, got %s", retryAfter)
+	}
+
+	limiter.RecordFailure("12345678900", "10.0.0.1")
+	if retryAfter, _ := limiter.RetryAfter("12345678900", ""); retryAfter != 2*time.Minute {
+		t.Errorf("Expected backoff to double, got %s", retryAfter)
+	}
+}
+
+func TestLimiter_LocksClientAcrossAccounts(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+
+	for i := 0; i < 5; i++ {
+		limiter.RecordFailure(string(rune('a'+i)), "10.0.0.1")
+	}
+
+	if retryAfter, _ := limiter.RetryAfter("outra-conta", "10.0.0.1"); retryAfter != time.Minute {
+		t.Errorf("Expected IP to be locked, got %s", retryAfter)
+	}
+}
+
+func TestLimiter_UnlockAndForget(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+
+	for i := 0; i < 3; i++ {
+		limiter.RecordFailure("12345678900", "")
+	}
+
+	if err := limiter.Unlock("12345678900"); err != nil {
+		t.Fatalf("Failed to unlock: %v", err)
+	}
+
+	if retryAfter, _ := limiter.RetryAfter("12345678900", ""); retryAfter != 0 {
+		t.Errorf("Expected account to be unlocked, got %s", retryAfter)
+	}
+
+	limiter.RecordFailure("12345678900", "")
+	limiter.RecordFailure("12345678900", "")
+	now = now.Add(11 * time.Minute)
+	limiter.RecordFailure("12345678900", "")
+
+	if retryAfter, _ := limiter.RetryAfter("12345678900", ""); retryAfter != 0 {
+		t.Errorf("Expected old failures to be forgotten, got %s", retryAfter)
+	}
+}
+
+func TestLimiter_ReserveIsAtomic(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+
+	// Reservas simultâneas: só MaxAttempts passam antes que o bloqueio seja aplicado
+	var wg sync.WaitGroup
+	var allowed int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if retryAfter, err := limiter.Reserve("12345678900", "10.0.0.1"); err == nil && retryAfter == 0 {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 3 {
+		t.Errorf("Expected exactly 3 reserved attempts, got %d", allowed)
+	}
+	if retryAfter, _ := limiter.RetryAfter("12345678900", ""); retryAfter != time.Minute {
+		t.Errorf("Expected account to be locked after the reservations, got %s", retryAfter)
+	}
+}
+
+func TestLimiter_ReserveAcrossInstances(t *testing.T) {
+	now := time.Now()
+	store := repository.NewMockLoginAttemptRepository()
+	rule := Rule{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+	// Instâncias distintas sobre o mesmo Store, como réplicas do servidor, dependem só das atualizações condicionais
+	limiters := make([]*Limiter, 4)
+	for i := range limiters {
+		limiters[i] = NewLimiter(store, rule, rule)
+		limiters[i].now = func() time.Time { return now }
+	}
+
+	var wg sync.WaitGroup
+	var allowed int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(limiter *Limiter) {
+			defer wg.Done()
+			if retryAfter, err := limiter.Reserve("12345678900", ""); err == nil && retryAfter == 0 {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}(limiters[i%len(limiters)])
+	}
+	wg.Wait()
+
+	if allowed != 3 {
+		t.Errorf("Expected exactly 3 reserved attempts, got %d", allowed)
+	}
+}
+
+func TestLimiter_Release(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+
+	limiter.RecordFailure("12345678900", "10.0.0.1")
+	limiter.RecordFailure("12345678900", "10.0.0.1")
+
+	// A reserva que atinge o limite bloqueia a conta até ser devolvida
+	if retryAfter, err := limiter.Reserve("12345678900", "10.0.0.1"); err != nil || retryAfter != 0 {
+		t.Fatalf("Expected the attempt to be reserved, got %s (%v)", retryAfter, err)
+	}
+	if retryAfter, _ := limiter.RetryAfter("12345678900", ""); retryAfter != time.Minute {
+		t.Fatalf("Expected the reservation to count as a failure, got %s", retryAfter)
+	}
+
+	if err := limiter.Release("12345678900", "10.0.0.1"); err != nil {
+		t.Fatalf("Failed to release: %v", err)
+	}
+	if retryAfter, _ := limiter.RetryAfter("12345678900", "10.0.0.1"); retryAfter != 0 {
+		t.Errorf("Expected the released attempt not to lock, got %s", retryAfter)
+	}
+
+	// Devolvida a reserva, a próxima falha volta a ser a terceira
+	limiter.RecordFailure("12345678900", "10.0.0.1")
+	if retryAfter, _ := limiter.RetryAfter("12345678900", ""); retryAfter != time.Minute {
+		t.Errorf("Expected the third failure to lock for 1m, got %s", retryAfter)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// APIKeyPrefix identifica as chaves de api emitidas por este servidor
+const APIKeyPrefix = "ak_"
+
+// APIKey é uma chave de acesso pessoal usada por scripts e integrações no lugar do cpf e da senha.
+// Apenas o hash da chave é armazenado; Prefix guarda o início da chave para que o usuário a identifique.
+type APIKey struct {
+	Base
+	UserID     uuid.UUID  `json:"-" gorm:"type:uuid;index"`
+	Name       string     `json:"Name"`
+	Prefix     string     `json:"Prefix"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex"`
+	Scopes     StringList `json:"Scopes"`
+	ExpiresAt  *time.Time `json:"ExpiresAt"`
+	LastUsedAt *time.Time `json:"LastUsedAt"`
+	RevokedAt  *time.Time `json:"RevokedAt"`
+}
+
+// IsActive informa se a chave não foi revogada e não expirou.
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
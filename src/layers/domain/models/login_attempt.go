@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LoginAttempt acumula as falhas de autenticação de uma chave (conta ou endereço IP).
+// Revision muda a cada gravação e permite atualizar o registro apenas se ninguém o alterou desde a leitura.
+type LoginAttempt struct {
+	Base
+	Key           string `gorm:"uniqueIndex"`
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+	Revision      int
+}
+
+// IsLocked informa se a chave está bloqueada no instante informado.
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
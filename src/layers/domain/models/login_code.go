@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// LoginCode registra um pedido de login sem senha. O link e o código de 6 dígitos são enviados juntos por e-mail
+// e qualquer um dos dois consome o pedido. Apenas os hashes são armazenados.
+type LoginCode struct {
+	Base
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	TokenHash string    `gorm:"uniqueIndex"`
+	CodeHash  string
+	Attempts  int // códigos errados informados para este pedido
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// IsUsable informa se o pedido ainda não foi consumido e não expirou.
+func (c *LoginCode) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// OAuthAuthorizationCode é o código de uso único emitido pelo /oauth/authorize e trocado por tokens no /oauth/token.
+// Apenas o hash do código é armazenado, junto com o desafio PKCE apresentado pelo cliente
+// e os dados do OpenID Connect (nonce e momento do login) repassados ao ID token.
+type OAuthAuthorizationCode struct {
+	Base
+	CodeHash            string    `gorm:"uniqueIndex"`
+	ClientID            string    `gorm:"index"`
+	UserID              uuid.UUID `gorm:"type:uuid;index"`
+	RedirectURI         string
+	Scope               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+	AuthTime            time.Time
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+}
+
+// IsUsable informa se o código ainda não foi usado e não expirou.
+func (c *OAuthAuthorizationCode) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt)
+}
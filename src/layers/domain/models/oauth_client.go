@@ -0,0 +1,24 @@
+package models
+
+// OAuthClient é uma aplicação autorizada a obter tokens pelo fluxo OAuth 2.0.
+// Clientes públicos (SPAs e aplicativos) não têm segredo e dependem do PKCE; apenas o hash do segredo é armazenado.
+type OAuthClient struct {
+	Base
+	ClientID     string     `json:"ClientID" gorm:"uniqueIndex"`
+	SecretHash   string     `json:"-"`
+	Name         string     `json:"Name"`
+	RedirectURIs StringList `json:"RedirectURIs"`
+	Scopes       StringList `json:"Scopes"`
+	GrantTypes   StringList `json:"GrantTypes"`
+	Public       bool       `json:"Public"`
+}
+
+// AllowsGrant informa se o cliente pode usar o tipo de concessão informado.
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return c.GrantTypes.Contains(grantType)
+}
+
+// AllowsRedirectURI informa se a URI de retorno foi cadastrada; a comparação é exata, sem curingas.
+func (c *OAuthClient) AllowsRedirectURI(redirectURI string) bool {
+	return c.RedirectURIs.Contains(redirectURI)
+}
@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// OAuthConsent registra os escopos que o usuário já autorizou para um cliente.
+type OAuthConsent struct {
+	Base
+	UserID   uuid.UUID  `json:"-" gorm:"type:uuid;uniqueIndex:idx_oauth_consent_user_client"`
+	ClientID string     `json:"ClientID" gorm:"uniqueIndex:idx_oauth_consent_user_client"`
+	Scopes   StringList `json:"Scopes"`
+}
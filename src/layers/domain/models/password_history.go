@@ -0,0 +1,10 @@
+package models
+
+import "github.com/google/uuid"
+
+// PasswordHistory guarda o hash de uma senha anterior do usuário, para impedir sua reutilização.
+type PasswordHistory struct {
+	Base
+	UserID   uuid.UUID `gorm:"type:uuid;index"`
+	Password string
+}
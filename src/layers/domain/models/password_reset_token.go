@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// PasswordResetToken registra uma solicitação de redefinição de senha. Apenas o hash do token é armazenado.
+type PasswordResetToken struct {
+	Base
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	TokenHash string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// IsUsable informa se o token ainda não foi usado e não expirou.
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
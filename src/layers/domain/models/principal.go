@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+// ErrAccessDenied indica que o principal não tem permissão para executar a operação.
+var ErrAccessDenied = errors.New("acesso negado")
+
+// Principal representa o usuário autenticado que originou a requisição.
+type Principal struct {
+	UserID      uuid.UUID
+	TenantID    string
+	Roles       []string
+	Permissions []string
+	TokenID     string
+	FamilyID    uuid.UUID // família de refresh tokens do token de acesso; identifica a sessão
+	Scope       string    // escopo que restringe o token; vazio concede o acesso completo
+	APIKeyID    string    // preenchido quando a requisição foi autenticada por uma chave de api
+	ClientID    string    // cliente OAuth que obteve o token, quando emitido pelo /oauth/token
+	AuthTime    time.Time // momento em que o usuário se autenticou; zero nas chaves de api
+}
+
+// HasRole informa se o principal possui o papel informado.
+func (p *Principal) HasRole(role string) bool {
+	return StringList(p.Roles).Contains(role)
+}
+
+// HasPermission informa se o principal possui a permissão informada.
+func (p *Principal) HasPermission(permission string) bool {
+	return StringList(p.Permissions).Contains(permission)
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RecoveryCode é um código de uso único que substitui o segundo fator quando o autenticador não está disponível.
+// Apenas o hash do código é armazenado.
+type RecoveryCode struct {
+	Base
+	UserID   uuid.UUID `gorm:"type:uuid;index"`
+	CodeHash string
+	UsedAt   *time.Time
+}
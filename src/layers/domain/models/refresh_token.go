@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RefreshToken registra um refresh token emitido e a família de tokens à qual pertence.
+// Cada uso gera um novo registro na mesma família; reapresentar um token já usado revoga a família inteira.
+type RefreshToken struct {
+	Base
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	FamilyID  uuid.UUID `gorm:"type:uuid;index"`
+	TokenID   string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	// ClientID e Scope identificam os tokens emitidos para um cliente OAuth; vazios nos tokens do próprio servidor
+	ClientID string `gorm:"index"`
+	Scope    string
+	// AuthTime é o momento do login que iniciou a família, mantido em cada rotação
+	AuthTime time.Time
+}
+
+// IsExpired informa se o refresh token já passou da data de expiração.
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
+
+// AuthenticatedAt retorna o momento do login que iniciou a família; registros anteriores ao campo usam a data de criação.
+func (t *RefreshToken) AuthenticatedAt() time.Time {
+	if t.AuthTime.IsZero() {
+		return t.CreatedAt
+	}
+	return t.AuthTime
+}
+
+// IsConsumed informa se o refresh token já foi usado ou revogado.
+func (t *RefreshToken) IsConsumed() bool {
+	return t.UsedAt != nil || t.RevokedAt != nil
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RevokedToken registra a revogação de um token específico (pelo jti) ou, quando TokenID está vazio,
+// de todos os tokens do usuário emitidos antes de IssuedBeforeMilli (em milissegundos).
+type RevokedToken struct {
+	Base
+	TokenID           string    `gorm:"index"`
+	UserID            uuid.UUID `gorm:"type:uuid;index"`
+	IssuedBeforeMilli int64
+	ExpiresAt         time.Time `gorm:"index"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "sort"
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+	// RoleBackoffice é o papel de operadores de baixo risco, que podem entrar sem senha por link ou código enviado por e-mail
+	RoleBackoffice = "backoffice"
+
+	PermissionUsersRead    = "users:read"
+	PermissionUsersWrite   = "users:write"
+	PermissionRolesWrite   = "roles:write"
+	PermissionProfileRead  = "profile:read"
+	PermissionProfileWrite = "profile:write"
+	PermissionClientsWrite = "clients:write"
+	// PermissionTokensIntrospect e PermissionTokensRevoke liberam a consulta e a revogação de tokens de qualquer usuário
+	PermissionTokensIntrospect = "tokens:introspect"
+	PermissionTokensRevoke     = "tokens:revoke"
+	// PermissionSessionsRead e PermissionSessionsWrite liberam as sessões e o histórico de login de qualquer usuário
+	PermissionSessionsRead  = "sessions:read"
+	PermissionSessionsWrite = "sessions:write"
+	// PermissionPasswordlessSignIn libera o login por link ou código enviado ao e-mail confirmado
+	PermissionPasswordlessSignIn = "signin:passwordless"
+	// PermissionMetricsRead libera as métricas operacionais do servidor
+	PermissionMetricsRead = "metrics:read"
+)
+
+// RolePermissions relaciona cada papel às permissões que ele concede.
+var RolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionRolesWrite,
+		PermissionProfileRead,
+		PermissionProfileWrite,
+		PermissionClientsWrite,
+		PermissionTokensIntrospect,
+		PermissionTokensRevoke,
+		PermissionSessionsRead,
+		PermissionSessionsWrite,
+		PermissionMetricsRead,
+	},
+	RoleUser: {
+		PermissionProfileRead,
+		PermissionProfileWrite,
+	},
+	RoleBackoffice: {
+		PermissionProfileRead,
+		PermissionProfileWrite,
+		PermissionPasswordlessSignIn,
+	},
+}
+
+// IsKnownRole informa se o papel está definido em RolePermissions.
+func IsKnownRole(role string) bool {
+	_, exists := RolePermissions[role]
+	return exists
+}
+
+// PermissionsForRoles retorna as permissões concedidas pelos papéis somadas às permissões extras, sem repetição.
+func PermissionsForRoles(roles []string, extra []string) []string {
+	unique := make(map[string]struct{})
+	for _, role := range roles {
+		for _, permission := range RolePermissions[role] {
+			unique[permission] = struct{}{}
+		}
+	}
+	for _, permission := range extra {
+		unique[permission] = struct{}{}
+	}
+
+	permissions := make([]string, 0, len(unique))
+	for permission := range unique {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
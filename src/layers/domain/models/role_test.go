@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestNewUser_DefaultRole(t *testing.T) {
+	user, err := NewUser("83103569009", "Lucas", "Albuquerque", "password123")
+	if err != nil {
+		t.Fatalf("Falha ao criar usuário com dados válidos: %v", err)
+	}
+
+	if !user.Roles.Contains(RoleUser) {
+		t.Errorf("Esperado papel %s, mas recebeu %v", RoleUser, user.Roles)
+	}
+
+	if StringList(user.EffectivePermissions()).Contains(PermissionUsersRead) {
+		t.Error("Usuário comum não deveria ter a permissão users:read")
+	}
+}
+
+func TestUser_EffectivePermissions(t *testing.T) {
+	user := &User{Roles: StringList{RoleUser}, Permissions: StringList{"reports:read"}}
+
+	permissions := StringList(user.EffectivePermissions())
+	for _, expected := range []string{PermissionProfileRead, PermissionProfileWrite, "reports:read"} {
+		if !permissions.Contains(expected) {
+			t.Errorf("Esperada a permissão %s em %v", expected, permissions)
+		}
+	}
+}
+
+func TestStringList_ScanAndValue(t *testing.T) {
+	list := StringList{RoleAdmin, RoleUser}
+
+	value, err := list.Value()
+	if err != nil || value != "admin,user" {
+		t.Fatalf("Esperado 'admin,user', mas recebeu %v (%v)", value, err)
+	}
+
+	var scanned StringList
+	if err := scanned.Scan("admin, user,"); err != nil {
+		t.Fatalf("Falha ao ler a lista: %v", err)
+	}
+	if len(scanned) != 2 || scanned[0] != RoleAdmin || scanned[1] != RoleUser {
+		t.Errorf("Lista lida incorretamente: %v", scanned)
+	}
+}
+
+func TestPasswordlessSignIn_OnlyBackoffice(t *testing.T) {
+	backoffice := &User{Roles: StringList{RoleUser, RoleBackoffice}}
+	if !StringList(backoffice.EffectivePermissions()).Contains(PermissionPasswordlessSignIn) {
+		t.Errorf("Esperada a permissão %s para o papel %s", PermissionPasswordlessSignIn, RoleBackoffice)
+	}
+
+	for _, role := range []string{RoleUser, RoleAdmin} {
+		user := &User{Roles: StringList{role}}
+		if StringList(user.EffectivePermissions()).Contains(PermissionPasswordlessSignIn) {
+			t.Errorf("Papel %s não deveria permitir o login sem senha", role)
+		}
+	}
+}
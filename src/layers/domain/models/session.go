@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Session registra o login que iniciou uma família de refresh tokens e o dispositivo que a utiliza.
+// A sessão está ativa enquanto a família tiver um refresh token válido e ela não for revogada.
+type Session struct {
+	Base
+	UserID     uuid.UUID  `json:"-" gorm:"type:uuid;index"`
+	FamilyID   uuid.UUID  `json:"-" gorm:"type:uuid;uniqueIndex"`
+	IP         string     `json:"IP"`
+	UserAgent  string     `json:"UserAgent"`
+	LastSeenAt time.Time  `json:"LastSeenAt"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// IsRevoked informa se a sessão foi encerrada pelo usuário ou por um administrador.
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import "github.com/google/uuid"
+
+// Métodos de autenticação registrados no histórico de logins
+const (
+	SignInMethodPassword     = "password"
+	SignInMethodTOTP         = "totp"
+	SignInMethodRecoveryCode = "recovery_code"
+	SignInMethodMagicLink    = "magic_link"
+	SignInMethodEmailCode    = "email_code"
+	SignInMethodLDAP         = "ldap"
+)
+
+// Motivos das tentativas de login recusadas
+const (
+	SignInFailureInvalidPassword = "invalid_password"
+	SignInFailureInvalidCode     = "invalid_code"
+)
+
+// SignInEvent registra uma tentativa de login em uma conta existente, bem-sucedida ou não.
+// Tentativas com cpfs inexistentes não pertencem a nenhuma conta e não são registradas.
+type SignInEvent struct {
+	Base
+	UserID        uuid.UUID `json:"-" gorm:"type:uuid;index"`
+	Method        string    `json:"Method"`
+	Success       bool      `json:"Success"`
+	FailureReason string    `json:"FailureReason,omitempty"`
+	IP            string    `json:"IP"`
+	UserAgent     string    `json:"UserAgent"`
+	// SessionID identifica a sessão criada pelo login bem-sucedido
+	SessionID *uuid.UUID `json:"SessionID,omitempty" gorm:"type:uuid"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// StringList armazena uma lista de textos em uma única coluna, separados por vírgula.
+type StringList []string
+
+// Value converte a lista para o formato gravado no banco de dados.
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+// Scan lê a lista a partir do valor gravado no banco de dados.
+func (l *StringList) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return errors.New("tipo incompatível com StringList")
+	}
+
+	*l = nil
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// GormDataType informa ao GORM o tipo da coluna.
+func (StringList) GormDataType() string {
+	return "text"
+}
+
+// Contains informa se a lista possui o item informado.
+func (l StringList) Contains(item string) bool {
+	for _, i := range l {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"server/src/commons/shared"
+	"time"
 )
 
 // User representa o modelo de domínio para um usuário.
@@ -12,6 +13,22 @@ type User struct {
 	Password  string `json:"-"`
 	FirstName string `json:"FirstName"`
 	LastName  string `json:"LastName"`
+	// Email é opcional e guardado normalizado; só é aceito no login depois de confirmado
+	Email           string     `json:"Email,omitempty" gorm:"uniqueIndex:idx_users_email,where:email <> ''"`
+	EmailVerifiedAt *time.Time `json:"EmailVerifiedAt,omitempty"`
+	TenantID        string     `json:"TenantID,omitempty" gorm:"index"`
+	Roles           StringList `json:"Roles"`
+	Permissions     StringList `json:"-"` // permissões concedidas diretamente, além das dos papéis
+	// TOTPSecret guarda o segredo do segundo fator, pendente até TOTPEnabled ser confirmado
+	TOTPSecret   string `json:"-"`
+	TOTPEnabled  bool   `json:"TwoFactorEnabled"`
+	TOTPLastStep int64  `json:"-"` // último intervalo TOTP aceito, para impedir a reutilização de um código
+	// PasswordChangedAt registra a última troca de senha feita pelo usuário; o rehash no login não a altera
+	PasswordChangedAt time.Time `json:"PasswordChangedAt"`
+	// AuthProvider é o provedor externo que autentica o usuário, vazio para contas com senha local;
+	// ExternalID identifica a conta no provedor (no LDAP, o DN da entrada)
+	AuthProvider string `json:"AuthProvider,omitempty"`
+	ExternalID   string `json:"-" gorm:"index"`
 }
 
 // NewUser é um construtor para o modelo User.
@@ -21,15 +38,76 @@ func NewUser(cpf, firstName, lastName, password string) (*User, error) {
 	}
 
 	return &User{
-		CPF:       cpf,
-		Password:  password,
-		FirstName: firstName,
-		LastName:  lastName,
+		CPF:               cpf,
+		Password:          password,
+		FirstName:         firstName,
+		LastName:          lastName,
+		Roles:             StringList{RoleUser},
+		PasswordChangedAt: time.Now(),
 	}, nil
 }
 
+// NewExternalUser é um construtor para usuários provisionados por um provedor externo, sem senha local.
+func NewExternalUser(cpf, firstName, lastName, provider, externalID string) (*User, error) {
+	if err := validateProfileFields(cpf, firstName, lastName); err != nil {
+		return nil, err
+	}
+	if provider == "" {
+		return nil, errors.New("Provedor deve ser informado")
+	}
+
+	return &User{
+		CPF:          cpf,
+		FirstName:    firstName,
+		LastName:     lastName,
+		Roles:        StringList{RoleUser},
+		AuthProvider: provider,
+		ExternalID:   externalID,
+	}, nil
+}
+
+// IsExternal informa se o usuário é autenticado por um provedor externo em vez da senha local.
+func (u *User) IsExternal() bool {
+	return u.AuthProvider != ""
+}
+
+// PasswordExpired informa se a senha ultrapassou a idade máxima; maxAge zero desativa a expiração.
+// Usuários sem troca registrada usam a data de cadastro; usuários externos não têm senha local para expirar.
+func (u *User) PasswordExpired(maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 || u.IsExternal() {
+		return false
+	}
+
+	changedAt := u.PasswordChangedAt
+	if changedAt.IsZero() {
+		changedAt = u.CreatedAt
+	}
+	return now.Sub(changedAt) > maxAge
+}
+
+// EmailVerified informa se o usuário tem um e-mail confirmado, que pode ser usado como identificador no login.
+func (u *User) EmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
+}
+
+// EffectivePermissions retorna todas as permissões do usuário, incluindo as herdadas dos papéis.
+func (u *User) EffectivePermissions() []string {
+	return PermissionsForRoles(u.Roles, u.Permissions)
+}
+
 // validateUserFields verifica se os campos obrigatórios estão preenchidos e se o CPF é válido.
 func validateUserFields(cpf, firstName, lastName, password string) error {
+	if err := validateProfileFields(cpf, firstName, lastName); err != nil {
+		return err
+	}
+	if password == "" {
+		return errors.New("Senha deve ser informada")
+	}
+	return nil
+}
+
+// validateProfileFields verifica o cpf, o nome e o sobrenome, comuns a usuários locais e externos.
+func validateProfileFields(cpf, firstName, lastName string) error {
 	if cpf == "" {
 		return errors.New("Cpf deve ser informado")
 	}
@@ -42,8 +120,5 @@ func validateUserFields(cpf, firstName, lastName, password string) error {
 	if lastName == "" {
 		return errors.New("Sobrenome deve ser informado")
 	}
-	if password == "" {
-		return errors.New("Senha deve ser informada")
-	}
 	return nil
 }
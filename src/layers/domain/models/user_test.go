@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewUser(t *testing.T) {
@@ -43,6 +44,54 @@ func TestNewUser(t *testing.T) {
 	}
 }
 
+func TestNewExternalUser(t *testing.T) {
+	validCPF := "83103569009"
+
+	user, err := NewExternalUser(validCPF, "Lucas", "Albuquerque", "ldap", "uid=lucas,ou=people,dc=example,dc=com")
+	if err != nil {
+		t.Fatalf("Falha ao criar usuário externo com dados válidos: %v", err)
+	}
+	if !user.IsExternal() || user.Password != "" {
+		t.Error("Usuário externo não deveria ter senha local")
+	}
+	if user.PasswordExpired(time.Hour, time.Now()) {
+		t.Error("Usuário externo não tem senha local para expirar")
+	}
+
+	_, err = NewExternalUser(validCPF, "Lucas", "Albuquerque", "", "")
+	if err == nil || err.Error() != "Provedor deve ser informado" {
+		t.Error("Esperado erro de provedor não informado")
+	}
+
+	_, err = NewExternalUser("invalidCPF", "Lucas", "Albuquerque", "ldap", "")
+	if err == nil || err.Error() != "Cpf com formato inválido" {
+		t.Error("Esperado erro de formato de CPF inválido")
+	}
+}
+
+func TestUser_PasswordExpired(t *testing.T) {
+	now := time.Now()
+	maxAge := 90 * 24 * time.Hour
+
+	recent := &User{PasswordChangedAt: now.Add(-24 * time.Hour)}
+	if recent.PasswordExpired(maxAge, now) {
+		t.Error("Senha trocada ontem não deveria estar expirada")
+	}
+
+	old := &User{PasswordChangedAt: now.Add(-100 * 24 * time.Hour)}
+	if !old.PasswordExpired(maxAge, now) {
+		t.Error("Senha trocada há 100 dias deveria estar expirada")
+	}
+	if old.PasswordExpired(0, now) {
+		t.Error("Sem idade máxima a senha nunca expira")
+	}
+
+	legacy := &User{Base: Base{CreatedAt: now.Add(-100 * 24 * time.Hour)}}
+	if !legacy.PasswordExpired(maxAge, now) {
+		t.Error("Sem data de troca deveria usar a data de cadastro")
+	}
+}
+
 func TestValidateUserFields(t *testing.T) {
 	// Estes testes são similares aos testes de `NewUser`, já que `NewUser` chama `validateUserFields`.
 	// No entanto, é uma boa prática testar funções isoladamente para garantir que cada uma funcione corretamente.
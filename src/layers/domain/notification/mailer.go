@@ -0,0 +1,13 @@
+package notification
+
+// Message é um e-mail em texto puro pronto para envio.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer entrega mensagens de e-mail. O transporte (SMTP, arquivo ou memória) é escolhido na configuração.
+type Mailer interface {
+	Send(message Message) error
+}
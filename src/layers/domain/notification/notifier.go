@@ -0,0 +1,21 @@
+package notification
+
+import (
+	"server/src/layers/domain/models"
+	"time"
+)
+
+// PasswordResetNotifier entrega ao usuário o token de redefinição de senha.
+type PasswordResetNotifier interface {
+	NotifyPasswordReset(user *models.User, token string, expiresAt time.Time) error
+}
+
+// EmailVerificationNotifier entrega ao usuário o link de confirmação do e-mail cadastrado.
+type EmailVerificationNotifier interface {
+	NotifyEmailVerification(user *models.User, link string, expiresAt time.Time) error
+}
+
+// LoginCodeNotifier entrega ao usuário o link e o código de uso único do login sem senha.
+type LoginCodeNotifier interface {
+	NotifyLoginCode(user *models.User, code, link string, expiresAt time.Time) error
+}
@@ -0,0 +1,19 @@
+package oauth
+
+// Error é um erro do protocolo OAuth, devolvido ao cliente no formato da RFC 6749.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// NewError cria um erro OAuth com o código e a descrição informados.
+func NewError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
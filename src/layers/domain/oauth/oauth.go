@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+// Tipos de concessão suportados pelo endpoint /oauth/token
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+
+	ResponseTypeCode = "code"
+
+	// CodeChallengeS256 é o único método PKCE aceito; "plain" não protege contra a interceptação do código
+	CodeChallengeS256 = "S256"
+)
+
+// Tipos de token informados no token_type_hint e nas respostas de introspecção (RFC 7662 e RFC 7009).
+// TokenTypeAPIKey identifica as chaves de api, os tokens opacos deste servidor.
+const (
+	TokenTypeAccessToken  = "access_token"
+	TokenTypeRefreshToken = "refresh_token"
+	TokenTypeAPIKey       = "api_key"
+)
+
+// Códigos de erro definidos pela RFC 6749
+const (
+	ErrInvalidRequest          = "invalid_request"
+	ErrInvalidClient           = "invalid_client"
+	ErrInvalidGrant            = "invalid_grant"
+	ErrUnauthorizedClient      = "unauthorized_client"
+	ErrUnsupportedGrantType    = "unsupported_grant_type"
+	ErrUnsupportedResponseType = "unsupported_response_type"
+	ErrInvalidScope            = "invalid_scope"
+	ErrAccessDenied            = "access_denied"
+	ErrServerError             = "server_error"
+)
+
+// ParseScope separa o parâmetro scope (valores separados por espaço), ignorando repetições.
+func ParseScope(scope string) []string {
+	seen := make(map[string]struct{})
+	var scopes []string
+	for _, value := range strings.Fields(scope) {
+		if _, exists := seen[value]; exists {
+			continue
+		}
+		seen[value] = struct{}{}
+		scopes = append(scopes, value)
+	}
+	return scopes
+}
+
+// FormatScope junta os escopos no formato do parâmetro scope.
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// ContainsAll informa se todos os escopos requested estão em granted.
+func ContainsAll(granted, requested []string) bool {
+	allowed := make(map[string]struct{}, len(granted))
+	for _, scope := range granted {
+		allowed[scope] = struct{}{}
+	}
+	for _, scope := range requested {
+		if _, exists := allowed[scope]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect retorna os escopos de values que também estão em allowed, na ordem de values.
+func Intersect(values, allowed []string) []string {
+	var result []string
+	for _, value := range values {
+		if ContainsAll(allowed, []string{value}) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// CodeChallenge calcula o code_challenge S256 de um code_verifier (RFC 7636).
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyCodeChallenge confere o code_verifier apresentado no /oauth/token com o desafio guardado no código.
+func VerifyCodeChallenge(verifier, challenge, method string) bool {
+	if method != CodeChallengeS256 || !validVerifier(verifier) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(CodeChallenge(verifier)), []byte(challenge)) == 1
+}
+
+// validVerifier aplica o tamanho (43 a 128) e o alfabeto do code_verifier definidos na RFC 7636
+func validVerifier(verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, r := range verifier {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case r == '-', r == '.', r == '_', r == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScope(t *testing.T) {
+	scopes := ParseScope("  users:read profile:read users:read ")
+	if FormatScope(scopes) != "users:read profile:read" {
+		t.Errorf("Escopos inesperados: %v", scopes)
+	}
+
+	if len(ParseScope("")) != 0 {
+		t.Error("Escopo vazio deveria resultar em lista vazia")
+	}
+}
+
+func TestContainsAllAndIntersect(t *testing.T) {
+	granted := []string{"profile:read", "users:read"}
+
+	if !ContainsAll(granted, []string{"users:read"}) {
+		t.Error("users:read deveria estar contido")
+	}
+	if ContainsAll(granted, []string{"users:read", "roles:write"}) {
+		t.Error("roles:write não deveria estar contido")
+	}
+	if got := Intersect([]string{"roles:write", "profile:read"}, granted); len(got) != 1 || got[0] != "profile:read" {
+		t.Errorf("Interseção inesperada: %v", got)
+	}
+}
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// Exemplo do apêndice B da RFC 7636
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if CodeChallenge(verifier) != challenge {
+		t.Fatalf("Desafio inesperado: %s", CodeChallenge(verifier))
+	}
+
+	tests := []struct {
+		name     string
+		verifier string
+		method   string
+		expected bool
+	}{
+		{"verificador correto", verifier, CodeChallengeS256, true},
+		{"método plain recusado", verifier, "plain", false},
+		{"verificador diferente", strings.Repeat("a", 43), CodeChallengeS256, false},
+		{"verificador curto", "abc", CodeChallengeS256, false},
+		{"caractere inválido", verifier[:42] + "!", CodeChallengeS256, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyCodeChallenge(tt.verifier, challenge, tt.method); got != tt.expected {
+				t.Errorf("Esperava %v, obteve %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package oauth
+
+import (
+	"server/src/layers/domain/models"
+	"strings"
+)
+
+// Escopos do OpenID Connect. Diferente dos demais escopos, eles não correspondem a permissões do usuário:
+// liberam a emissão do ID token e das claims de identidade no ID token e no /userinfo.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeCPF     = "cpf"
+)
+
+// Valores do parâmetro prompt do /oauth/authorize
+const (
+	PromptNone    = "none"
+	PromptConsent = "consent"
+)
+
+// Códigos de erro definidos pelo OpenID Connect Core e, para o /userinfo, pela RFC 6750
+const (
+	ErrLoginRequired     = "login_required"
+	ErrConsentRequired   = "consent_required"
+	ErrInvalidToken      = "invalid_token"
+	ErrInsufficientScope = "insufficient_scope"
+)
+
+// IdentityScopes lista os escopos do OpenID Connect suportados, na ordem publicada no discovery.
+var IdentityScopes = []string{ScopeOpenID, ScopeProfile, ScopeCPF}
+
+// IsIdentityScope informa se o escopo é do OpenID Connect, e não uma permissão.
+func IsIdentityScope(scope string) bool {
+	return ContainsAll(IdentityScopes, []string{scope})
+}
+
+// UserInfo reúne as claims de identidade do usuário, no formato do /userinfo e do ID token.
+type UserInfo struct {
+	Subject    string `json:"sub"`
+	Name       string `json:"name,omitempty"`
+	GivenName  string `json:"given_name,omitempty"`
+	FamilyName string `json:"family_name,omitempty"`
+	CPF        string `json:"cpf,omitempty"`
+}
+
+// UserInfoFor mapeia o usuário para as claims padrão. O escopo profile libera o nome
+// e o escopo cpf libera a claim personalizada com o cpf.
+func UserInfoFor(user *models.User, scopes []string) UserInfo {
+	info := UserInfo{Subject: user.ID.String()}
+
+	if ContainsAll(scopes, []string{ScopeProfile}) {
+		info.GivenName = user.FirstName
+		info.FamilyName = user.LastName
+		info.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	if ContainsAll(scopes, []string{ScopeCPF}) {
+		info.CPF = user.CPF
+	}
+
+	return info
+}
+
+// GrantScopes filtra os escopos que o usuário pode conceder: os do OpenID Connect
+// e as permissões que ele possui, na ordem solicitada.
+func GrantScopes(scopes []string, user *models.User) []string {
+	permissions := user.EffectivePermissions()
+
+	var granted []string
+	for _, scope := range scopes {
+		if IsIdentityScope(scope) || ContainsAll(permissions, []string{scope}) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted
+}
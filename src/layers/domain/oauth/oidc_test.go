@@ -0,0 +1,31 @@
+package oauth
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"testing"
+)
+
+func TestUserInfoFor(t *testing.T) {
+	user := &models.User{FirstName: "Ana", LastName: "Souza", CPF: "52998224725"}
+	user.ID = uuid.New()
+
+	info := UserInfoFor(user, []string{ScopeOpenID})
+	if info.Subject != user.ID.String() || info.Name != "" || info.CPF != "" {
+		t.Errorf("Sem profile e cpf, apenas o sub deveria ser preenchido: %+v", info)
+	}
+
+	info = UserInfoFor(user, []string{ScopeOpenID, ScopeProfile, ScopeCPF})
+	if info.Name != "Ana Souza" || info.GivenName != "Ana" || info.FamilyName != "Souza" || info.CPF != "52998224725" {
+		t.Errorf("Claims de identidade inesperadas: %+v", info)
+	}
+}
+
+func TestGrantScopes(t *testing.T) {
+	user := &models.User{Roles: models.StringList{models.RoleUser}}
+
+	granted := GrantScopes([]string{ScopeOpenID, models.PermissionUsersRead, models.PermissionProfileRead}, user)
+	if FormatScope(granted) != "openid profile:read" {
+		t.Errorf("Escopos concedidos inesperados: %v", granted)
+	}
+}
@@ -0,0 +1,164 @@
+package passwordpolicy
+
+import (
+	"fmt"
+	"server/src/layers/domain/models"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Códigos dos motivos de recusa de uma senha
+const (
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeTooFewClasses = "too_few_character_classes"
+	CodeContainsCPF   = "contains_cpf"
+	CodeContainsName  = "contains_name"
+	CodeBreached      = "breached"
+	CodeReused        = "reused"
+)
+
+// minNameLength evita recusar senhas por conterem partes muito curtas do nome, como "da" ou "de"
+const minNameLength = 3
+
+// BreachedChecker informa se a senha consta em uma base de senhas vazadas.
+type BreachedChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// Policy define as regras aplicadas às novas senhas. MinClasses conta quantos tipos de caracteres
+// (minúsculas, maiúsculas, números e símbolos) a senha deve combinar. Breached é opcional.
+type Policy struct {
+	MinLength  int
+	MaxLength  int
+	MinClasses int
+	Breached   BreachedChecker
+}
+
+// Subject reúne os dados pessoais que não podem aparecer na senha.
+type Subject struct {
+	CPF       string
+	FirstName string
+	LastName  string
+}
+
+// SubjectFromUser extrai os dados pessoais do usuário.
+func SubjectFromUser(user *models.User) Subject {
+	return Subject{CPF: user.CPF, FirstName: user.FirstName, LastName: user.LastName}
+}
+
+// FieldError descreve um motivo de recusa do campo informado.
+type FieldError struct {
+	Field   string `json:"Field"`
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// ValidationError agrupa todos os motivos pelos quais a senha foi recusada.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fieldError.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Check valida a senha do campo informado e retorna um *ValidationError com todos os motivos de recusa.
+// Erros de consulta à base de senhas vazadas são retornados como estão.
+func (p *Policy) Check(field, password string, subject Subject) error {
+	var reasons []FieldError
+	add := func(code, message string) {
+		reasons = append(reasons, FieldError{Field: field, Code: code, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		add(CodeTooShort, fmt.Sprintf("a senha deve ter pelo menos %d caracteres", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(CodeTooLong, fmt.Sprintf("a senha deve ter no máximo %d caracteres", p.MaxLength))
+	}
+	if characterClasses(password) < p.MinClasses {
+		add(CodeTooFewClasses, fmt.Sprintf("a senha deve combinar pelo menos %d tipos de caracteres entre minúsculas, maiúsculas, números e símbolos", p.MinClasses))
+	}
+	if containsCPF(password, subject.CPF) {
+		add(CodeContainsCPF, "a senha não pode conter o cpf")
+	}
+	if containsName(password, subject.FirstName, subject.LastName) {
+		add(CodeContainsName, "a senha não pode conter o nome ou o sobrenome")
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			add(CodeBreached, "a senha aparece em vazamentos conhecidos e não pode ser usada")
+		}
+	}
+
+	if len(reasons) > 0 {
+		return &ValidationError{Errors: reasons}
+	}
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+// containsCPF compara apenas os dígitos, para recusar o cpf com ou sem pontuação
+func containsCPF(password, cpf string) bool {
+	cpfDigits := digitsOnly(cpf)
+	if cpfDigits == "" {
+		return false
+	}
+	return strings.Contains(digitsOnly(password), cpfDigits)
+}
+
+func containsName(password string, names ...string) bool {
+	lowered := strings.ToLower(password)
+	for _, name := range names {
+		for _, part := range strings.Fields(strings.ToLower(name)) {
+			if utf8.RuneCountInString(part) >= minNameLength && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func digitsOnly(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
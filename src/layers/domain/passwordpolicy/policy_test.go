@@ -0,0 +1,79 @@
+package passwordpolicy
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeBreached map[string]bool
+
+func (f fakeBreached) IsBreached(password string) (bool, error) {
+	return f[password], nil
+}
+
+func codes(err error) []string {
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		return nil
+	}
+
+	var result []string
+	for _, fieldError := range validation.Errors {
+		result = append(result, fieldError.Code)
+	}
+	return result
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy := &Policy{
+		MinLength:  10,
+		MaxLength:  64,
+		MinClasses: 3,
+		Breached:   fakeBreached{"Password1234!": true},
+	}
+	subject := Subject{CPF: "529.982.247-25", FirstName: "Maria", LastName: "da Silva"}
+
+	tests := []struct {
+		name     string
+		password string
+		expected []string
+	}{
+		{"senha válida", "Correct-Horse-7", nil},
+		{"curta e simples", "abc", []string{CodeTooShort, CodeTooFewClasses}},
+		{"longa demais", "Aa1!" + string(make([]byte, 70)), []string{CodeTooLong}},
+		{"contém o cpf", "Xy!52998224725", []string{CodeContainsCPF}},
+		{"contém o nome", "Maria#2024xyz", []string{CodeContainsName}},
+		{"contém o sobrenome", "xyz!SILVA2024", []string{CodeContainsName}},
+		{"partes curtas do nome são permitidas", "Da-Sorte-2024", nil},
+		{"vazada", "Password1234!", []string{CodeBreached}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check("Password", tt.password, subject)
+			got := codes(err)
+
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Esperava %v, obteve %v (%v)", tt.expected, got, err)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Esperava %v, obteve %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_FieldName(t *testing.T) {
+	policy := &Policy{MinLength: 8}
+
+	var validation *ValidationError
+	if err := policy.Check("NewPassword", "curta", Subject{}); !errors.As(err, &validation) {
+		t.Fatalf("Esperava ValidationError, obteve %v", err)
+	}
+
+	if validation.Errors[0].Field != "NewPassword" {
+		t.Errorf("Esperava o campo NewPassword, obteve %s", validation.Errors[0].Field)
+	}
+}
@@ -0,0 +1,59 @@
+package policy
+
+import "server/src/layers/domain/models"
+
+// Ações e tipos de recurso usados pelos handlers da camada de serviço
+const (
+	ResourceUser = "user"
+
+	ActionUsersRead   = "users:read"
+	ActionUsersList   = "users:list"
+	ActionUsersUpdate = "users:update"
+)
+
+// DefaultRules são as regras usadas quando nenhum arquivo de políticas é configurado:
+// cada usuário lê e altera o próprio registro com profile:read/profile:write e as permissões users:read/users:write
+// liberam os demais. Tokens e chaves de api com escopo limitado não alcançam o próprio registro sem as permissões de perfil.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:     "owner-can-read",
+			Effect:   Allow,
+			Actions:  []string{ActionUsersRead},
+			Resource: ResourceUser,
+			Condition: Any(
+				All(MustExpr("subject.id == resource.id"), MustExpr("subject.permissions contains "+models.PermissionProfileRead)),
+				MustExpr("subject.permissions contains "+models.PermissionUsersRead),
+			),
+		},
+		{
+			Name:      "reader-can-list",
+			Effect:    Allow,
+			Actions:   []string{ActionUsersList},
+			Resource:  ResourceUser,
+			Condition: MustExpr("subject.permissions contains " + models.PermissionUsersRead),
+		},
+		{
+			Name:     "owner-can-update",
+			Effect:   Allow,
+			Actions:  []string{ActionUsersUpdate},
+			Resource: ResourceUser,
+			Condition: Any(
+				All(MustExpr("subject.id == resource.id"), MustExpr("subject.permissions contains "+models.PermissionProfileWrite)),
+				MustExpr("subject.permissions contains "+models.PermissionUsersWrite),
+			),
+		},
+	}
+}
+
+// UserResource descreve um usuário como recurso avaliado pelas políticas.
+func UserResource(user *models.User) Resource {
+	return Resource{
+		Type: ResourceUser,
+		ID:   user.ID.String(),
+		Attributes: map[string]string{
+			"owner_id":  user.ID.String(),
+			"tenant_id": user.TenantID,
+		},
+	}
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operadores aceitos nas expressões declarativas
+const (
+	opEquals    = "=="
+	opNotEquals = "!="
+	opContains  = "contains"
+)
+
+// expression é uma comparação do tipo "subject.id == resource.owner_id" ou "subject.roles contains admin".
+type expression struct {
+	source   string
+	left     operand
+	operator string
+	right    operand
+}
+
+// operand é uma referência a atributo (subject.* ou resource.*) ou um valor literal.
+type operand struct {
+	scope   string
+	name    string
+	literal string
+}
+
+// Expr interpreta uma expressão declarativa. Atributos disponíveis:
+// subject.id, subject.tenant_id, subject.roles, subject.permissions, resource.id, resource.type e resource.<atributo>.
+// Literais podem vir entre aspas simples.
+func Expr(source string) (Condition, error) {
+	fields := strings.Fields(source)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expressão inválida %q: use <operando> <operador> <operando>", source)
+	}
+
+	switch fields[1] {
+	case opEquals, opNotEquals, opContains:
+	default:
+		return nil, fmt.Errorf("operador desconhecido %q na expressão %q", fields[1], source)
+	}
+
+	return &expression{
+		source:   source,
+		left:     parseOperand(fields[0]),
+		operator: fields[1],
+		right:    parseOperand(fields[2]),
+	}, nil
+}
+
+// MustExpr é como Expr, mas entra em pânico em expressões inválidas. Útil para regras escritas em Go.
+func MustExpr(source string) Condition {
+	condition, err := Expr(source)
+	if err != nil {
+		panic(err)
+	}
+	return condition
+}
+
+func parseOperand(token string) operand {
+	if strings.HasPrefix(token, "'") && strings.HasSuffix(token, "'") && len(token) >= 2 {
+		return operand{literal: strings.Trim(token, "'")}
+	}
+	for _, scope := range []string{"subject", "resource"} {
+		if strings.HasPrefix(token, scope+".") {
+			return operand{scope: scope, name: strings.TrimPrefix(token, scope+".")}
+		}
+	}
+	return operand{literal: token}
+}
+
+// Evaluate compara os operandos. Atributos vazios nunca são considerados iguais,
+// para que recursos sem tenant não coincidam com principais sem tenant.
+func (e *expression) Evaluate(request Request) bool {
+	left := e.left.resolve(request)
+	right := e.right.resolve(request)
+
+	switch e.operator {
+	case opEquals:
+		return len(left) == 1 && len(right) == 1 && left[0] != "" && left[0] == right[0]
+	case opNotEquals:
+		return !(len(left) == 1 && len(right) == 1 && left[0] == right[0])
+	case opContains:
+		if len(right) != 1 || right[0] == "" {
+			return false
+		}
+		for _, value := range left {
+			if value == right[0] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o operand) resolve(request Request) []string {
+	switch o.scope {
+	case "subject":
+		principal := request.Principal
+		if principal == nil {
+			return nil
+		}
+		switch o.name {
+		case "id":
+			return []string{principal.UserID.String()}
+		case "tenant_id":
+			return []string{principal.TenantID}
+		case "roles":
+			return principal.Roles
+		case "permissions":
+			return principal.Permissions
+		}
+		return nil
+	case "resource":
+		switch o.name {
+		case "id":
+			return []string{request.Resource.ID}
+		case "type":
+			return []string{request.Resource.Type}
+		}
+		value, exists := request.Resource.Attributes[o.name]
+		if !exists {
+			return nil
+		}
+		return []string{value}
+	}
+	return []string{o.literal}
+}
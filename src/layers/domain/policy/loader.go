@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document representa um arquivo de políticas em JSON ou YAML.
+type document struct {
+	Rules []ruleDocument `json:"rules" yaml:"rules"`
+}
+
+// ruleDocument representa uma regra declarativa. As expressões em "all" precisam valer todas
+// e, se houver "any", ao menos uma delas precisa valer.
+type ruleDocument struct {
+	Name     string   `json:"name" yaml:"name"`
+	Effect   string   `json:"effect" yaml:"effect"`
+	Actions  []string `json:"actions" yaml:"actions"`
+	Resource string   `json:"resource" yaml:"resource"`
+	All      []string `json:"all" yaml:"all"`
+	Any      []string `json:"any" yaml:"any"`
+}
+
+// LoadFile lê regras de um arquivo .json, .yaml ou .yml.
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &doc)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("formato de arquivo de políticas não suportado: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler o arquivo de políticas %s: %w", path, err)
+	}
+
+	return doc.rules()
+}
+
+func (d document) rules() ([]Rule, error) {
+	rules := make([]Rule, 0, len(d.Rules))
+	for i, rd := range d.Rules {
+		rule, err := rd.rule()
+		if err != nil {
+			return nil, fmt.Errorf("regra %d (%s): %w", i, rd.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (rd ruleDocument) rule() (Rule, error) {
+	effect := Effect(strings.ToLower(rd.Effect))
+	if effect != Allow && effect != Deny {
+		return Rule{}, fmt.Errorf("efeito inválido %q", rd.Effect)
+	}
+	if len(rd.Actions) == 0 {
+		return Rule{}, fmt.Errorf("ao menos uma ação deve ser informada")
+	}
+
+	var conditions []Condition
+	for _, source := range rd.All {
+		condition, err := Expr(source)
+		if err != nil {
+			return Rule{}, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(rd.Any) > 0 {
+		alternatives := make([]Condition, 0, len(rd.Any))
+		for _, source := range rd.Any {
+			condition, err := Expr(source)
+			if err != nil {
+				return Rule{}, err
+			}
+			alternatives = append(alternatives, condition)
+		}
+		conditions = append(conditions, Any(alternatives...))
+	}
+
+	rule := Rule{Name: rd.Name, Effect: effect, Actions: rd.Actions, Resource: rd.Resource}
+	if len(conditions) > 0 {
+		rule.Condition = All(conditions...)
+	}
+	return rule, nil
+}
@@ -0,0 +1,184 @@
+// Package policy avalia regras de acesso baseadas em atributos (ABAC) do principal, da ação e do recurso.
+package policy
+
+import (
+	"log"
+	"server/src/layers/domain/models"
+	"strings"
+)
+
+// Effect define se uma regra concede ou nega o acesso.
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// Resource descreve o alvo da ação e os atributos usados nas regras (por exemplo, owner_id e tenant_id).
+type Resource struct {
+	Type       string
+	ID         string
+	Attributes map[string]string
+}
+
+// Request reúne o principal, a ação e o recurso avaliados pelo motor.
+type Request struct {
+	Principal *models.Principal
+	Action    string
+	Resource  Resource
+}
+
+// Decision é o resultado de uma avaliação.
+type Decision struct {
+	Allowed bool
+	Rule    string
+	Reason  string
+}
+
+// Condition avalia se uma regra se aplica à requisição.
+type Condition interface {
+	Evaluate(request Request) bool
+}
+
+// ConditionFunc permite escrever condições diretamente em Go.
+type ConditionFunc func(request Request) bool
+
+// Evaluate executa a função da condição.
+func (f ConditionFunc) Evaluate(request Request) bool {
+	return f(request)
+}
+
+// Rule relaciona ações sobre um tipo de recurso a uma condição. Sem condição, a regra sempre se aplica.
+type Rule struct {
+	Name      string
+	Effect    Effect
+	Actions   []string // aceita "*" e curingas como "users:*"
+	Resource  string   // tipo do recurso; "*" ou vazio vale para qualquer tipo
+	Condition Condition
+}
+
+// DecisionLogger registra as decisões para auditoria.
+type DecisionLogger interface {
+	LogDecision(request Request, decision Decision)
+}
+
+// LogDecisionLogger registra as decisões no log padrão da aplicação.
+type LogDecisionLogger struct{}
+
+// LogDecision escreve a decisão no log.
+func (LogDecisionLogger) LogDecision(request Request, decision Decision) {
+	subject := "anônimo"
+	if request.Principal != nil {
+		subject = request.Principal.UserID.String()
+	}
+
+	outcome := "negado"
+	if decision.Allowed {
+		outcome = "permitido"
+	}
+
+	log.Printf("auditoria de acesso: %s action=%s subject=%s resource=%s/%s rule=%q reason=%q",
+		outcome, request.Action, subject, request.Resource.Type, request.Resource.ID, decision.Rule, decision.Reason)
+}
+
+// Engine avalia as regras com precedência de negação: qualquer regra deny aplicável nega o acesso,
+// e na ausência de uma regra allow aplicável o acesso também é negado.
+type Engine struct {
+	rules  []Rule
+	logger DecisionLogger
+}
+
+// NewEngine cria um motor de políticas. Sem logger, as decisões são registradas no log padrão.
+func NewEngine(rules []Rule, logger DecisionLogger) *Engine {
+	if logger == nil {
+		logger = LogDecisionLogger{}
+	}
+	return &Engine{rules: rules, logger: logger}
+}
+
+// Evaluate avalia a requisição contra as regras e registra a decisão.
+func (e *Engine) Evaluate(request Request) Decision {
+	decision := e.evaluate(request)
+	e.logger.LogDecision(request, decision)
+	return decision
+}
+
+// Authorize avalia a requisição e retorna models.ErrAccessDenied quando o acesso é negado.
+func (e *Engine) Authorize(principal *models.Principal, action string, resource Resource) error {
+	decision := e.Evaluate(Request{Principal: principal, Action: action, Resource: resource})
+	if !decision.Allowed {
+		return models.ErrAccessDenied
+	}
+	return nil
+}
+
+func (e *Engine) evaluate(request Request) Decision {
+	if request.Principal == nil {
+		return Decision{Reason: "principal ausente"}
+	}
+
+	var allowedBy string
+	for _, rule := range e.rules {
+		if !rule.matches(request) {
+			continue
+		}
+		if rule.Effect == Deny {
+			return Decision{Rule: rule.Name, Reason: "negado por regra"}
+		}
+		if allowedBy == "" {
+			allowedBy = rule.Name
+		}
+	}
+
+	if allowedBy == "" {
+		return Decision{Reason: "nenhuma regra concedeu acesso"}
+	}
+	return Decision{Allowed: true, Rule: allowedBy, Reason: "permitido por regra"}
+}
+
+func (r Rule) matches(request Request) bool {
+	if r.Resource != "" && r.Resource != "*" && r.Resource != request.Resource.Type {
+		return false
+	}
+	if !matchesAction(r.Actions, request.Action) {
+		return false
+	}
+	return r.Condition == nil || r.Condition.Evaluate(request)
+}
+
+func matchesAction(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == action {
+			return true
+		}
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(action, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// All aplica-se quando todas as condições se aplicam.
+func All(conditions ...Condition) Condition {
+	return ConditionFunc(func(request Request) bool {
+		for _, condition := range conditions {
+			if !condition.Evaluate(request) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Any aplica-se quando ao menos uma das condições se aplica.
+func Any(conditions ...Condition) Condition {
+	return ConditionFunc(func(request Request) bool {
+		for _, condition := range conditions {
+			if condition.Evaluate(request) {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"server/src/layers/domain/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type recordingLogger struct {
+	decisions []Decision
+}
+
+func (l *recordingLogger) LogDecision(_ Request, decision Decision) {
+	l.decisions = append(l.decisions, decision)
+}
+
+func newUser(tenantID string) *models.User {
+	user := &models.User{TenantID: tenantID}
+	user.ID = uuid.New()
+	return user
+}
+
+func TestEngine_DefaultRules(t *testing.T) {
+	logger := &recordingLogger{}
+	engine := NewEngine(DefaultRules(), logger)
+
+	owner := newUser("")
+	other := newUser("")
+
+	user := &models.Principal{UserID: owner.ID, Permissions: models.RolePermissions[models.RoleUser]}
+	admin := &models.Principal{UserID: uuid.New(), Permissions: models.RolePermissions[models.RoleAdmin]}
+	scoped := &models.Principal{UserID: owner.ID, Permissions: []string{models.PermissionMetricsRead}}
+
+	tests := []struct {
+		name      string
+		principal *models.Principal
+		action    string
+		resource  Resource
+		allowed   bool
+	}{
+		{"Owner reads own record", user, ActionUsersRead, UserResource(owner), true},
+		{"User reads another record", user, ActionUsersRead, UserResource(other), false},
+		{"User lists users", user, ActionUsersList, Resource{Type: ResourceUser}, false},
+		{"Admin reads another record", admin, ActionUsersRead, UserResource(other), true},
+		{"Admin lists users", admin, ActionUsersList, Resource{Type: ResourceUser}, true},
+		{"Owner updates own record", user, ActionUsersUpdate, UserResource(owner), true},
+		{"Anonymous reads record", nil, ActionUsersRead, UserResource(owner), false},
+		{"Scoped token reads own record", scoped, ActionUsersRead, UserResource(owner), false},
+		{"Scoped token updates own record", scoped, ActionUsersUpdate, UserResource(owner), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := engine.Authorize(test.principal, test.action, test.resource)
+			if test.allowed && err != nil {
+				t.Errorf("Esperava acesso permitido, obteve: %v", err)
+			}
+			if !test.allowed && err != models.ErrAccessDenied {
+				t.Errorf("Esperava acesso negado, obteve: %v", err)
+			}
+		})
+	}
+
+	if len(logger.decisions) != len(tests) {
+		t.Errorf("Esperava %d decisões registradas, obteve %d", len(tests), len(logger.decisions))
+	}
+}
+
+func TestEngine_DenyOverridesAllow(t *testing.T) {
+	engine := NewEngine([]Rule{
+		{Name: "allow-all", Effect: Allow, Actions: []string{"*"}},
+		{Name: "no-deletes", Effect: Deny, Actions: []string{"users:delete"}, Resource: ResourceUser},
+	}, &recordingLogger{})
+
+	principal := &models.Principal{UserID: uuid.New()}
+
+	decision := engine.Evaluate(Request{Principal: principal, Action: "users:delete", Resource: Resource{Type: ResourceUser}})
+	if decision.Allowed || decision.Rule != "no-deletes" {
+		t.Errorf("Esperava negação pela regra no-deletes, obteve: %+v", decision)
+	}
+
+	decision = engine.Evaluate(Request{Principal: principal, Action: "users:read", Resource: Resource{Type: ResourceUser}})
+	if !decision.Allowed {
+		t.Errorf("Esperava acesso permitido pela regra allow-all, obteve: %+v", decision)
+	}
+}
+
+func TestLoadFile_TenantRule(t *testing.T) {
+	rules, err := LoadFile(filepath.Join("..", "..", "..", "..", "docs", "policies.example.yaml"))
+	if err != nil {
+		t.Fatalf("Falha ao carregar as políticas: %v", err)
+	}
+
+	engine := NewEngine(rules, &recordingLogger{})
+
+	tenant := uuid.NewString()
+	record := newUser(tenant)
+
+	permissions := models.RolePermissions[models.RoleUser]
+	sameTenant := &models.Principal{UserID: uuid.New(), TenantID: tenant, Permissions: permissions}
+	otherTenant := &models.Principal{UserID: uuid.New(), TenantID: uuid.NewString(), Permissions: permissions}
+	noTenant := &models.Principal{UserID: uuid.New(), Permissions: permissions}
+	scoped := &models.Principal{UserID: record.ID, TenantID: tenant, Permissions: []string{models.PermissionMetricsRead}}
+
+	if err := engine.Authorize(sameTenant, ActionUsersUpdate, UserResource(record)); err != nil {
+		t.Errorf("Usuário do mesmo tenant deveria alterar o registro: %v", err)
+	}
+	if err := engine.Authorize(otherTenant, ActionUsersUpdate, UserResource(record)); err == nil {
+		t.Error("Usuário de outro tenant não deveria alterar o registro")
+	}
+	if err := engine.Authorize(noTenant, ActionUsersUpdate, UserResource(newUser(""))); err == nil {
+		t.Error("Tenants vazios não deveriam ser considerados iguais")
+	}
+	if err := engine.Authorize(scoped, ActionUsersRead, UserResource(record)); err == nil {
+		t.Error("Token sem profile:read não deveria ler o próprio registro")
+	}
+}
+
+func TestLoadFile_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	content := `{"rules": [{"name": "admins", "effect": "allow", "actions": ["users:*"], "resource": "user", "all": ["subject.roles contains 'admin'"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Falha ao escrever o arquivo: %v", err)
+	}
+
+	rules, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Falha ao carregar as políticas: %v", err)
+	}
+
+	engine := NewEngine(rules, &recordingLogger{})
+	admin := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleAdmin}}
+
+	if err := engine.Authorize(admin, ActionUsersList, Resource{Type: ResourceUser}); err != nil {
+		t.Errorf("Administrador deveria listar usuários: %v", err)
+	}
+}
+
+func TestLoadFile_InvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"invalid-effect.json":     `{"rules": [{"name": "x", "effect": "maybe", "actions": ["*"]}]}`,
+		"invalid-expression.json": `{"rules": [{"name": "x", "effect": "allow", "actions": ["*"], "all": ["subject.id ~ resource.id"]}]}`,
+		"missing-actions.json":    `{"rules": [{"name": "x", "effect": "allow"}]}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			os.WriteFile(path, []byte(content), 0600)
+
+			if _, err := LoadFile(path); err == nil {
+				t.Error("Esperava erro ao carregar regra inválida")
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"time"
+)
+
+var ErrAPIKeyNotFound = errors.New("chave de api não encontrada")
+
+// APIKeyRepository define a interface de armazenamento das chaves de acesso pessoais
+type APIKeyRepository interface {
+	Store(key *models.APIKey) error
+	FindByHash(keyHash string) (*models.APIKey, error)
+	FindByUser(userID uuid.UUID) ([]*models.APIKey, error)
+	// Revoke revoga a chave do usuário e retorna false se ela não existir ou já estiver revogada.
+	Revoke(id, userID uuid.UUID, at time.Time) (bool, error)
+	TouchLastUsed(id uuid.UUID, at time.Time) error
+}
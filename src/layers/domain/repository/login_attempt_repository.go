@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"sync"
+)
+
+var ErrLoginAttemptNotFound = errors.New("tentativas de login não encontradas")
+
+// LoginAttemptRepository define a interface de armazenamento das falhas de autenticação.
+// Create e Update são condicionais e retornam false quando outra gravação chegou antes.
+type LoginAttemptRepository interface {
+	Find(key string) (*models.LoginAttempt, error)
+	Create(attempt *models.LoginAttempt) (bool, error)
+	Update(attempt *models.LoginAttempt) (bool, error)
+	Delete(key string) error
+}
+
+// MockLoginAttemptRepository é uma implementação fictícia do LoginAttemptRepository para testes
+type MockLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*models.LoginAttempt
+}
+
+// NewMockLoginAttemptRepository cria uma nova instância do MockLoginAttemptRepository
+func NewMockLoginAttemptRepository() *MockLoginAttemptRepository {
+	return &MockLoginAttemptRepository{attempts: make(map[string]*models.LoginAttempt)}
+}
+
+// Find retorna as tentativas registradas para a chave
+func (m *MockLoginAttemptRepository) Find(key string) (*models.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if attempt, exists := m.attempts[key]; exists {
+		copied := *attempt
+		return &copied, nil
+	}
+	return nil, ErrLoginAttemptNotFound
+}
+
+// Create insere as tentativas da chave se ela ainda não tiver registro
+func (m *MockLoginAttemptRepository) Create(attempt *models.LoginAttempt) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.attempts[attempt.Key]; exists {
+		return false, nil
+	}
+	if attempt.ID == uuid.Nil {
+		attempt.ID = uuid.New()
+	}
+	copied := *attempt
+	m.attempts[attempt.Key] = &copied
+	return true, nil
+}
+
+// Update substitui as tentativas da chave se o registro ainda estiver na revisão lida
+func (m *MockLoginAttemptRepository) Update(attempt *models.LoginAttempt) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.attempts[attempt.Key]
+	if !exists || stored.Revision != attempt.Revision {
+		return false, nil
+	}
+	attempt.Revision++
+	copied := *attempt
+	m.attempts[attempt.Key] = &copied
+	return true, nil
+}
+
+// Delete remove as tentativas da chave
+func (m *MockLoginAttemptRepository) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.attempts, key)
+	return nil
+}
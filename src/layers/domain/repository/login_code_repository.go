@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"time"
+)
+
+var ErrLoginCodeNotFound = errors.New("pedido de login não encontrado")
+
+// LoginCodeRepository define a interface de armazenamento dos pedidos de login sem senha
+type LoginCodeRepository interface {
+	Store(code *models.LoginCode) error
+	FindByTokenHash(tokenHash string) (*models.LoginCode, error)
+	// FindActiveByUser retorna o pedido mais recente do usuário ainda utilizável.
+	FindActiveByUser(userID uuid.UUID, now time.Time) (*models.LoginCode, error)
+	// CountSince conta os pedidos do usuário criados a partir de since, usados ou não.
+	CountSince(userID uuid.UUID, since time.Time) (int64, error)
+	// RecordAttempt contabiliza um código errado e retorna o total de tentativas do pedido.
+	RecordAttempt(id uuid.UUID) (int, error)
+	// MarkUsed marca o pedido como usado e retorna false se ele já havia sido consumido.
+	MarkUsed(id uuid.UUID) (bool, error)
+	// InvalidateForUser descarta os pedidos ainda não usados do usuário.
+	InvalidateForUser(userID uuid.UUID) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+var ErrAuthorizationCodeNotFound = errors.New("código de autorização não encontrado")
+
+// AuthorizationCodeRepository define a interface de armazenamento dos códigos de autorização OAuth
+type AuthorizationCodeRepository interface {
+	Store(code *models.OAuthAuthorizationCode) error
+	FindByHash(codeHash string) (*models.OAuthAuthorizationCode, error)
+	// MarkUsed marca o código como usado e retorna false se ele já havia sido consumido.
+	MarkUsed(id uuid.UUID) (bool, error)
+}
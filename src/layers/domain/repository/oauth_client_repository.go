@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"errors"
+	"server/src/layers/domain/models"
+)
+
+var ErrOAuthClientNotFound = errors.New("cliente oauth não encontrado")
+
+// OAuthClientRepository define a interface de armazenamento dos clientes OAuth
+type OAuthClientRepository interface {
+	Store(client *models.OAuthClient) error
+	FindByClientID(clientID string) (*models.OAuthClient, error)
+	FindAll() ([]*models.OAuthClient, error)
+	Delete(clientID string) error
+}
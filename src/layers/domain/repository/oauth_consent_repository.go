@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+var ErrConsentNotFound = errors.New("consentimento não encontrado")
+
+// ConsentRepository define a interface de armazenamento dos consentimentos dados pelos usuários aos clientes OAuth
+type ConsentRepository interface {
+	Find(userID uuid.UUID, clientID string) (*models.OAuthConsent, error)
+	// Save grava o consentimento, substituindo os escopos de um consentimento anterior do mesmo cliente.
+	Save(consent *models.OAuthConsent) error
+}
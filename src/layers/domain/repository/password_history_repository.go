@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+// PasswordHistoryRepository define a interface de armazenamento das senhas anteriores dos usuários
+type PasswordHistoryRepository interface {
+	Store(entry *models.PasswordHistory) error
+	// FindRecentByUser retorna até limit senhas anteriores do usuário, da mais recente para a mais antiga.
+	FindRecentByUser(userID uuid.UUID, limit int) ([]*models.PasswordHistory, error)
+	// PruneForUser mantém apenas as keep senhas mais recentes do usuário.
+	PruneForUser(userID uuid.UUID, keep int) error
+}
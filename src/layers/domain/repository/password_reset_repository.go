@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+var ErrPasswordResetTokenNotFound = errors.New("token de redefinição de senha não encontrado")
+
+// PasswordResetRepository define a interface de armazenamento dos tokens de redefinição de senha
+type PasswordResetRepository interface {
+	Store(token *models.PasswordResetToken) error
+	FindByHash(tokenHash string) (*models.PasswordResetToken, error)
+	// MarkUsed marca o token como usado e retorna false se ele já havia sido consumido.
+	MarkUsed(id uuid.UUID) (bool, error)
+	// InvalidateForUser descarta os tokens ainda não usados do usuário.
+	InvalidateForUser(userID uuid.UUID) error
+}
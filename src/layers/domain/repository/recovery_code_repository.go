@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+// RecoveryCodeRepository define a interface de armazenamento dos códigos de recuperação do segundo fator
+type RecoveryCodeRepository interface {
+	// ReplaceForUser descarta os códigos anteriores do usuário e grava os novos.
+	ReplaceForUser(userID uuid.UUID, codes []*models.RecoveryCode) error
+	FindUnusedByUser(userID uuid.UUID) ([]*models.RecoveryCode, error)
+	// MarkUsed marca o código como usado e retorna false se ele já havia sido consumido.
+	MarkUsed(id uuid.UUID) (bool, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token não encontrado")
+
+// RefreshTokenRepository define a interface de armazenamento das famílias de refresh tokens
+type RefreshTokenRepository interface {
+	Store(token *models.RefreshToken) error
+	FindByTokenID(tokenID string) (*models.RefreshToken, error)
+	// MarkUsed marca o token como usado e retorna false se ele já havia sido consumido.
+	MarkUsed(id uuid.UUID) (bool, error)
+	RevokeFamily(familyID uuid.UUID) error
+	RevokeAllForUser(userID uuid.UUID) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("sessão não encontrada")
+
+// SessionRepository define a interface de armazenamento das sessões de login
+type SessionRepository interface {
+	Store(session *models.Session) error
+	FindByID(id uuid.UUID) (*models.Session, error)
+	FindByFamilyID(familyID uuid.UUID) (*models.Session, error)
+	// FindActiveByUser lista as sessões não revogadas cuja família ainda tem um refresh token válido em now.
+	FindActiveByUser(userID uuid.UUID, now time.Time) ([]*models.Session, error)
+	Touch(id uuid.UUID, at time.Time) error
+	// Revoke encerra a sessão e retorna false se ela não existir ou já estiver revogada.
+	Revoke(id uuid.UUID, at time.Time) (bool, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+)
+
+// SignInHistoryRepository define a interface de armazenamento do histórico de logins
+type SignInHistoryRepository interface {
+	Store(event *models.SignInEvent) error
+	// FindByUser retorna uma página do histórico do usuário, do mais recente para o mais antigo, e o total de eventos.
+	FindByUser(userID uuid.UUID, limit, offset int) ([]*models.SignInEvent, int64, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TokenRevocationRepository define a interface do armazenamento de tokens revogados antes de expirar
+type TokenRevocationRepository interface {
+	// Revoke invalida o token identificado pelo jti até a sua expiração.
+	Revoke(tokenID string, userID uuid.UUID, expiresAt time.Time) error
+	// RevokeAllForUser invalida todos os tokens do usuário emitidos até issuedBefore.
+	RevokeAllForUser(userID uuid.UUID, issuedBefore time.Time, expiresAt time.Time) error
+	// IsRevoked informa se o token foi revogado individualmente ou por uma revogação geral do usuário.
+	IsRevoked(tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	// PurgeExpired remove as revogações cujos tokens já expiraram e retorna quantas foram removidas.
+	PurgeExpired(now time.Time) (int64, error)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"server/src/layers/domain/models"
+	"time"
 )
 
 var (
@@ -16,8 +17,15 @@ type UserRepository interface {
 	Store(user *models.User) (*models.User, error)
 	FindByID(id uuid.UUID) (*models.User, error)
 	FindByCPF(cpf string) (*models.User, error)
+	// FindByEmail busca pelo e-mail já normalizado, confirmado ou não.
+	FindByEmail(email string) (*models.User, error)
 	Update(user *models.User) error
 	UpdatePassword(id uuid.UUID, hashedPassword string) error
+	// ChangePassword grava a nova senha escolhida pelo usuário e a data da troca.
+	ChangePassword(id uuid.UUID, hashedPassword string, changedAt time.Time) error
+	// AdvanceTOTPStep grava o intervalo TOTP aceito somente se ele for posterior ao último gravado;
+	// retorna false quando outro login já usou esse intervalo.
+	AdvanceTOTPStep(id uuid.UUID, step int64) (bool, error)
 	Delete(id uuid.UUID) error
 	FindAllWithPagination(limit int, offset int) ([]*models.User, error)
 }
@@ -33,16 +41,16 @@ func NewMockUserRepository() *MockUserRepository {
 }
 
 // Store adiciona um novo usuário ao armazenamento fictício
-func (m *MockUserRepository) Store(user *models.User) error {
+func (m *MockUserRepository) Store(user *models.User) (*models.User, error) {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
 
 	if _, exists := m.users[user.ID]; exists {
-		return ErrUserExists
+		return nil, ErrUserExists
 	}
 	m.users[user.ID] = user
-	return nil
+	return user, nil
 }
 
 // FindByID retorna um usuário pelo ID do armazenamento fictício
@@ -63,6 +71,16 @@ func (m *MockUserRepository) FindByCPF(cpf string) (*models.User, error) {
 	return nil, ErrUserNotFound
 }
 
+// FindByEmail retorna um usuário pelo e-mail do armazenamento fictício
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	for _, user := range m.users {
+		if email != "" && user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
 // Update atualiza um usuário existente no armazenamento fictício
 func (m *MockUserRepository) Update(user *models.User) error {
 	if _, exists := m.users[user.ID]; !exists {
@@ -81,6 +99,30 @@ func (m *MockUserRepository) UpdatePassword(id uuid.UUID, hashedPassword string)
 	return nil
 }
 
+// AdvanceTOTPStep avança o último intervalo TOTP aceito no armazenamento fictício
+func (m *MockUserRepository) AdvanceTOTPStep(id uuid.UUID, step int64) (bool, error) {
+	user, exists := m.users[id]
+	if !exists {
+		return false, ErrUserNotFound
+	}
+	if user.TOTPLastStep >= step {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+// ChangePassword atualiza a senha e a data da troca no armazenamento fictício
+func (m *MockUserRepository) ChangePassword(id uuid.UUID, hashedPassword string, changedAt time.Time) error {
+	user, exists := m.users[id]
+	if !exists {
+		return ErrUserNotFound
+	}
+	user.Password = hashedPassword
+	user.PasswordChangedAt = changedAt
+	return nil
+}
+
 // Delete remove um usuário pelo ID do armazenamento fictício
 func (m *MockUserRepository) Delete(id uuid.UUID) error {
 	if _, exists := m.users[id]; !exists {
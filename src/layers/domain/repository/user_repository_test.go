@@ -12,13 +12,13 @@ func TestMockUserRepository_Store(t *testing.T) {
 	user := &models.User{CPF: "83103569009", FirstName: "Lucas", LastName: "Albuquerque"}
 
 	// Teste para adicionar um novo usuário
-	err := repo.Store(user)
+	_, err := repo.Store(user)
 	if err != nil {
 		t.Fatalf("Erro ao armazenar o usuário: %v", err)
 	}
 
 	// Teste para verificar se o usuário já existe
-	err = repo.Store(user)
+	_, err = repo.Store(user)
 	if err != ErrUserExists {
 		t.Fatalf("Esperado erro de usuário já existe, mas obteve: %v", err)
 	}
@@ -0,0 +1,108 @@
+package breachlist
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// SHA1File consulta senhas vazadas em um arquivo de hashes SHA-1 ordenados, um por linha, no formato
+// "HASH" ou "HASH:ocorrências" (como o export "ordered by hash" do Have I Been Pwned).
+// A busca binária é feita direto no arquivo, sem carregá-lo em memória e sem acesso à rede.
+type SHA1File struct {
+	file *os.File
+	size int64
+}
+
+// Open abre o arquivo de hashes vazados.
+func Open(path string) (*SHA1File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &SHA1File{file: file, size: info.Size()}, nil
+}
+
+// Close fecha o arquivo de hashes.
+func (f *SHA1File) Close() error {
+	return f.file.Close()
+}
+
+// IsBreached informa se o SHA-1 da senha consta no arquivo.
+func (f *SHA1File) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// Invariante: se o hash existir, sua linha começa no intervalo [low, high)
+	low, high := int64(0), f.size
+	for low < high {
+		mid := low + (high-low)/2
+
+		start, err := f.lineStart(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= high {
+			high = mid
+			continue
+		}
+
+		hash, end, err := f.readHash(start)
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case hash == target:
+			return true, nil
+		case hash < target:
+			low = end
+		default:
+			high = mid
+		}
+	}
+
+	return false, nil
+}
+
+// lineStart retorna o início da primeira linha que começa em offset ou depois dele
+func (f *SHA1File) lineStart(offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(f.file, offset-1, f.size-offset+1))
+	skipped, err := reader.ReadSlice('\n')
+	if errors.Is(err, io.EOF) {
+		return f.size, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return offset - 1 + int64(len(skipped)), nil
+}
+
+// readHash lê o hash da linha iniciada em offset e retorna também o início da linha seguinte
+func (f *SHA1File) readHash(offset int64) (string, int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(f.file, offset, f.size-offset))
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+
+	end := offset + int64(len(line))
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash), end, nil
+}
@@ -0,0 +1,85 @@
+package breachlist
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeList(t *testing.T, passwords []string) string {
+	var lines []string
+	for i, password := range passwords {
+		lines = append(lines, sha1Hex(password)+":"+strings.Repeat("9", i+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600); err != nil {
+		t.Fatalf("Erro ao gravar o arquivo: %v", err)
+	}
+	return path
+}
+
+func TestSHA1File_IsBreached(t *testing.T) {
+	var breached []string
+	for i := 0; i < 500; i++ {
+		breached = append(breached, "senha"+strings.Repeat("x", i%7)+string(rune('a'+i%26))+strings.Repeat("1", i/26))
+	}
+
+	list, err := Open(writeList(t, breached))
+	if err != nil {
+		t.Fatalf("Erro ao abrir o arquivo: %v", err)
+	}
+	defer list.Close()
+
+	for _, password := range breached {
+		found, err := list.IsBreached(password)
+		if err != nil {
+			t.Fatalf("Erro na consulta: %v", err)
+		}
+		if !found {
+			t.Errorf("Esperava que %q constasse no arquivo", password)
+		}
+	}
+
+	for _, password := range []string{"Correct-Horse-7", "", "senha"} {
+		found, err := list.IsBreached(password)
+		if err != nil {
+			t.Fatalf("Erro na consulta: %v", err)
+		}
+		if found {
+			t.Errorf("Não esperava que %q constasse no arquivo", password)
+		}
+	}
+}
+
+func TestSHA1File_EmptyAndSingleLine(t *testing.T) {
+	empty, err := Open(writeList(t, nil))
+	if err != nil {
+		t.Fatalf("Erro ao abrir o arquivo: %v", err)
+	}
+	defer empty.Close()
+
+	if found, _ := empty.IsBreached("qualquer"); found {
+		t.Error("Arquivo vazio não deveria conter senhas")
+	}
+
+	single, err := Open(writeList(t, []string{"123456"}))
+	if err != nil {
+		t.Fatalf("Erro ao abrir o arquivo: %v", err)
+	}
+	defer single.Close()
+
+	if found, _ := single.IsBreached("123456"); !found {
+		t.Error("Esperava encontrar a única senha do arquivo")
+	}
+}
@@ -0,0 +1,169 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"net/url"
+	"server/src/layers/domain/authn"
+	"strings"
+	"time"
+	"unicode"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// Attributes define os atributos do diretório mapeados para os campos do usuário local.
+type Attributes struct {
+	CPF       string
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+// Config descreve o acesso ao diretório. A conta de serviço (BindDN) localiza a entrada do usuário
+// pelo UserFilter, em que %s é substituído pelo identificador escapado; sem BindDN a busca é anônima.
+type Config struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string
+	Attributes         Attributes
+	Timeout            time.Duration
+}
+
+// Directory autentica usuários por simple bind no LDAP: localiza a entrada do usuário e faz o bind com o
+// DN encontrado e a senha informada. Cada autenticação usa uma conexão própria.
+type Directory struct {
+	config Config
+}
+
+// NewDirectory cria um Directory com a configuração informada.
+func NewDirectory(config Config) *Directory {
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+	return &Directory{config: config}
+}
+
+// Authenticate verifica as credenciais no diretório e retorna a identidade com os atributos mapeados.
+func (d *Directory) Authenticate(username, password string) (*authn.Identity, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, authn.ErrUnknownIdentity
+	}
+	// Um bind com senha vazia é anônimo e seria aceito pelo servidor sem verificar nada
+	if password == "" {
+		return nil, authn.ErrInvalidCredentials
+	}
+
+	conn, err := d.dial()
+	if err != nil {
+		log.Printf("falha ao conectar ao LDAP: %v", err)
+		return nil, authn.ErrUnavailable
+	}
+	defer conn.Close()
+
+	if d.config.BindDN != "" {
+		if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			log.Printf("falha no bind da conta de serviço do LDAP: %v", err)
+			return nil, authn.ErrUnavailable
+		}
+	}
+
+	entry, err := d.findEntry(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, authn.ErrInvalidCredentials
+		}
+		log.Printf("falha no bind do usuário no LDAP: %v", err)
+		return nil, authn.ErrUnavailable
+	}
+
+	return d.identity(entry), nil
+}
+
+// findEntry busca a entrada única que corresponde ao identificador; nenhuma ou mais de uma são tratadas
+// como identidade desconhecida.
+func (d *Directory) findEntry(conn *goldap.Conn, username string) (*goldap.Entry, error) {
+	filter := strings.ReplaceAll(d.config.UserFilter, "%s", goldap.EscapeFilter(username))
+	attributes := []string{d.config.Attributes.CPF, d.config.Attributes.FirstName, d.config.Attributes.LastName, d.config.Attributes.Email}
+
+	request := goldap.NewSearchRequest(
+		d.config.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		2, int(d.config.Timeout.Seconds()), false, filter, attributes, nil,
+	)
+
+	result, err := conn.Search(request)
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) || (err == nil && len(result.Entries) > 1) {
+		log.Printf("o filtro do LDAP encontrou mais de uma entrada para o identificador informado")
+		return nil, authn.ErrUnknownIdentity
+	}
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+		return nil, authn.ErrUnknownIdentity
+	}
+	if err != nil {
+		log.Printf("falha na busca do usuário no LDAP: %v", err)
+		return nil, authn.ErrUnavailable
+	}
+	if len(result.Entries) == 0 {
+		return nil, authn.ErrUnknownIdentity
+	}
+	return result.Entries[0], nil
+}
+
+// identity mapeia os atributos da entrada; o cpf é guardado apenas com os dígitos.
+func (d *Directory) identity(entry *goldap.Entry) *authn.Identity {
+	cpf := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, entry.GetEqualFoldAttributeValue(d.config.Attributes.CPF))
+
+	return &authn.Identity{
+		ExternalID: entry.DN,
+		CPF:        cpf,
+		FirstName:  strings.TrimSpace(entry.GetEqualFoldAttributeValue(d.config.Attributes.FirstName)),
+		LastName:   strings.TrimSpace(entry.GetEqualFoldAttributeValue(d.config.Attributes.LastName)),
+		Email:      entry.GetEqualFoldAttributeValue(d.config.Attributes.Email),
+	}
+}
+
+// dial abre a conexão com o diretório, com TLS para ldaps:// ou por StartTLS quando configurado.
+func (d *Directory) dial() (*goldap.Conn, error) {
+	parsed, err := url.Parse(d.config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         parsed.Hostname(),
+		InsecureSkipVerify: d.config.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	conn, err := goldap.DialURL(d.config.URL,
+		goldap.DialWithDialer(&net.Dialer{Timeout: d.config.Timeout}),
+		goldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(d.config.Timeout)
+
+	if d.config.StartTLS && parsed.Scheme != "ldaps" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
@@ -0,0 +1,121 @@
+package ldap
+
+import (
+	"errors"
+	"server/src/layers/domain/authn"
+	"server/src/layers/infrastructure/ldap/ldaptest"
+	"testing"
+	"time"
+)
+
+const serviceDN = "cn=servico,ou=system,dc=example,dc=com"
+
+func startDirectory(t *testing.T) (*ldaptest.Server, *Directory) {
+	server, err := ldaptest.NewServer(
+		ldaptest.Entry{DN: serviceDN, Password: "segredo"},
+		ldaptest.Entry{
+			DN:       "uid=ana,ou=people,dc=example,dc=com",
+			Password: "senha-da-ana",
+			Attributes: map[string][]string{
+				"uid":            {"ana"},
+				"employeeNumber": {"529.982.247-25"},
+				"givenName":      {"Ana"},
+				"sn":             {"Souza"},
+				"mail":           {"ana.souza@example.com"},
+			},
+		},
+		ldaptest.Entry{DN: "uid=bruno,ou=people,dc=example,dc=com", Password: "x", Attributes: map[string][]string{"uid": {"bruno"}, "sn": {"Lima"}}},
+		ldaptest.Entry{DN: "uid=carla,ou=people,dc=example,dc=com", Password: "y", Attributes: map[string][]string{"uid": {"carla"}, "sn": {"Lima"}}},
+	)
+	if err != nil {
+		t.Fatalf("Erro ao iniciar o servidor LDAP: %v", err)
+	}
+	server.RequireBind = true
+	t.Cleanup(func() { server.Close() })
+
+	directory := NewDirectory(Config{
+		URL:          server.URL(),
+		BindDN:       serviceDN,
+		BindPassword: "segredo",
+		BaseDN:       "ou=people,dc=example,dc=com",
+		Attributes:   Attributes{CPF: "employeeNumber", FirstName: "givenName", LastName: "sn", Email: "mail"},
+		Timeout:      2 * time.Second,
+	})
+	return server, directory
+}
+
+func TestDirectory_Authenticate(t *testing.T) {
+	_, directory := startDirectory(t)
+
+	identity, err := directory.Authenticate("ana", "senha-da-ana")
+	if err != nil {
+		t.Fatalf("Erro ao autenticar: %v", err)
+	}
+
+	expected := authn.Identity{
+		ExternalID: "uid=ana,ou=people,dc=example,dc=com",
+		CPF:        "52998224725",
+		FirstName:  "Ana",
+		LastName:   "Souza",
+		Email:      "ana.souza@example.com",
+	}
+	if *identity != expected {
+		t.Errorf("Esperava %+v, obteve %+v", expected, *identity)
+	}
+}
+
+func TestDirectory_Authenticate_Failures(t *testing.T) {
+	server, directory := startDirectory(t)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		expected error
+	}{
+		{"senha incorreta", "ana", "errada", authn.ErrInvalidCredentials},
+		{"usuário inexistente", "zeca", "qualquer", authn.ErrUnknownIdentity},
+		{"filtro com curinga escapado", "*", "senha-da-ana", authn.ErrUnknownIdentity},
+		{"injeção no filtro", "ana)(uid=*", "senha-da-ana", authn.ErrUnknownIdentity},
+		{"senha vazia", "ana", "", authn.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := directory.Authenticate(tt.username, tt.password); !errors.Is(err, tt.expected) {
+				t.Errorf("Esperava %v, obteve %v", tt.expected, err)
+			}
+		})
+	}
+
+	// A senha vazia é recusada antes de qualquer bind, que o servidor aceitaria como anônimo
+	for _, dn := range server.Binds() {
+		if dn == "" {
+			t.Error("Nenhum bind anônimo deveria ser feito")
+		}
+	}
+}
+
+func TestDirectory_Authenticate_AmbiguousFilter(t *testing.T) {
+	_, directory := startDirectory(t)
+	directory.config.UserFilter = "(sn=%s)"
+
+	if _, err := directory.Authenticate("Lima", "x"); !errors.Is(err, authn.ErrUnknownIdentity) {
+		t.Errorf("Esperava identidade desconhecida para um filtro com mais de uma entrada, obteve %v", err)
+	}
+}
+
+func TestDirectory_Authenticate_Unavailable(t *testing.T) {
+	server, directory := startDirectory(t)
+
+	directory.config.BindPassword = "errada"
+	if _, err := directory.Authenticate("ana", "senha-da-ana"); !errors.Is(err, authn.ErrUnavailable) {
+		t.Errorf("Esperava indisponível com a conta de serviço recusada, obteve %v", err)
+	}
+
+	server.Close()
+	directory.config.BindPassword = "segredo"
+	if _, err := directory.Authenticate("ana", "senha-da-ana"); !errors.Is(err, authn.ErrUnavailable) {
+		t.Errorf("Esperava indisponível com o servidor fora do ar, obteve %v", err)
+	}
+}
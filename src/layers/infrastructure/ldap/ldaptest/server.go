@@ -0,0 +1,291 @@
+// Package ldaptest oferece um servidor LDAP mínimo, em processo, para testar a autenticação sem um diretório real.
+// Ele atende simple bind, busca com filtros de igualdade, presença, and, or e not, e unbind; as demais
+// operações encerram a conexão.
+package ldaptest
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// Operações do protocolo atendidas pelo servidor
+const (
+	opBindRequest    = 0
+	opBindResponse   = 1
+	opSearchRequest  = 3
+	opSearchEntry    = 4
+	opSearchDone     = 5
+	opAbandonRequest = 16
+)
+
+// Códigos de resultado
+const (
+	resultSuccess            = 0
+	resultSizeLimitExceeded  = 4
+	resultInvalidCredentials = 49
+	resultInsufficientAccess = 50
+	resultUnwillingToPerform = 53
+)
+
+// Tipos de filtro de busca
+const (
+	filterAnd           = 0
+	filterOr            = 1
+	filterNot           = 2
+	filterEqualityMatch = 3
+	filterPresent       = 7
+)
+
+// Entry é uma entrada do diretório; Password é a senha aceita no bind com o DN da entrada.
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// Server é um diretório em memória que escuta em uma porta local aleatória.
+// Com RequireBind, buscas anônimas são recusadas, como nos diretórios corporativos.
+type Server struct {
+	RequireBind bool
+
+	listener net.Listener
+	mu       sync.Mutex
+	entries  []Entry
+	binds    []string
+}
+
+// NewServer inicia o servidor com as entradas informadas.
+func NewServer(entries ...Entry) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{listener: listener, entries: entries}
+	go server.serve()
+	return server, nil
+}
+
+// URL retorna o endereço ldap:// do servidor.
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// Close encerra o servidor.
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+// Binds retorna os DNs de todos os binds recebidos, bem-sucedidos ou não.
+func (s *Server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle atende as mensagens de uma conexão até o unbind ou até uma operação não suportada.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	bound := false
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+
+		request := packet.Children[1]
+		var responses []*ber.Packet
+		switch request.Tag {
+		case opBindRequest:
+			var code int64
+			code, bound = s.bind(request)
+			responses = append(responses, result(opBindResponse, code, ""))
+		case opSearchRequest:
+			if s.RequireBind && !bound {
+				responses = append(responses, result(opSearchDone, resultInsufficientAccess, "bind necessário para a busca"))
+				break
+			}
+			responses = s.search(request)
+		case opAbandonRequest:
+			continue
+		default:
+			return
+		}
+
+		for _, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+			envelope.AppendChild(response)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// bind confere o DN e a senha; o bind anônimo é aceito, mas não libera buscas com RequireBind.
+func (s *Server) bind(request *ber.Packet) (int64, bool) {
+	if len(request.Children) < 3 {
+		return resultUnwillingToPerform, false
+	}
+
+	dn := stringValue(request.Children[1])
+	password := request.Children[2].Data.String()
+
+	s.mu.Lock()
+	s.binds = append(s.binds, dn)
+	s.mu.Unlock()
+
+	if dn == "" && password == "" {
+		return resultSuccess, false
+	}
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) && entry.Password != "" && entry.Password == password {
+			return resultSuccess, true
+		}
+	}
+	return resultInvalidCredentials, false
+}
+
+// search retorna as entradas abaixo da base que satisfazem o filtro, respeitando o limite de resultados.
+func (s *Server) search(request *ber.Packet) []*ber.Packet {
+	if len(request.Children) < 8 {
+		return []*ber.Packet{result(opSearchDone, resultUnwillingToPerform, "busca malformada")}
+	}
+
+	baseDN := strings.ToLower(stringValue(request.Children[0]))
+	sizeLimit, _ := request.Children[3].Value.(int64)
+	filter := request.Children[6]
+
+	var requested []string
+	for _, attribute := range request.Children[7].Children {
+		requested = append(requested, stringValue(attribute))
+	}
+
+	var responses []*ber.Packet
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.DN), baseDN) || !matches(filter, entry) {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(responses)) == sizeLimit {
+			return append(responses, result(opSearchDone, resultSizeLimitExceeded, ""))
+		}
+		responses = append(responses, searchEntry(entry, requested))
+	}
+	return append(responses, result(opSearchDone, resultSuccess, ""))
+}
+
+// matches avalia o filtro na entrada; nomes de atributos e valores são comparados sem diferenciar maiúsculas.
+func matches(filter *ber.Packet, entry Entry) bool {
+	switch filter.Tag {
+	case filterAnd:
+		for _, child := range filter.Children {
+			if !matches(child, entry) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range filter.Children {
+			if matches(child, entry) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return len(filter.Children) == 1 && !matches(filter.Children[0], entry)
+	case filterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, value := range attributeValues(entry, stringValue(filter.Children[0])) {
+			if strings.EqualFold(value, stringValue(filter.Children[1])) {
+				return true
+			}
+		}
+		return false
+	case filterPresent:
+		return len(attributeValues(entry, filter.Data.String())) > 0
+	default:
+		return false
+	}
+}
+
+// attributeValues retorna os valores do atributo, sem diferenciar maiúsculas no nome.
+func attributeValues(entry Entry, name string) []string {
+	for attribute, values := range entry.Attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// searchEntry codifica a entrada com os atributos pedidos, ou com todos quando nenhum é pedido.
+func searchEntry(entry Entry, requested []string) *ber.Packet {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, opSearchEntry, nil, "Search Result Entry")
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "objectName"))
+
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range entry.Attributes {
+		if !wanted(name, requested) {
+			continue
+		}
+
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	response.AppendChild(attributes)
+	return response
+}
+
+func wanted(name string, requested []string) bool {
+	if len(requested) == 0 {
+		return true
+	}
+	for _, attribute := range requested {
+		if attribute == "*" || strings.EqualFold(attribute, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// result codifica uma resposta com código de resultado, como BindResponse e SearchResultDone.
+func result(op ber.Tag, code int64, message string) *ber.Packet {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, op, nil, "Result")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "diagnosticMessage"))
+	return response
+}
+
+// stringValue lê o conteúdo de um OCTET STRING
+func stringValue(packet *ber.Packet) string {
+	return packet.Data.String()
+}
@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	domain "server/src/layers/domain/notification"
+	"time"
+)
+
+// FileMailer grava cada e-mail como um arquivo .eml no diretório informado, em vez de enviá-lo.
+// Destinado a desenvolvimento: os links enviados podem ser abertos direto do arquivo.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer cria uma nova instância de FileMailer.
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send grava a mensagem em um novo arquivo, nomeado pela data de envio para manter a ordem.
+func (m *FileMailer) Send(message domain.Message) error {
+	now := time.Now()
+	data, err := formatMessage(m.from, message, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+
+	name := now.UTC().Format("20060102T150405.000000000") + "-" + uuid.NewString() + ".eml"
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o600)
+}
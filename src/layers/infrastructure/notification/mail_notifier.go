@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"fmt"
+	"server/src/layers/domain/models"
+	domain "server/src/layers/domain/notification"
+	"time"
+)
+
+// MailNotifier entrega as notificações ao usuário por e-mail, usando o Mailer configurado.
+type MailNotifier struct {
+	mailer domain.Mailer
+}
+
+// NewMailNotifier cria uma nova instância de MailNotifier.
+func NewMailNotifier(mailer domain.Mailer) *MailNotifier {
+	return &MailNotifier{mailer: mailer}
+}
+
+// NotifyEmailVerification envia o link de confirmação para o e-mail cadastrado pelo usuário.
+func (n *MailNotifier) NotifyEmailVerification(user *models.User, link string, expiresAt time.Time) error {
+	body := fmt.Sprintf("Olá, %s.\n\n"+
+		"Para confirmar este e-mail na sua conta, acesse o link abaixo até %s:\n\n"+
+		"%s\n\n"+
+		"Se você não cadastrou este e-mail, ignore esta mensagem.\n",
+		user.FirstName, expiresAt.Format("02/01/2006 15:04 MST"), link)
+
+	return n.mailer.Send(domain.Message{
+		To:      user.Email,
+		Subject: "Confirme seu e-mail",
+		Body:    body,
+	})
+}
+
+// NotifyPasswordReset envia o token de redefinição de senha para o e-mail confirmado do usuário.
+func (n *MailNotifier) NotifyPasswordReset(user *models.User, token string, expiresAt time.Time) error {
+	body := fmt.Sprintf("Olá, %s.\n\n"+
+		"Use o token abaixo para definir uma nova senha até %s:\n\n"+
+		"%s\n\n"+
+		"O token vale para uma única redefinição. Se você não pediu para redefinir a senha, ignore esta mensagem.\n",
+		user.FirstName, expiresAt.Format("02/01/2006 15:04 MST"), token)
+
+	return n.mailer.Send(domain.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha",
+		Body:    body,
+	})
+}
+
+// NotifyLoginCode envia o link e o código do login sem senha para o e-mail confirmado do usuário.
+func (n *MailNotifier) NotifyLoginCode(user *models.User, code, link string, expiresAt time.Time) error {
+	body := fmt.Sprintf("Olá, %s.\n\n"+
+		"Use o código %s ou acesse o link abaixo para entrar na sua conta até %s:\n\n"+
+		"%s\n\n"+
+		"O código e o link valem para um único acesso. Se você não pediu para entrar, ignore esta mensagem.\n",
+		user.FirstName, code, expiresAt.Format("02/01/2006 15:04 MST"), link)
+
+	return n.mailer.Send(domain.Message{
+		To:      user.Email,
+		Subject: "Seu código de acesso",
+		Body:    body,
+	})
+}
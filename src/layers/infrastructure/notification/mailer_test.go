@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"os"
+	"path/filepath"
+	"server/src/layers/domain/models"
+	domain "server/src/layers/domain/notification"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatMessage(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err := formatMessage("no-reply@example.com", domain.Message{To: "ana@example.com", Subject: "Confirmação", Body: "linha 1\nlinha 2"}, date)
+	if err != nil {
+		t.Fatalf("Erro ao montar a mensagem: %v", err)
+	}
+
+	message := string(data)
+	for _, expected := range []string{
+		"From: no-reply@example.com\r\n",
+		"To: ana@example.com\r\n",
+		"Subject: =?utf-8?q?Confirma=C3=A7=C3=A3o?=\r\n",
+		"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n",
+		"\r\n\r\nlinha 1\r\nlinha 2",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("Esperava %q na mensagem, obteve:\n%s", expected, message)
+		}
+	}
+}
+
+func TestFormatMessage_RejectsHeaderInjection(t *testing.T) {
+	messages := []domain.Message{
+		{To: "ana@example.com\r\nBcc: eve@example.com", Subject: "Oi"},
+		{To: "ana@example.com", Subject: "Oi\nBcc: eve@example.com"},
+		{To: "", Subject: "Oi"},
+	}
+
+	for _, message := range messages {
+		if _, err := formatMessage("no-reply@example.com", message, time.Now()); err == nil {
+			t.Errorf("Esperava a mensagem %+v ser recusada", message)
+		}
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer(dir, "no-reply@example.com")
+
+	if err := mailer.Send(domain.Message{To: "ana@example.com", Subject: "Oi", Body: "corpo"}); err != nil {
+		t.Fatalf("Erro ao gravar a mensagem: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Esperava um arquivo .eml, obteve %v (%v)", files, err)
+	}
+
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "To: ana@example.com") || !strings.HasSuffix(string(data), "corpo") {
+		t.Errorf("Conteúdo inesperado no arquivo: %s", data)
+	}
+}
+
+func TestMailNotifier_NotifyEmailVerification(t *testing.T) {
+	mailer := NewMemoryMailer()
+	notifier := NewMailNotifier(mailer)
+	user := &models.User{FirstName: "Ana", Email: "ana@example.com"}
+
+	if err := notifier.NotifyEmailVerification(user, "http://localhost/email/verify#token=abc", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Erro ao enviar a confirmação: %v", err)
+	}
+
+	messages := mailer.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Esperava uma mensagem enviada, obteve %d", len(messages))
+	}
+	if messages[0].To != "ana@example.com" || !strings.Contains(messages[0].Body, "http://localhost/email/verify#token=abc") {
+		t.Errorf("Mensagem inesperada: %+v", messages[0])
+	}
+}
+
+func TestMailNotifier_NotifyPasswordReset(t *testing.T) {
+	mailer := NewMemoryMailer()
+	notifier := NewMailNotifier(mailer)
+	user := &models.User{FirstName: "Ana", Email: "ana@example.com"}
+
+	if err := notifier.NotifyPasswordReset(user, "token-de-teste", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Erro ao enviar o token de redefinição: %v", err)
+	}
+
+	messages := mailer.Messages()
+	if len(messages) != 1 || messages[0].To != "ana@example.com" || !strings.Contains(messages[0].Body, "token-de-teste") {
+		t.Errorf("Mensagem inesperada: %+v", messages)
+	}
+}
@@ -0,0 +1,34 @@
+package notification
+
+import (
+	domain "server/src/layers/domain/notification"
+	"sync"
+)
+
+// MemoryMailer guarda os e-mails em memória, para testes e ambientes sem servidor de e-mail.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []domain.Message
+}
+
+// NewMemoryMailer cria uma nova instância de MemoryMailer.
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send guarda a mensagem.
+func (m *MemoryMailer) Send(message domain.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages retorna uma cópia das mensagens enviadas, da mais antiga para a mais recente.
+func (m *MemoryMailer) Messages() []domain.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]domain.Message(nil), m.messages...)
+}
@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	domain "server/src/layers/domain/notification"
+	"strings"
+	"time"
+)
+
+var errInvalidHeader = errors.New("cabeçalho de e-mail inválido")
+
+// formatMessage monta o e-mail no formato RFC 5322, em texto puro UTF-8 e com o assunto codificado para aceitar acentos.
+// Quebras de linha no remetente, destinatário ou assunto são recusadas para impedir a injeção de cabeçalhos.
+func formatMessage(from string, message domain.Message, date time.Time) ([]byte, error) {
+	for _, header := range []string{from, message.To, message.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errInvalidHeader
+		}
+	}
+	if message.To == "" {
+		return nil, errInvalidHeader
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("From: " + from + "\r\n")
+	buffer.WriteString("To: " + message.To + "\r\n")
+	buffer.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	buffer.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buffer.WriteString("\r\n")
+
+	body := strings.ReplaceAll(message.Body, "\r\n", "\n")
+	buffer.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buffer.Bytes(), nil
+}
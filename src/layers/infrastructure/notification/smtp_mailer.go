@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"net"
+	"net/smtp"
+	domain "server/src/layers/domain/notification"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer envia os e-mails por um servidor SMTP. A conexão usa STARTTLS quando o servidor oferece,
+// e a autenticação PLAIN só é feita com TLS ou em localhost.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer cria uma nova instância de SMTPMailer; sem usuário, os e-mails são enviados sem autenticação.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+		auth: auth,
+	}
+}
+
+// Send entrega a mensagem ao servidor SMTP.
+func (m *SMTPMailer) Send(message domain.Message) error {
+	data, err := formatMessage(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{message.To}, data)
+}
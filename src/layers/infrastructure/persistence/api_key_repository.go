@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// APIKeyRepository representa o repositório de chaves de acesso pessoais.
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository cria uma nova instância de APIKeyRepository.
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+// Store insere uma nova chave de api.
+func (ar *APIKeyRepository) Store(key *models.APIKey) error {
+	return ar.db.Create(key).Error
+}
+
+// FindByHash busca uma chave de api pelo hash.
+func (ar *APIKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := ar.db.First(&key, "key_hash = ?", keyHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByUser lista as chaves do usuário, das mais recentes para as mais antigas.
+func (ar *APIKeyRepository) FindByUser(userID uuid.UUID) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	if err := ar.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marca a chave do usuário como revogada de forma atômica.
+func (ar *APIKeyRepository) Revoke(id, userID uuid.UUID, at time.Time) (bool, error) {
+	result := ar.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// TouchLastUsed registra o último uso da chave.
+func (ar *APIKeyRepository) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	return ar.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
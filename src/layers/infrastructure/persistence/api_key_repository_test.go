@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewAPIKeyRepository(db)
+	db.AutoMigrate(&models.APIKey{})
+
+	userID := uuid.New()
+	key := &models.APIKey{
+		UserID:  userID,
+		Name:    "deploy",
+		Prefix:  "ak_abcdefgh",
+		KeyHash: "hash-da-chave",
+		Scopes:  models.StringList{models.PermissionProfileRead},
+	}
+
+	t.Run("Gravar e buscar pelo hash", func(t *testing.T) {
+		if err := repo.Store(key); err != nil {
+			t.Fatalf("Erro ao gravar a chave: %v", err)
+		}
+
+		found, err := repo.FindByHash("hash-da-chave")
+		if err != nil || found.ID != key.ID || found.Scopes[0] != models.PermissionProfileRead {
+			t.Fatalf("Chave não encontrada corretamente: %v", err)
+		}
+
+		if _, err := repo.FindByHash("outro-hash"); err != repository.ErrAPIKeyNotFound {
+			t.Errorf("Esperava ErrAPIKeyNotFound, obteve %v", err)
+		}
+	})
+
+	t.Run("Registrar o último uso", func(t *testing.T) {
+		usedAt := time.Now().Truncate(time.Second)
+		if err := repo.TouchLastUsed(key.ID, usedAt); err != nil {
+			t.Fatalf("Erro ao registrar o uso: %v", err)
+		}
+
+		found, _ := repo.FindByHash("hash-da-chave")
+		if found.LastUsedAt == nil || !found.LastUsedAt.Equal(usedAt) {
+			t.Errorf("Último uso não registrado: %v", found.LastUsedAt)
+		}
+	})
+
+	t.Run("Revogar apenas as chaves do próprio usuário", func(t *testing.T) {
+		if revoked, _ := repo.Revoke(key.ID, uuid.New(), time.Now()); revoked {
+			t.Fatalf("Outro usuário não deveria revogar a chave")
+		}
+
+		if revoked, err := repo.Revoke(key.ID, userID, time.Now()); err != nil || !revoked {
+			t.Fatalf("Esperava revogar a chave, obteve: %v, %v", revoked, err)
+		}
+
+		if revoked, _ := repo.Revoke(key.ID, userID, time.Now()); revoked {
+			t.Errorf("Uma chave já revogada não deveria ser revogada novamente")
+		}
+
+		keys, _ := repo.FindByUser(userID)
+		if len(keys) != 1 || keys[0].IsActive(time.Now()) {
+			t.Errorf("Esperava uma chave inativa na listagem")
+		}
+	})
+}
@@ -22,7 +22,22 @@ func Connect() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&models.User{})
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.PasswordResetToken{},
+		&models.LoginAttempt{},
+		&models.RecoveryCode{},
+		&models.PasswordHistory{},
+		&models.APIKey{},
+		&models.OAuthClient{},
+		&models.OAuthAuthorizationCode{},
+		&models.OAuthConsent{},
+		&models.Session{},
+		&models.SignInEvent{},
+		&models.LoginCode{},
+	)
 	if err != nil {
 		log.Printf("Erro na migração automática: %v", err)
 		return nil, err
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+)
+
+// LoginAttemptRepository representa o repositório das falhas de autenticação.
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository cria uma nova instância de LoginAttemptRepository.
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db: db,
+	}
+}
+
+// Find busca as tentativas registradas para a chave.
+func (lr *LoginAttemptRepository) Find(key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	if err := lr.db.First(&attempt, "key = ?", key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrLoginAttemptNotFound
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Create insere as tentativas da chave. Se outra requisição já criou o registro, nada é gravado e retorna false.
+func (lr *LoginAttemptRepository) Create(attempt *models.LoginAttempt) (bool, error) {
+	result := lr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(attempt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Update grava as tentativas em uma única atualização condicional, que só se aplica se o registro ainda
+// estiver na revisão lida. Assim, duas falhas simultâneas na mesma chave não sobrescrevem uma à outra.
+func (lr *LoginAttemptRepository) Update(attempt *models.LoginAttempt) (bool, error) {
+	result := lr.db.Model(&models.LoginAttempt{}).
+		Where("key = ? AND revision = ?", attempt.Key, attempt.Revision).
+		Updates(map[string]interface{}{
+			"failures":        attempt.Failures,
+			"last_failure_at": attempt.LastFailureAt,
+			"locked_until":    attempt.LockedUntil,
+			"revision":        attempt.Revision + 1,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return false, nil
+	}
+	attempt.Revision++
+	return true, nil
+}
+
+// Delete remove as tentativas da chave.
+func (lr *LoginAttemptRepository) Delete(key string) error {
+	return lr.db.Unscoped().Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewLoginAttemptRepository(db)
+	db.AutoMigrate(&models.LoginAttempt{})
+
+	t.Run("Criar e atualizar tentativas", func(t *testing.T) {
+		attempt := &models.LoginAttempt{Key: "account:12345678900", Failures: 1, LastFailureAt: time.Now()}
+		if created, err := repo.Create(attempt); err != nil || !created {
+			t.Fatalf("Erro ao criar as tentativas: %v", err)
+		}
+
+		if created, err := repo.Create(&models.LoginAttempt{Key: attempt.Key, Failures: 1}); err != nil || created {
+			t.Fatalf("Esperado que a chave existente não fosse criada de novo, obteve %v (%v)", created, err)
+		}
+
+		found, err := repo.Find(attempt.Key)
+		if err != nil {
+			t.Fatalf("Erro ao buscar as tentativas: %v", err)
+		}
+
+		found.Failures = 2
+		if updated, err := repo.Update(found); err != nil || !updated {
+			t.Fatalf("Erro ao atualizar as tentativas: %v", err)
+		}
+
+		updated, _ := repo.Find(attempt.Key)
+		if updated.Failures != 2 || updated.ID != attempt.ID || updated.Revision != found.Revision {
+			t.Errorf("Esperado o mesmo registro com 2 falhas na revisão %d, obteve %d falhas na revisão %d", found.Revision, updated.Failures, updated.Revision)
+		}
+	})
+
+	t.Run("Atualização com revisão desatualizada", func(t *testing.T) {
+		first, _ := repo.Find("account:12345678900")
+		second, _ := repo.Find("account:12345678900")
+
+		first.Failures++
+		if updated, err := repo.Update(first); err != nil || !updated {
+			t.Fatalf("Erro ao atualizar as tentativas: %v", err)
+		}
+
+		second.Failures++
+		if updated, err := repo.Update(second); err != nil || updated {
+			t.Fatalf("Esperado que a leitura desatualizada não sobrescrevesse o registro, obteve %v (%v)", updated, err)
+		}
+
+		found, _ := repo.Find("account:12345678900")
+		if found.Failures != 3 {
+			t.Errorf("Esperado 3 falhas, obteve %d", found.Failures)
+		}
+	})
+
+	t.Run("Remover tentativas", func(t *testing.T) {
+		if err := repo.Delete("account:12345678900"); err != nil {
+			t.Fatalf("Erro ao remover as tentativas: %v", err)
+		}
+
+		if _, err := repo.Find("account:12345678900"); err != repository.ErrLoginAttemptNotFound {
+			t.Errorf("Esperado erro de tentativas não encontradas, obteve: %v", err)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// LoginCodeRepository representa o repositório de pedidos de login sem senha.
+type LoginCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginCodeRepository cria uma nova instância de LoginCodeRepository.
+func NewLoginCodeRepository(db *gorm.DB) *LoginCodeRepository {
+	return &LoginCodeRepository{
+		db: db,
+	}
+}
+
+// Store insere um novo pedido de login.
+func (lr *LoginCodeRepository) Store(code *models.LoginCode) error {
+	return lr.db.Create(code).Error
+}
+
+// FindByTokenHash busca um pedido de login pelo hash do token do link.
+func (lr *LoginCodeRepository) FindByTokenHash(tokenHash string) (*models.LoginCode, error) {
+	var code models.LoginCode
+	if err := lr.db.First(&code, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrLoginCodeNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// FindActiveByUser busca o pedido mais recente do usuário que ainda não foi usado nem expirou.
+func (lr *LoginCodeRepository) FindActiveByUser(userID uuid.UUID, now time.Time) (*models.LoginCode, error) {
+	var code models.LoginCode
+	err := lr.db.
+		Where("user_id = ? AND used_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrLoginCodeNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// CountSince conta os pedidos do usuário criados a partir de since.
+func (lr *LoginCodeRepository) CountSince(userID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := lr.db.Model(&models.LoginCode{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// RecordAttempt incrementa de forma atômica as tentativas do pedido e retorna o novo total.
+func (lr *LoginCodeRepository) RecordAttempt(id uuid.UUID) (int, error) {
+	err := lr.db.Model(&models.LoginCode{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var code models.LoginCode
+	if err := lr.db.Select("attempts").First(&code, "id = ?", id).Error; err != nil {
+		return 0, err
+	}
+	return code.Attempts, nil
+}
+
+// MarkUsed marca o pedido como usado de forma atômica.
+func (lr *LoginCodeRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := lr.db.Model(&models.LoginCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateForUser marca como usados os pedidos pendentes do usuário.
+func (lr *LoginCodeRepository) InvalidateForUser(userID uuid.UUID) error {
+	return lr.db.Model(&models.LoginCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func newLoginCode(userID uuid.UUID, expiresAt time.Time) *models.LoginCode {
+	return &models.LoginCode{
+		UserID:    userID,
+		TokenHash: shared.HashToken(uuid.NewString()),
+		CodeHash:  shared.HashToken("123456"),
+		ExpiresAt: expiresAt,
+	}
+}
+
+func TestLoginCodeRepository_FindActiveByUser(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewLoginCodeRepository(db)
+	db.AutoMigrate(&models.LoginCode{})
+
+	userID := uuid.New()
+	now := time.Now()
+	repo.Store(newLoginCode(userID, now.Add(-time.Minute)))
+	older := newLoginCode(userID, now.Add(5*time.Minute))
+	repo.Store(older)
+	latest := newLoginCode(userID, now.Add(5*time.Minute))
+	latest.CreatedAt = older.CreatedAt.Add(time.Second)
+	repo.Store(latest)
+
+	found, err := repo.FindActiveByUser(userID, now)
+	if err != nil || found.ID != latest.ID {
+		t.Fatalf("Esperava o pedido mais recente, obteve: %v, %v", found, err)
+	}
+
+	found, err = repo.FindByTokenHash(older.TokenHash)
+	if err != nil || found.ID != older.ID {
+		t.Fatalf("Erro ao buscar o pedido pelo hash do token: %v", err)
+	}
+
+	if err := repo.InvalidateForUser(userID); err != nil {
+		t.Fatalf("Erro ao invalidar os pedidos: %v", err)
+	}
+	if _, err := repo.FindActiveByUser(userID, now); err != repository.ErrLoginCodeNotFound {
+		t.Fatalf("Esperava nenhum pedido ativo após a invalidação, obteve: %v", err)
+	}
+
+	count, err := repo.CountSince(userID, now.Add(-time.Hour))
+	if err != nil || count != 3 {
+		t.Fatalf("Esperava 3 pedidos na janela, obteve: %d, %v", count, err)
+	}
+}
+
+func TestLoginCodeRepository_AttemptsAndMarkUsed(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewLoginCodeRepository(db)
+	db.AutoMigrate(&models.LoginCode{})
+
+	code := newLoginCode(uuid.New(), time.Now().Add(5*time.Minute))
+	repo.Store(code)
+
+	for expected := 1; expected <= 2; expected++ {
+		attempts, err := repo.RecordAttempt(code.ID)
+		if err != nil || attempts != expected {
+			t.Fatalf("Esperava %d tentativas, obteve: %d, %v", expected, attempts, err)
+		}
+	}
+
+	if marked, err := repo.MarkUsed(code.ID); err != nil || !marked {
+		t.Fatalf("Esperava marcar o pedido como usado, obteve: %v, %v", marked, err)
+	}
+	if marked, _ := repo.MarkUsed(code.ID); marked {
+		t.Fatal("Esperava que o pedido não pudesse ser usado duas vezes")
+	}
+}
@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+type revokedUser struct {
+	issuedBeforeMilli int64
+	expiresAt         time.Time
+}
+
+// MemoryTokenRevocationRepository mantém as revogações em memória, útil para testes e instâncias únicas.
+type MemoryTokenRevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[uuid.UUID]revokedUser
+}
+
+// NewMemoryTokenRevocationRepository cria uma nova instância de MemoryTokenRevocationRepository.
+func NewMemoryTokenRevocationRepository() *MemoryTokenRevocationRepository {
+	return &MemoryTokenRevocationRepository{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]revokedUser),
+	}
+}
+
+// Revoke registra a revogação de um token pelo jti.
+func (mr *MemoryTokenRevocationRepository) Revoke(tokenID string, _ uuid.UUID, expiresAt time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.tokens[tokenID] = expiresAt
+	return nil
+}
+
+// RevokeAllForUser registra a revogação de todos os tokens do usuário emitidos antes de issuedBefore.
+func (mr *MemoryTokenRevocationRepository) RevokeAllForUser(userID uuid.UUID, issuedBefore time.Time, expiresAt time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	current, exists := mr.users[userID]
+	if exists && current.issuedBeforeMilli > issuedBefore.UnixMilli() {
+		return nil
+	}
+	mr.users[userID] = revokedUser{issuedBeforeMilli: issuedBefore.UnixMilli(), expiresAt: expiresAt}
+	return nil
+}
+
+// IsRevoked verifica se o token ou todas as sessões do usuário foram revogados.
+func (mr *MemoryTokenRevocationRepository) IsRevoked(tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	if _, exists := mr.tokens[tokenID]; exists {
+		return true, nil
+	}
+	if revoked, exists := mr.users[userID]; exists && revoked.issuedBeforeMilli > issuedAt.UnixMilli() {
+		return true, nil
+	}
+	return false, nil
+}
+
+// PurgeExpired remove as revogações de tokens que já expiraram.
+func (mr *MemoryTokenRevocationRepository) PurgeExpired(now time.Time) (int64, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	var purged int64
+	for tokenID, expiresAt := range mr.tokens {
+		if expiresAt.Before(now) {
+			delete(mr.tokens, tokenID)
+			purged++
+		}
+	}
+	for userID, revoked := range mr.users {
+		if revoked.expiresAt.Before(now) {
+			delete(mr.users, userID)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// AuthorizationCodeRepository representa o repositório de códigos de autorização OAuth.
+type AuthorizationCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthorizationCodeRepository cria uma nova instância de AuthorizationCodeRepository.
+func NewAuthorizationCodeRepository(db *gorm.DB) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{
+		db: db,
+	}
+}
+
+// Store insere um novo código de autorização.
+func (ar *AuthorizationCodeRepository) Store(code *models.OAuthAuthorizationCode) error {
+	return ar.db.Create(code).Error
+}
+
+// FindByHash busca um código de autorização pelo hash.
+func (ar *AuthorizationCodeRepository) FindByHash(codeHash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	if err := ar.db.First(&code, "code_hash = ?", codeHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrAuthorizationCodeNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// MarkUsed marca o código como usado de forma atômica.
+func (ar *AuthorizationCodeRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := ar.db.Model(&models.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
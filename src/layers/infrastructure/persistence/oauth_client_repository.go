@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+)
+
+// OAuthClientRepository representa o repositório de clientes OAuth.
+type OAuthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository cria uma nova instância de OAuthClientRepository.
+func NewOAuthClientRepository(db *gorm.DB) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		db: db,
+	}
+}
+
+// Store insere um novo cliente OAuth.
+func (cr *OAuthClientRepository) Store(client *models.OAuthClient) error {
+	return cr.db.Create(client).Error
+}
+
+// FindByClientID busca um cliente pelo client_id.
+func (cr *OAuthClientRepository) FindByClientID(clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := cr.db.First(&client, "client_id = ?", clientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// FindAll lista os clientes cadastrados.
+func (cr *OAuthClientRepository) FindAll() ([]*models.OAuthClient, error) {
+	var clients []*models.OAuthClient
+	if err := cr.db.Order("created_at").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// Delete remove o cliente pelo client_id.
+func (cr *OAuthClientRepository) Delete(clientID string) error {
+	result := cr.db.Where("client_id = ?", clientID).Delete(&models.OAuthClient{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrOAuthClientNotFound
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+)
+
+// ConsentRepository representa o repositório de consentimentos OAuth.
+type ConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewConsentRepository cria uma nova instância de ConsentRepository.
+func NewConsentRepository(db *gorm.DB) *ConsentRepository {
+	return &ConsentRepository{
+		db: db,
+	}
+}
+
+// Find busca o consentimento do usuário para o cliente.
+func (cr *ConsentRepository) Find(userID uuid.UUID, clientID string) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+	if err := cr.db.First(&consent, "user_id = ? AND client_id = ?", userID, clientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrConsentNotFound
+		}
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// Save insere o consentimento ou atualiza os escopos do consentimento existente.
+func (cr *ConsentRepository) Save(consent *models.OAuthConsent) error {
+	existing, err := cr.Find(consent.UserID, consent.ClientID)
+	if errors.Is(err, repository.ErrConsentNotFound) {
+		return cr.db.Create(consent).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	consent.ID = existing.ID
+	return cr.db.Model(existing).Update("scopes", consent.Scopes).Error
+}
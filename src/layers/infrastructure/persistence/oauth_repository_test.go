@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func TestOAuthClientRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewOAuthClientRepository(db)
+	db.AutoMigrate(&models.OAuthClient{})
+
+	client := &models.OAuthClient{
+		ClientID:     "spa-" + uuid.NewString(),
+		Name:         "SPA",
+		RedirectURIs: models.StringList{"https://app.example.com/callback"},
+		Scopes:       models.StringList{models.PermissionProfileRead},
+		GrantTypes:   models.StringList{"authorization_code"},
+		Public:       true,
+	}
+
+	if err := repo.Store(client); err != nil {
+		t.Fatalf("Erro ao gravar o cliente: %v", err)
+	}
+
+	found, err := repo.FindByClientID(client.ClientID)
+	if err != nil || !found.AllowsRedirectURI("https://app.example.com/callback") || !found.AllowsGrant("authorization_code") {
+		t.Fatalf("Cliente não encontrado corretamente: %v", err)
+	}
+
+	if err := repo.Delete(client.ClientID); err != nil {
+		t.Fatalf("Erro ao remover o cliente: %v", err)
+	}
+	if _, err := repo.FindByClientID(client.ClientID); err != repository.ErrOAuthClientNotFound {
+		t.Errorf("Esperava ErrOAuthClientNotFound, obteve %v", err)
+	}
+	if err := repo.Delete(client.ClientID); err != repository.ErrOAuthClientNotFound {
+		t.Errorf("Esperava ErrOAuthClientNotFound ao remover novamente, obteve %v", err)
+	}
+}
+
+func TestAuthorizationCodeRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewAuthorizationCodeRepository(db)
+	db.AutoMigrate(&models.OAuthAuthorizationCode{})
+
+	code := &models.OAuthAuthorizationCode{
+		CodeHash:  "hash-" + uuid.NewString(),
+		ClientID:  "spa",
+		UserID:    uuid.New(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	if err := repo.Store(code); err != nil {
+		t.Fatalf("Erro ao gravar o código: %v", err)
+	}
+
+	found, err := repo.FindByHash(code.CodeHash)
+	if err != nil || !found.IsUsable(time.Now()) {
+		t.Fatalf("Código não encontrado corretamente: %v", err)
+	}
+
+	if marked, err := repo.MarkUsed(code.ID); err != nil || !marked {
+		t.Fatalf("Esperava marcar o código como usado, obteve: %v, %v", marked, err)
+	}
+	if marked, _ := repo.MarkUsed(code.ID); marked {
+		t.Errorf("Um código já usado não deveria ser marcado novamente.")
+	}
+}
+
+func TestConsentRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewConsentRepository(db)
+	db.AutoMigrate(&models.OAuthConsent{})
+
+	userID := uuid.New()
+
+	if _, err := repo.Find(userID, "spa"); err != repository.ErrConsentNotFound {
+		t.Fatalf("Esperava ErrConsentNotFound, obteve %v", err)
+	}
+
+	repo.Save(&models.OAuthConsent{UserID: userID, ClientID: "spa", Scopes: models.StringList{"profile:read"}})
+	if err := repo.Save(&models.OAuthConsent{UserID: userID, ClientID: "spa", Scopes: models.StringList{"profile:read", "users:read"}}); err != nil {
+		t.Fatalf("Erro ao atualizar o consentimento: %v", err)
+	}
+
+	consent, err := repo.Find(userID, "spa")
+	if err != nil || len(consent.Scopes) != 2 {
+		t.Fatalf("Esperava o consentimento atualizado com 2 escopos, obteve %v (%v)", consent, err)
+	}
+}
@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+)
+
+// PasswordHistoryRepository representa o repositório de senhas anteriores dos usuários.
+type PasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository cria uma nova instância de PasswordHistoryRepository.
+func NewPasswordHistoryRepository(db *gorm.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{
+		db: db,
+	}
+}
+
+// Store grava o hash de uma senha anterior.
+func (hr *PasswordHistoryRepository) Store(entry *models.PasswordHistory) error {
+	return hr.db.Create(entry).Error
+}
+
+// FindRecentByUser busca as senhas anteriores mais recentes do usuário.
+func (hr *PasswordHistoryRepository) FindRecentByUser(userID uuid.UUID, limit int) ([]*models.PasswordHistory, error) {
+	var entries []*models.PasswordHistory
+	err := hr.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PruneForUser remove as senhas anteriores que excedem as keep mais recentes do usuário.
+func (hr *PasswordHistoryRepository) PruneForUser(userID uuid.UUID, keep int) error {
+	if keep <= 0 {
+		return hr.db.Where("user_id = ?", userID).Delete(&models.PasswordHistory{}).Error
+	}
+
+	recent := hr.db.Model(&models.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(keep)
+
+	return hr.db.Where("user_id = ? AND id NOT IN (?)", userID, recent).Delete(&models.PasswordHistory{}).Error
+}
@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPasswordHistoryRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewPasswordHistoryRepository(db)
+	db.AutoMigrate(&models.PasswordHistory{})
+
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	start := time.Now().Add(-time.Hour)
+
+	for i, hash := range []string{"h1", "h2", "h3", "h4"} {
+		entry := &models.PasswordHistory{UserID: userID, Password: hash}
+		entry.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		if err := repo.Store(entry); err != nil {
+			t.Fatalf("Erro ao gravar o histórico: %v", err)
+		}
+	}
+	repo.Store(&models.PasswordHistory{UserID: otherUserID, Password: "outro"})
+
+	t.Run("Buscar as senhas mais recentes", func(t *testing.T) {
+		entries, err := repo.FindRecentByUser(userID, 2)
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("Esperava 2 senhas, obteve %d (%v)", len(entries), err)
+		}
+		if entries[0].Password != "h4" || entries[1].Password != "h3" {
+			t.Errorf("Ordem inesperada: %s, %s", entries[0].Password, entries[1].Password)
+		}
+	})
+
+	t.Run("Manter apenas as mais recentes", func(t *testing.T) {
+		if err := repo.PruneForUser(userID, 2); err != nil {
+			t.Fatalf("Erro ao podar o histórico: %v", err)
+		}
+
+		entries, _ := repo.FindRecentByUser(userID, 10)
+		if len(entries) != 2 || entries[0].Password != "h4" || entries[1].Password != "h3" {
+			t.Fatalf("Esperava manter h4 e h3, obteve %d senhas", len(entries))
+		}
+
+		others, _ := repo.FindRecentByUser(otherUserID, 10)
+		if len(others) != 1 {
+			t.Errorf("O histórico de outro usuário não deveria ser alterado")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// PasswordResetRepository representa o repositório de tokens de redefinição de senha.
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository cria uma nova instância de PasswordResetRepository.
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+// Store insere um novo token de redefinição de senha.
+func (pr *PasswordResetRepository) Store(token *models.PasswordResetToken) error {
+	return pr.db.Create(token).Error
+}
+
+// FindByHash busca um token de redefinição pelo hash.
+func (pr *PasswordResetRepository) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := pr.db.First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrPasswordResetTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marca o token como usado de forma atômica.
+func (pr *PasswordResetRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := pr.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateForUser marca como usados os tokens pendentes do usuário.
+func (pr *PasswordResetRepository) InvalidateForUser(userID uuid.UUID) error {
+	return pr.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
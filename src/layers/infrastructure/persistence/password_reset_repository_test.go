@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func newPasswordResetToken(userID uuid.UUID) *models.PasswordResetToken {
+	return &models.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: shared.HashToken(uuid.NewString()),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestPasswordResetRepository_StoreAndFind(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewPasswordResetRepository(db)
+	db.AutoMigrate(&models.PasswordResetToken{})
+
+	token := newPasswordResetToken(uuid.New())
+	if err := repo.Store(token); err != nil {
+		t.Fatalf("Erro ao armazenar o token: %v", err)
+	}
+
+	found, err := repo.FindByHash(token.TokenHash)
+	if err != nil || found.UserID != token.UserID {
+		t.Fatalf("Erro ao buscar o token pelo hash: %v", err)
+	}
+
+	if _, err := repo.FindByHash(shared.HashToken("inexistente")); err != repository.ErrPasswordResetTokenNotFound {
+		t.Fatalf("Esperado erro de token não encontrado, mas obteve: %v", err)
+	}
+}
+
+func TestPasswordResetRepository_MarkUsed(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewPasswordResetRepository(db)
+	db.AutoMigrate(&models.PasswordResetToken{})
+
+	token := newPasswordResetToken(uuid.New())
+	repo.Store(token)
+
+	if marked, err := repo.MarkUsed(token.ID); err != nil || !marked {
+		t.Fatalf("Esperava marcar o token como usado, obteve: %v, %v", marked, err)
+	}
+
+	if marked, _ := repo.MarkUsed(token.ID); marked {
+		t.Fatalf("Um token já usado não deveria ser marcado novamente.")
+	}
+}
+
+func TestPasswordResetRepository_InvalidateForUser(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewPasswordResetRepository(db)
+	db.AutoMigrate(&models.PasswordResetToken{})
+
+	userID := uuid.New()
+	token := newPasswordResetToken(userID)
+	repo.Store(token)
+
+	if err := repo.InvalidateForUser(userID); err != nil {
+		t.Fatalf("Erro ao invalidar os tokens do usuário: %v", err)
+	}
+
+	found, _ := repo.FindByHash(token.TokenHash)
+	if found.IsUsable(time.Now()) {
+		t.Fatalf("Token pendente do usuário deveria ter sido invalidado.")
+	}
+}
@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"time"
+)
+
+// RecoveryCodeRepository representa o repositório de códigos de recuperação do segundo fator.
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository cria uma nova instância de RecoveryCodeRepository.
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db: db,
+	}
+}
+
+// ReplaceForUser remove os códigos do usuário e insere os novos em uma única transação.
+func (rr *RecoveryCodeRepository) ReplaceForUser(userID uuid.UUID, codes []*models.RecoveryCode) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		for _, code := range codes {
+			code.UserID = userID
+			if err := tx.Create(code).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindUnusedByUser busca os códigos ainda não usados do usuário.
+func (rr *RecoveryCodeRepository) FindUnusedByUser(userID uuid.UUID) ([]*models.RecoveryCode, error) {
+	var codes []*models.RecoveryCode
+	if err := rr.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// MarkUsed marca o código como usado de forma atômica.
+func (rr *RecoveryCodeRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := rr.db.Model(&models.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
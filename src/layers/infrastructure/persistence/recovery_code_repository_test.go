@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"testing"
+)
+
+func TestRecoveryCodeRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewRecoveryCodeRepository(db)
+	db.AutoMigrate(&models.RecoveryCode{})
+
+	userID := uuid.New()
+
+	t.Run("Substituir os códigos do usuário", func(t *testing.T) {
+		repo.ReplaceForUser(userID, []*models.RecoveryCode{{CodeHash: "a"}, {CodeHash: "b"}})
+		err := repo.ReplaceForUser(userID, []*models.RecoveryCode{{CodeHash: "c"}, {CodeHash: "d"}, {CodeHash: "e"}})
+		if err != nil {
+			t.Fatalf("Erro ao substituir os códigos: %v", err)
+		}
+
+		codes, err := repo.FindUnusedByUser(userID)
+		if err != nil || len(codes) != 3 {
+			t.Fatalf("Esperava 3 códigos, obteve %d (%v)", len(codes), err)
+		}
+	})
+
+	t.Run("Marcar código como usado", func(t *testing.T) {
+		codes, _ := repo.FindUnusedByUser(userID)
+
+		if marked, err := repo.MarkUsed(codes[0].ID); err != nil || !marked {
+			t.Fatalf("Esperava marcar o código como usado, obteve: %v, %v", marked, err)
+		}
+
+		if marked, _ := repo.MarkUsed(codes[0].ID); marked {
+			t.Fatalf("Um código já usado não deveria ser marcado novamente.")
+		}
+
+		remaining, _ := repo.FindUnusedByUser(userID)
+		if len(remaining) != 2 {
+			t.Errorf("Esperava 2 códigos restantes, obteve %d", len(remaining))
+		}
+	})
+}
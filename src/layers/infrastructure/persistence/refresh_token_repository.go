@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// RefreshTokenRepository representa o repositório de refresh tokens.
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository cria uma nova instância de RefreshTokenRepository.
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Store insere um novo refresh token.
+func (rr *RefreshTokenRepository) Store(token *models.RefreshToken) error {
+	return rr.db.Create(token).Error
+}
+
+// FindByTokenID busca um refresh token pelo identificador (jti).
+func (rr *RefreshTokenRepository) FindByTokenID(tokenID string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := rr.db.First(&token, "token_id = ?", tokenID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marca o refresh token como usado de forma atômica.
+func (rr *RefreshTokenRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := rr.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily revoga todos os refresh tokens ainda ativos da família.
+func (rr *RefreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return rr.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revoga todos os refresh tokens ainda ativos do usuário.
+func (rr *RefreshTokenRepository) RevokeAllForUser(userID uuid.UUID) error {
+	return rr.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func newRefreshToken(familyID uuid.UUID) *models.RefreshToken {
+	return &models.RefreshToken{
+		UserID:    uuid.New(),
+		FamilyID:  familyID,
+		TokenID:   uuid.NewString(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestRefreshTokenRepository_StoreAndFind(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewRefreshTokenRepository(db)
+	db.AutoMigrate(&models.RefreshToken{})
+
+	token := newRefreshToken(uuid.New())
+	if err := repo.Store(token); err != nil {
+		t.Fatalf("Erro ao armazenar o refresh token: %v", err)
+	}
+
+	t.Run("Find by valid token ID", func(t *testing.T) {
+		found, err := repo.FindByTokenID(token.TokenID)
+		if err != nil {
+			t.Fatalf("Erro ao buscar o refresh token: %v", err)
+		}
+		if found.FamilyID != token.FamilyID {
+			t.Fatalf("Família do refresh token não corresponde ao esperado.")
+		}
+	})
+
+	t.Run("Find by invalid token ID", func(t *testing.T) {
+		_, err := repo.FindByTokenID(uuid.NewString())
+		if err != repository.ErrRefreshTokenNotFound {
+			t.Fatalf("Esperado erro de refresh token não encontrado, mas obteve: %v", err)
+		}
+	})
+}
+
+func TestRefreshTokenRepository_MarkUsed(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewRefreshTokenRepository(db)
+	db.AutoMigrate(&models.RefreshToken{})
+
+	token := newRefreshToken(uuid.New())
+	repo.Store(token)
+
+	marked, err := repo.MarkUsed(token.ID)
+	if err != nil || !marked {
+		t.Fatalf("Esperava marcar o refresh token como usado, obteve: %v, %v", marked, err)
+	}
+
+	marked, err = repo.MarkUsed(token.ID)
+	if err != nil {
+		t.Fatalf("Erro ao marcar o refresh token: %v", err)
+	}
+	if marked {
+		t.Fatalf("Um refresh token já usado não deveria ser marcado novamente.")
+	}
+}
+
+func TestRefreshTokenRepository_RevokeFamily(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewRefreshTokenRepository(db)
+	db.AutoMigrate(&models.RefreshToken{})
+
+	familyID := uuid.New()
+	first := newRefreshToken(familyID)
+	second := newRefreshToken(familyID)
+	other := newRefreshToken(uuid.New())
+	repo.Store(first)
+	repo.Store(second)
+	repo.Store(other)
+
+	if err := repo.RevokeFamily(familyID); err != nil {
+		t.Fatalf("Erro ao revogar a família: %v", err)
+	}
+
+	for _, token := range []*models.RefreshToken{first, second} {
+		found, _ := repo.FindByTokenID(token.TokenID)
+		if found.RevokedAt == nil {
+			t.Fatalf("Refresh token da família deveria estar revogado.")
+		}
+	}
+
+	found, _ := repo.FindByTokenID(other.TokenID)
+	if found.RevokedAt != nil {
+		t.Fatalf("Refresh token de outra família não deveria ser revogado.")
+	}
+}
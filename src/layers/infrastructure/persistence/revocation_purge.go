@@ -0,0 +1,34 @@
+package persistence
+
+import (
+	"log"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// StartRevocationPurge remove periodicamente as revogações expiradas e retorna uma função para interromper a rotina.
+func StartRevocationPurge(repo repository.TokenRevocationRepository, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				purged, err := repo.PurgeExpired(time.Now())
+				if err != nil {
+					log.Printf("falha ao remover revogações expiradas: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("%d revogações expiradas removidas", purged)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// SessionRepository representa o repositório de sessões de login.
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository cria uma nova instância de SessionRepository.
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// Store insere uma nova sessão.
+func (sr *SessionRepository) Store(session *models.Session) error {
+	return sr.db.Create(session).Error
+}
+
+// FindByID busca uma sessão pelo ID.
+func (sr *SessionRepository) FindByID(id uuid.UUID) (*models.Session, error) {
+	return sr.find("id = ?", id)
+}
+
+// FindByFamilyID busca a sessão da família de refresh tokens.
+func (sr *SessionRepository) FindByFamilyID(familyID uuid.UUID) (*models.Session, error) {
+	return sr.find("family_id = ?", familyID)
+}
+
+func (sr *SessionRepository) find(query string, args ...interface{}) (*models.Session, error) {
+	var session models.Session
+	if err := sr.db.First(&session, append([]interface{}{query}, args...)...).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveByUser lista as sessões ativas do usuário, das usadas mais recentemente para as mais antigas.
+// Sessões cuja família foi revogada, rotacionada até expirar ou encerrada pelo logout não são listadas.
+func (sr *SessionRepository) FindActiveByUser(userID uuid.UUID, now time.Time) ([]*models.Session, error) {
+	activeFamilies := sr.db.Model(&models.RefreshToken{}).
+		Select("family_id").
+		Where("used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+
+	var sessions []*models.Session
+	err := sr.db.
+		Where("user_id = ? AND revoked_at IS NULL AND family_id IN (?)", userID, activeFamilies).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Touch registra a última atividade da sessão.
+func (sr *SessionRepository) Touch(id uuid.UUID, at time.Time) error {
+	return sr.db.Model(&models.Session{}).Where("id = ?", id).Update("last_seen_at", at).Error
+}
+
+// Revoke marca a sessão como revogada de forma atômica.
+func (sr *SessionRepository) Revoke(id uuid.UUID, at time.Time) (bool, error) {
+	result := sr.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func TestSessionRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewSessionRepository(db)
+	tokens := NewRefreshTokenRepository(db)
+	db.AutoMigrate(&models.Session{}, &models.RefreshToken{})
+
+	now := time.Now()
+	userID := uuid.New()
+
+	newSession := func(expiresAt time.Time) *models.Session {
+		session := &models.Session{UserID: userID, FamilyID: uuid.New(), IP: "10.0.0.1", UserAgent: "curl/8.0", LastSeenAt: now}
+		if err := repo.Store(session); err != nil {
+			t.Fatalf("Erro ao gravar a sessão: %v", err)
+		}
+		tokens.Store(&models.RefreshToken{UserID: userID, FamilyID: session.FamilyID, TokenID: uuid.NewString(), ExpiresAt: expiresAt})
+		return session
+	}
+
+	active := newSession(now.Add(time.Hour))
+	newSession(now.Add(-time.Hour)) // família expirada
+	signedOut := newSession(now.Add(time.Hour))
+	tokens.RevokeFamily(signedOut.FamilyID)
+
+	t.Run("Buscar pela família", func(t *testing.T) {
+		found, err := repo.FindByFamilyID(active.FamilyID)
+		if err != nil || found.ID != active.ID {
+			t.Fatalf("Sessão não encontrada corretamente: %v", err)
+		}
+
+		if _, err := repo.FindByFamilyID(uuid.New()); err != repository.ErrSessionNotFound {
+			t.Errorf("Esperava ErrSessionNotFound, obteve %v", err)
+		}
+	})
+
+	t.Run("Listar apenas as sessões ativas", func(t *testing.T) {
+		sessions, err := repo.FindActiveByUser(userID, now)
+		if err != nil || len(sessions) != 1 || sessions[0].ID != active.ID {
+			t.Fatalf("Esperava apenas a sessão ativa, obteve %d sessões (%v)", len(sessions), err)
+		}
+	})
+
+	t.Run("Registrar a última atividade", func(t *testing.T) {
+		seenAt := now.Add(time.Minute).Truncate(time.Second)
+		if err := repo.Touch(active.ID, seenAt); err != nil {
+			t.Fatalf("Erro ao registrar a atividade: %v", err)
+		}
+
+		found, _ := repo.FindByID(active.ID)
+		if !found.LastSeenAt.Equal(seenAt) {
+			t.Errorf("Última atividade não registrada: %v", found.LastSeenAt)
+		}
+	})
+
+	t.Run("Revogar a sessão", func(t *testing.T) {
+		if revoked, err := repo.Revoke(active.ID, now); err != nil || !revoked {
+			t.Fatalf("Esperava revogar a sessão, obteve: %v, %v", revoked, err)
+		}
+		if revoked, _ := repo.Revoke(active.ID, now); revoked {
+			t.Errorf("Uma sessão já revogada não deveria ser revogada novamente")
+		}
+
+		if sessions, _ := repo.FindActiveByUser(userID, now); len(sessions) != 0 {
+			t.Errorf("Uma sessão revogada não deveria ser listada")
+		}
+	})
+}
+
+func TestSignInHistoryRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewSignInHistoryRepository(db)
+	db.AutoMigrate(&models.SignInEvent{})
+
+	userID := uuid.New()
+	for i := 0; i < 3; i++ {
+		repo.Store(&models.SignInEvent{UserID: userID, Method: models.SignInMethodPassword, FailureReason: models.SignInFailureInvalidPassword})
+	}
+	repo.Store(&models.SignInEvent{UserID: userID, Method: models.SignInMethodPassword, Success: true})
+	repo.Store(&models.SignInEvent{UserID: uuid.New(), Method: models.SignInMethodPassword, Success: true})
+
+	events, total, err := repo.FindByUser(userID, 2, 0)
+	if err != nil || total != 4 || len(events) != 2 {
+		t.Fatalf("Esperava 2 de 4 eventos, obteve %d de %d (%v)", len(events), total, err)
+	}
+	if !events[0].Success {
+		t.Errorf("O evento mais recente deveria vir primeiro")
+	}
+
+	events, _, _ = repo.FindByUser(userID, 2, 2)
+	if len(events) != 2 || events[0].Success {
+		t.Errorf("A segunda página deveria conter as tentativas mais antigas")
+	}
+}
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+)
+
+// SignInHistoryRepository representa o repositório do histórico de logins.
+type SignInHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewSignInHistoryRepository cria uma nova instância de SignInHistoryRepository.
+func NewSignInHistoryRepository(db *gorm.DB) *SignInHistoryRepository {
+	return &SignInHistoryRepository{
+		db: db,
+	}
+}
+
+// Store insere um evento de login.
+func (hr *SignInHistoryRepository) Store(event *models.SignInEvent) error {
+	return hr.db.Create(event).Error
+}
+
+// FindByUser retorna uma página do histórico do usuário e o total de eventos.
+func (hr *SignInHistoryRepository) FindByUser(userID uuid.UUID, limit, offset int) ([]*models.SignInEvent, int64, error) {
+	var total int64
+	if err := hr.db.Model(&models.SignInEvent{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*models.SignInEvent
+	err := hr.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"time"
+)
+
+// TokenRevocationRepository representa o repositório de tokens revogados no banco de dados.
+type TokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRevocationRepository cria uma nova instância de TokenRevocationRepository.
+func NewTokenRevocationRepository(db *gorm.DB) *TokenRevocationRepository {
+	return &TokenRevocationRepository{
+		db: db,
+	}
+}
+
+// Revoke registra a revogação de um token pelo jti.
+func (tr *TokenRevocationRepository) Revoke(tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	return tr.db.Create(&models.RevokedToken{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// RevokeAllForUser registra a revogação de todos os tokens do usuário emitidos antes de issuedBefore.
+func (tr *TokenRevocationRepository) RevokeAllForUser(userID uuid.UUID, issuedBefore time.Time, expiresAt time.Time) error {
+	return tr.db.Create(&models.RevokedToken{
+		UserID:            userID,
+		IssuedBeforeMilli: issuedBefore.UnixMilli(),
+		ExpiresAt:         expiresAt,
+	}).Error
+}
+
+// IsRevoked verifica se o token ou todas as sessões do usuário foram revogados.
+func (tr *TokenRevocationRepository) IsRevoked(tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	var count int64
+	err := tr.db.Model(&models.RevokedToken{}).
+		Where("token_id = ? AND token_id <> ''", tokenID).
+		Or("token_id = '' AND user_id = ? AND issued_before_milli > ?", userID, issuedAt.UnixMilli()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PurgeExpired remove as revogações de tokens que já expiraram.
+func (tr *TokenRevocationRepository) PurgeExpired(now time.Time) (int64, error) {
+	result := tr.db.Where("expires_at < ?", now).Delete(&models.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
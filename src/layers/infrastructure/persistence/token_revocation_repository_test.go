@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+	"time"
+)
+
+func testTokenRevocationRepository(t *testing.T, repo repository.TokenRevocationRepository) {
+	userID := uuid.New()
+	issuedAt := time.Now().Add(-time.Minute)
+
+	t.Run("Revoke single token", func(t *testing.T) {
+		tokenID := uuid.NewString()
+		if err := repo.Revoke(tokenID, userID, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Erro ao revogar o token: %v", err)
+		}
+
+		revoked, err := repo.IsRevoked(tokenID, userID, issuedAt)
+		if err != nil || !revoked {
+			t.Fatalf("Esperava token revogado, obteve: %v, %v", revoked, err)
+		}
+
+		revoked, _ = repo.IsRevoked(uuid.NewString(), userID, issuedAt)
+		if revoked {
+			t.Fatalf("Outro token do usuário não deveria estar revogado.")
+		}
+	})
+
+	t.Run("Revoke all tokens of user", func(t *testing.T) {
+		otherUser := uuid.New()
+		if err := repo.RevokeAllForUser(otherUser, time.Now(), time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Erro ao revogar as sessões do usuário: %v", err)
+		}
+
+		revoked, _ := repo.IsRevoked(uuid.NewString(), otherUser, issuedAt)
+		if !revoked {
+			t.Fatalf("Token emitido antes da revogação geral deveria estar revogado.")
+		}
+
+		revoked, _ = repo.IsRevoked(uuid.NewString(), otherUser, time.Now().Add(time.Minute))
+		if revoked {
+			t.Fatalf("Token emitido depois da revogação geral não deveria estar revogado.")
+		}
+	})
+
+	t.Run("Token issued in the same second after revoking all", func(t *testing.T) {
+		otherUser := uuid.New()
+		revokedAt := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+		if err := repo.RevokeAllForUser(otherUser, revokedAt, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Erro ao revogar as sessões do usuário: %v", err)
+		}
+
+		revoked, _ := repo.IsRevoked(uuid.NewString(), otherUser, revokedAt.Add(-time.Millisecond))
+		if !revoked {
+			t.Fatalf("Token emitido no mesmo segundo, antes da revogação geral, deveria estar revogado.")
+		}
+
+		revoked, _ = repo.IsRevoked(uuid.NewString(), otherUser, revokedAt.Add(time.Millisecond))
+		if revoked {
+			t.Fatalf("Token emitido no mesmo segundo, depois da revogação geral, não deveria estar revogado.")
+		}
+	})
+
+	t.Run("Purge expired revocations", func(t *testing.T) {
+		tokenID := uuid.NewString()
+		repo.Revoke(tokenID, userID, time.Now().Add(-time.Minute))
+
+		purged, err := repo.PurgeExpired(time.Now())
+		if err != nil {
+			t.Fatalf("Erro ao remover revogações expiradas: %v", err)
+		}
+		if purged == 0 {
+			t.Fatalf("Esperava remover ao menos uma revogação expirada.")
+		}
+
+		revoked, _ := repo.IsRevoked(tokenID, userID, issuedAt)
+		if revoked {
+			t.Fatalf("Revogação expirada deveria ter sido removida.")
+		}
+	})
+}
+
+func TestTokenRevocationRepository(t *testing.T) {
+	db, _ := setupDatabase()
+	db.AutoMigrate(&models.RevokedToken{})
+
+	testTokenRevocationRepository(t, NewTokenRevocationRepository(db))
+}
+
+func TestMemoryTokenRevocationRepository(t *testing.T) {
+	testTokenRevocationRepository(t, NewMemoryTokenRevocationRepository())
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"server/src/layers/domain/models"
+	"time"
 )
 
 // UserRepository representa o repositório de usuário.
@@ -52,6 +53,18 @@ func (ur *UserRepository) FindByCPF(cpf string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByEmail busca um usuário pelo e-mail normalizado.
+func (ur *UserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := ur.db.First(&user, "email = ? AND email <> ''", email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuário com o e-mail %s não encontrado", email)
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 // Update atualiza os detalhes do usuário e cria um evento relacionado.
 func (ur *UserRepository) Update(user *models.User) error {
 	return ur.db.Save(user).Error
@@ -62,6 +75,24 @@ func (ur *UserRepository) UpdatePassword(id uuid.UUID, hashedPassword string) er
 	return ur.db.Model(&models.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
 }
 
+// AdvanceTOTPStep grava o intervalo TOTP aceito em uma única atualização condicional, para que
+// dois logins simultâneos com o mesmo código não sejam ambos aceitos.
+func (ur *UserRepository) AdvanceTOTPStep(id uuid.UUID, step int64) (bool, error) {
+	result := ur.db.Model(&models.User{}).Where("id = ? AND totp_last_step < ?", id, step).Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ChangePassword grava a nova senha escolhida pelo usuário junto com a data da troca.
+func (ur *UserRepository) ChangePassword(id uuid.UUID, hashedPassword string, changedAt time.Time) error {
+	return ur.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":            hashedPassword,
+		"password_changed_at": changedAt,
+	}).Error
+}
+
 // Delete remove um usuário e cria um evento relacionado.
 func (ur *UserRepository) Delete(id uuid.UUID) error {
 	return ur.db.Delete(&models.User{}, "id = ?", id).Error
@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 	"server/src/layers/domain/models"
 	"testing"
+	"time"
 )
 
 func setupDatabase() (*gorm.DB, error) {
@@ -153,6 +154,63 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
 	})
 }
 
+func TestUserRepository_ChangePassword(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewUserRepository(db)
+	db.AutoMigrate(&models.User{})
+
+	user := &models.User{
+		CPF:       "83103569009",
+		Password:  "password",
+		FirstName: "Lucas",
+		LastName:  "Albuquerque",
+	}
+	repo.Store(user)
+
+	changedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	if err := repo.ChangePassword(user.ID, "new_password", changedAt); err != nil {
+		t.Fatalf("Erro ao trocar a senha do usuário: %v", err)
+	}
+
+	updatedUser, _ := repo.FindByID(user.ID)
+	if updatedUser.Password != "new_password" || !updatedUser.PasswordChangedAt.Equal(changedAt) {
+		t.Fatalf("Senha e data da troca não foram gravadas corretamente: %v", updatedUser.PasswordChangedAt)
+	}
+}
+
+func TestUserRepository_AdvanceTOTPStep(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewUserRepository(db)
+	db.AutoMigrate(&models.User{})
+
+	user := &models.User{
+		CPF:       "39053344705",
+		Password:  "password",
+		FirstName: "Lucas",
+		LastName:  "Albuquerque",
+	}
+	if _, err := repo.Store(user); err != nil {
+		t.Fatalf("Erro ao armazenar o usuário: %v", err)
+	}
+
+	advanced, err := repo.AdvanceTOTPStep(user.ID, 10)
+	if err != nil || !advanced {
+		t.Fatalf("Esperava avançar o intervalo TOTP: %v, %v", advanced, err)
+	}
+
+	for _, step := range []int64{10, 9} {
+		advanced, err := repo.AdvanceTOTPStep(user.ID, step)
+		if err != nil || advanced {
+			t.Fatalf("O intervalo %d não deveria ser aceito depois do 10: %v, %v", step, advanced, err)
+		}
+	}
+
+	updatedUser, _ := repo.FindByID(user.ID)
+	if updatedUser.TOTPLastStep != 10 {
+		t.Fatalf("Intervalo TOTP gravado incorreto: %d", updatedUser.TOTPLastStep)
+	}
+}
+
 func TestUserRepository_Delete(t *testing.T) {
 	db, _ := setupDatabase()
 	repo := NewUserRepository(db)
@@ -207,3 +265,67 @@ func TestUserRepository_FindAllWithPagination(t *testing.T) {
 		}
 	})
 }
+
+func TestUserRepository_Roles(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewUserRepository(db)
+	db.AutoMigrate(&models.User{})
+
+	user := &models.User{
+		CPF:       "83103569009",
+		Password:  "password",
+		FirstName: "Lucas",
+		LastName:  "Albuquerque",
+		Roles:     models.StringList{models.RoleUser, models.RoleAdmin},
+	}
+	repo.Store(user)
+
+	foundUser, err := repo.FindByID(user.ID)
+	if err != nil {
+		t.Fatalf("Erro ao buscar o usuário pelo ID: %v", err)
+	}
+	if !foundUser.Roles.Contains(models.RoleAdmin) || len(foundUser.Roles) != 2 {
+		t.Fatalf("Papéis do usuário não foram persistidos corretamente: %v", foundUser.Roles)
+	}
+}
+
+func TestUserRepository_FindByEmail(t *testing.T) {
+	db, _ := setupDatabase()
+	repo := NewUserRepository(db)
+	db.AutoMigrate(&models.User{})
+
+	user := &models.User{
+		CPF:       "83103569009",
+		Password:  "password",
+		FirstName: "Lucas",
+		LastName:  "Albuquerque",
+		Email:     "lucas.email@example.com",
+	}
+	if _, err := repo.Store(user); err != nil {
+		t.Fatalf("Erro ao armazenar o usuário: %v", err)
+	}
+
+	t.Run("Find by valid email", func(t *testing.T) {
+		foundUser, err := repo.FindByEmail(user.Email)
+		if err != nil {
+			t.Fatalf("Erro ao buscar o usuário pelo e-mail: %v", err)
+		}
+		if foundUser.ID != user.ID {
+			t.Fatalf("Usuário encontrado não corresponde ao esperado.")
+		}
+	})
+
+	t.Run("Empty email does not match users without email", func(t *testing.T) {
+		repo.Store(&models.User{CPF: "52998224725", Password: "password", FirstName: "Ana", LastName: "Souza"})
+		if _, err := repo.FindByEmail(""); err == nil {
+			t.Fatalf("Esperava um erro ao buscar um e-mail vazio, mas não obteve nenhum.")
+		}
+	})
+
+	t.Run("Duplicate email is rejected", func(t *testing.T) {
+		duplicate := &models.User{CPF: "11144477735", Password: "password", FirstName: "Ana", LastName: "Souza", Email: user.Email}
+		if _, err := repo.Store(duplicate); err == nil {
+			t.Fatalf("Esperava um erro ao armazenar um e-mail duplicado, mas não obteve nenhum.")
+		}
+	})
+}
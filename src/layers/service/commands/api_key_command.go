@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+const (
+	// apiKeySize é o número de bytes aleatórios de cada chave
+	apiKeySize = 32
+	// apiKeyDisplayLength é a quantidade de caracteres da chave guardada em claro para identificá-la
+	apiKeyDisplayLength = len(models.APIKeyPrefix) + 8
+	// apiKeyTouchInterval evita gravar o último uso a cada requisição
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("chave de api inválida")
+	ErrAPIKeyNotFound = errors.New("chave de api não encontrada")
+)
+
+type CreateAPIKeyHandler struct {
+	Repo repository.UserRepository
+	Keys repository.APIKeyRepository
+}
+
+// CreateAPIKeyCommand representa a criação de uma chave de api para o usuário autenticado
+type CreateAPIKeyCommand struct {
+	UserID    uuid.UUID  `json:"-"`
+	Name      string     `json:"Name"`
+	Scopes    []string   `json:"Scopes"`
+	ExpiresAt *time.Time `json:"ExpiresAt"` // opcional; sem data a chave vale até ser revogada
+}
+
+// CreatedAPIKey devolve a chave em claro junto com seus dados; ela não pode ser consultada depois
+type CreatedAPIKey struct {
+	*models.APIKey
+	Key string `json:"Key"`
+}
+
+// Validate realiza validações básicas no comando CreateAPIKeyCommand
+func (c *CreateAPIKeyCommand) Validate() error {
+	if c.Name == "" {
+		return errors.New("Name é necessário")
+	}
+	if len(c.Scopes) == 0 {
+		return errors.New("Scopes é necessário")
+	}
+	if c.ExpiresAt != nil && !c.ExpiresAt.After(time.Now()) {
+		return errors.New("ExpiresAt deve ser uma data futura")
+	}
+	return nil
+}
+
+// Handle gera a chave, limitada a permissões que o usuário possui, e grava apenas o seu hash
+func (h *CreateAPIKeyHandler) Handle(command CreateAPIKeyCommand) (*CreatedAPIKey, error) {
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	permissions := models.StringList(user.EffectivePermissions())
+	for _, scope := range command.Scopes {
+		if !permissions.Contains(scope) {
+			return nil, fmt.Errorf("escopo não permitido: %s", scope)
+		}
+	}
+
+	token, err := shared.GenerateRandomToken(apiKeySize)
+	if err != nil {
+		return nil, errors.New("erro ao gerar a chave de api")
+	}
+	key := models.APIKeyPrefix + token
+
+	record := &models.APIKey{
+		UserID:    user.ID,
+		Name:      command.Name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   shared.HashToken(key),
+		Scopes:    models.StringList(command.Scopes),
+		ExpiresAt: command.ExpiresAt,
+	}
+	if err := h.Keys.Store(record); err != nil {
+		return nil, errors.New("erro ao gravar a chave de api")
+	}
+
+	return &CreatedAPIKey{APIKey: record, Key: key}, nil
+}
+
+type RevokeAPIKeyHandler struct {
+	Keys repository.APIKeyRepository
+}
+
+// RevokeAPIKeyCommand representa a revogação de uma chave de api do usuário autenticado
+type RevokeAPIKeyCommand struct {
+	UserID uuid.UUID `json:"-"`
+	KeyID  uuid.UUID `json:"ID"`
+}
+
+// Validate realiza validações básicas no comando RevokeAPIKeyCommand
+func (c *RevokeAPIKeyCommand) Validate() error {
+	if c.KeyID == uuid.Nil {
+		return errors.New("ID é necessário")
+	}
+	return nil
+}
+
+// Handle revoga a chave, desde que ela pertença ao usuário
+func (h *RevokeAPIKeyHandler) Handle(command RevokeAPIKeyCommand) error {
+	revoked, err := h.Keys.Revoke(command.KeyID, command.UserID, time.Now())
+	if err != nil {
+		return errors.New("erro ao revogar a chave de api")
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+type AuthenticateAPIKeyHandler struct {
+	Repo repository.UserRepository
+	Keys repository.APIKeyRepository
+}
+
+// AuthenticateAPIKeyCommand representa uma requisição autenticada por chave de api
+type AuthenticateAPIKeyCommand struct {
+	Key string `json:"-"`
+}
+
+// Handle valida a chave e retorna o principal com as permissões dos escopos que o usuário ainda possui.
+// Os papéis do usuário não são repassados, para que a chave nunca exceda os escopos concedidos.
+func (h *AuthenticateAPIKeyHandler) Handle(command AuthenticateAPIKeyCommand) (*models.Principal, error) {
+	now := time.Now()
+
+	key, err := h.Keys.FindByHash(shared.HashToken(command.Key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, errors.New("erro ao consultar a chave de api")
+	}
+	if !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := h.Repo.FindByID(key.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	permissions := models.StringList(user.EffectivePermissions())
+	var granted []string
+	for _, scope := range key.Scopes {
+		if permissions.Contains(scope) {
+			granted = append(granted, scope)
+		}
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := h.Keys.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("falha ao registrar o uso da chave de api %s: %v", key.ID, err)
+		}
+	}
+
+	return &models.Principal{
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		Permissions: granted,
+		APIKeyID:    key.ID.String(),
+	}, nil
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestCreateAPIKeyHandler_Scopes(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	user := &models.User{CPF: "52998224725", Roles: models.StringList{models.RoleUser}}
+	users.Store(user)
+	handler := CreateAPIKeyHandler{Repo: users, Keys: persistence.NewAPIKeyRepository(setupDatabase(t, &models.APIKey{}))}
+
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr bool
+	}{
+		{"Escopo que o usuário possui", []string{models.PermissionProfileRead}, false},
+		{"Escopo além das permissões do usuário", []string{models.PermissionProfileRead, models.PermissionUsersRead}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := handler.Handle(CreateAPIKeyCommand{UserID: user.ID, Name: "deploy", Scopes: tt.scopes})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if err == nil && created.KeyHash == created.Key {
+				t.Error("A chave não deveria ser gravada em claro")
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKeyHandler_Handle(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	user := &models.User{CPF: "52998224725", Roles: models.StringList{models.RoleAdmin}}
+	users.Store(user)
+
+	keys := persistence.NewAPIKeyRepository(setupDatabase(t, &models.APIKey{}))
+	create := CreateAPIKeyHandler{Repo: users, Keys: keys}
+	authenticate := AuthenticateAPIKeyHandler{Repo: users, Keys: keys}
+
+	newKey := func(t *testing.T) *CreatedAPIKey {
+		created, err := create.Handle(CreateAPIKeyCommand{UserID: user.ID, Name: "deploy", Scopes: []string{models.PermissionUsersRead, models.PermissionProfileRead}})
+		if err != nil {
+			t.Fatalf("Erro ao criar a chave: %v", err)
+		}
+		return created
+	}
+
+	t.Run("Permissões limitadas aos escopos que o usuário ainda possui", func(t *testing.T) {
+		created := newKey(t)
+		user.Roles = models.StringList{models.RoleUser}
+		defer func() { user.Roles = models.StringList{models.RoleAdmin} }()
+
+		principal, err := authenticate.Handle(AuthenticateAPIKeyCommand{Key: created.Key})
+		if err != nil {
+			t.Fatalf("Erro ao autenticar a chave: %v", err)
+		}
+		if principal.HasPermission(models.PermissionUsersRead) || !principal.HasPermission(models.PermissionProfileRead) {
+			t.Errorf("Esperava apenas profile:read após a perda do papel admin, obteve %v", principal.Permissions)
+		}
+		if len(principal.Roles) != 0 {
+			t.Errorf("Os papéis do usuário não deveriam ser repassados à chave: %v", principal.Roles)
+		}
+	})
+
+	tests := []struct {
+		name string
+		key  func(t *testing.T) string
+	}{
+		{"Chave desconhecida", func(t *testing.T) string { return models.APIKeyPrefix + "desconhecida" }},
+		{"Chave revogada", func(t *testing.T) string {
+			created := newKey(t)
+			keys.Revoke(created.ID, user.ID, time.Now())
+			return created.Key
+		}},
+		{"Chave expirada", func(t *testing.T) string {
+			expired := time.Now().Add(-time.Minute)
+			key := models.APIKeyPrefix + "expirada"
+			keys.Store(&models.APIKey{UserID: user.ID, Name: "antiga", Prefix: key, KeyHash: shared.HashToken(key), Scopes: models.StringList{models.PermissionProfileRead}, ExpiresAt: &expired})
+			return key
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authenticate.Handle(AuthenticateAPIKeyCommand{Key: tt.key(t)}); !errors.Is(err, ErrInvalidAPIKey) {
+				t.Errorf("Esperava ErrInvalidAPIKey, obteve %v", err)
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKeyHandler_OnlyOwner(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	owner := &models.User{CPF: "52998224725", Roles: models.StringList{models.RoleUser}}
+	users.Store(owner)
+
+	keys := persistence.NewAPIKeyRepository(setupDatabase(t, &models.APIKey{}))
+	created, err := (&CreateAPIKeyHandler{Repo: users, Keys: keys}).Handle(CreateAPIKeyCommand{UserID: owner.ID, Name: "deploy", Scopes: []string{models.PermissionProfileRead}})
+	if err != nil {
+		t.Fatalf("Erro ao criar a chave: %v", err)
+	}
+	revoke := RevokeAPIKeyHandler{Keys: keys}
+
+	if err := revoke.Handle(RevokeAPIKeyCommand{UserID: uuid.New(), KeyID: created.ID}); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Outro usuário não deveria revogar a chave, obteve %v", err)
+	}
+	if err := revoke.Handle(RevokeAPIKeyCommand{UserID: owner.ID, KeyID: created.ID}); err != nil {
+		t.Errorf("Esperava revogar a própria chave, obteve %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+type AssignRolesHandler struct {
+	Repo        repository.UserRepository
+	Tokens      repository.RefreshTokenRepository
+	Revocations repository.TokenRevocationRepository
+	JWT         *shared.JWTManager
+}
+
+// AssignRolesCommand representa a intenção de substituir os papéis de um usuário
+type AssignRolesCommand struct {
+	UserID    uuid.UUID         `json:"-"`
+	Roles     []string          `json:"Roles"`
+	Requester *models.Principal `json:"-"`
+}
+
+// Validate realiza validações básicas no comando AssignRolesCommand
+func (c *AssignRolesCommand) Validate() error {
+	if len(c.Roles) == 0 {
+		return errors.New("Roles é necessário")
+	}
+	for _, role := range c.Roles {
+		if !models.IsKnownRole(role) {
+			return fmt.Errorf("papel desconhecido: %s", role)
+		}
+	}
+	return nil
+}
+
+// Handle substitui os papéis do usuário e exige a permissão roles:write.
+// Como as permissões são gravadas nos tokens, uma mudança de papéis revoga os tokens de acesso e os refresh
+// tokens do usuário, que precisa entrar de novo para receber as novas permissões.
+func (h *AssignRolesHandler) Handle(command AssignRolesCommand) (*models.User, error) {
+	if command.Requester == nil || !command.Requester.HasPermission(models.PermissionRolesWrite) {
+		return nil, models.ErrAccessDenied
+	}
+
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	roles := models.StringList(command.Roles)
+	changed := !sameRoles(user.Roles, roles)
+
+	user.Roles = roles
+	if err := h.Repo.Update(user); err != nil {
+		return nil, errors.New("erro ao atualizar os papéis do usuário")
+	}
+
+	if changed {
+		now := time.Now()
+		if err := h.Revocations.RevokeAllForUser(user.ID, now, now.Add(h.JWT.TokenDuration())); err != nil {
+			return nil, errors.New("falha ao encerrar as sessões do usuário")
+		}
+		if err := h.Tokens.RevokeAllForUser(user.ID); err != nil {
+			return nil, errors.New("falha ao encerrar as sessões do usuário")
+		}
+	}
+
+	return user, nil
+}
+
+// sameRoles informa se as duas listas têm os mesmos papéis, em qualquer ordem
+func sameRoles(current, next models.StringList) bool {
+	for _, role := range current {
+		if !next.Contains(role) {
+			return false
+		}
+	}
+	for _, role := range next {
+		if !current.Contains(role) {
+			return false
+		}
+	}
+	return true
+}
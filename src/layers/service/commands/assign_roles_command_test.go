@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestAssignRolesHandler_RevokesTokensOnRoleChange(t *testing.T) {
+	db := setupDatabase(t, &models.RefreshToken{})
+	users := repository.NewMockUserRepository()
+	tokens := persistence.NewRefreshTokenRepository(db)
+	revocations := persistence.NewMemoryTokenRevocationRepository()
+	handler := AssignRolesHandler{Repo: users, Tokens: tokens, Revocations: revocations, JWT: shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour)}
+
+	admin := &models.Principal{Permissions: []string{models.PermissionRolesWrite}}
+	user := &models.User{CPF: "52998224725", Roles: models.StringList{models.RoleUser, models.RoleAdmin}}
+	users.Store(user)
+
+	refresh := &models.RefreshToken{UserID: user.ID, FamilyID: uuid.New(), TokenID: "refresh-1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := tokens.Store(refresh); err != nil {
+		t.Fatalf("Erro ao armazenar o refresh token: %v", err)
+	}
+	issuedAt := time.Now().Add(-time.Minute)
+
+	// Reenviar os mesmos papéis não encerra as sessões
+	if _, err := handler.Handle(AssignRolesCommand{UserID: user.ID, Roles: []string{models.RoleAdmin, models.RoleUser}, Requester: admin}); err != nil {
+		t.Fatalf("Erro ao atribuir os papéis: %v", err)
+	}
+	if revoked, _ := revocations.IsRevoked("access-1", user.ID, issuedAt); revoked {
+		t.Error("Os tokens não deveriam ser revogados sem mudança de papéis")
+	}
+
+	// Remover o papel de administrador revoga os tokens de acesso e os refresh tokens já emitidos
+	if _, err := handler.Handle(AssignRolesCommand{UserID: user.ID, Roles: []string{models.RoleUser}, Requester: admin}); err != nil {
+		t.Fatalf("Erro ao atribuir os papéis: %v", err)
+	}
+	if revoked, _ := revocations.IsRevoked("access-1", user.ID, issuedAt); !revoked {
+		t.Error("O token de acesso emitido antes da mudança deveria estar revogado")
+	}
+	stored, err := tokens.FindByTokenID("refresh-1")
+	if err != nil || stored.RevokedAt == nil {
+		t.Error("O refresh token deveria estar revogado")
+	}
+}
+
+func TestAssignRolesHandler_RequiresPermission(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	handler := AssignRolesHandler{Repo: users}
+
+	user := &models.User{CPF: "52998224725", Roles: models.StringList{models.RoleUser}}
+	users.Store(user)
+
+	requester := &models.Principal{UserID: user.ID, Permissions: []string{models.PermissionProfileWrite}}
+	_, err := handler.Handle(AssignRolesCommand{UserID: user.ID, Roles: []string{models.RoleAdmin}, Requester: requester})
+	if !errors.Is(err, models.ErrAccessDenied) {
+		t.Fatalf("Esperava acesso negado, obteve %v", err)
+	}
+	if user.Roles.Contains(models.RoleAdmin) {
+		t.Error("Os papéis não deveriam mudar sem a permissão roles:write")
+	}
+}
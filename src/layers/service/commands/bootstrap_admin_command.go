@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/passwordpolicy"
+	"server/src/layers/domain/repository"
+)
+
+// ErrBootstrapAdminTaken indica que o cpf do administrador inicial já pertence a uma conta comum
+var ErrBootstrapAdminTaken = errors.New("cpf do administrador inicial já cadastrado sem o papel de administrador; conceda o papel por PUT /users/:id/roles")
+
+// BootstrapAdminHandler cria o primeiro administrador a partir da configuração, na inicialização e antes de o
+// servidor aceitar requisições; nenhuma rota pública concede papéis. Depois dele, os papéis mudam apenas por
+// PUT /users/:id/roles.
+type BootstrapAdminHandler struct {
+	Repo           repository.UserRepository
+	Hasher         shared.PasswordHasher
+	PasswordPolicy *passwordpolicy.Policy
+}
+
+// BootstrapAdminCommand representa os dados do administrador inicial
+type BootstrapAdminCommand struct {
+	CPF       string
+	FirstName string
+	LastName  string
+	Password  string
+}
+
+// Validate realiza validações básicas no comando BootstrapAdminCommand
+func (c *BootstrapAdminCommand) Validate() error {
+	fields := map[string]interface{}{
+		"Cpf":       c.CPF,
+		"FirstName": c.FirstName,
+		"LastName":  c.LastName,
+		"Password":  c.Password,
+	}
+
+	for fieldName, value := range fields {
+		if value == "" {
+			return fmt.Errorf("%s é necessário", fieldName)
+		}
+	}
+	return nil
+}
+
+// Handle cria o administrador; se ele já existir, nada é alterado. Um cpf já cadastrado sem o papel de
+// administrador é recusado em vez de promovido, pois a conta pode ter sido criada por outra pessoa no cadastro público.
+// O segundo retorno informa se o usuário foi criado.
+func (h *BootstrapAdminHandler) Handle(command BootstrapAdminCommand) (*models.User, bool, error) {
+	if user, err := h.Repo.FindByCPF(command.CPF); err == nil && user != nil {
+		if !user.Roles.Contains(models.RoleAdmin) {
+			return nil, false, ErrBootstrapAdminTaken
+		}
+		return user, false, nil
+	}
+
+	subject := passwordpolicy.Subject{CPF: command.CPF, FirstName: command.FirstName, LastName: command.LastName}
+	if err := checkPasswordPolicy(h.PasswordPolicy, "Password", command.Password, subject); err != nil {
+		return nil, false, err
+	}
+
+	hashedPassword, err := h.Hasher.HashPassword(command.Password)
+	if err != nil {
+		return nil, false, hasherError(err, "erro ao criptografar a senha")
+	}
+
+	user, err := models.NewUser(command.CPF, command.FirstName, command.LastName, hashedPassword)
+	if err != nil {
+		return nil, false, err
+	}
+	user.Roles = append(user.Roles, models.RoleAdmin)
+
+	storedUser, err := h.Repo.Store(user)
+	if err != nil {
+		return nil, false, err
+	}
+	return storedUser, true, nil
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+)
+
+func TestBootstrapAdminHandler_Handle(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	handler := BootstrapAdminHandler{Repo: users, Hasher: shared.NewDefaultPasswordHasher(shared.NewArgon2Manager())}
+	command := BootstrapAdminCommand{CPF: "52998224725", FirstName: "Ana", LastName: "Souza", Password: "Senha-Do-Admin-123"}
+
+	admin, created, err := handler.Handle(command)
+	if err != nil || !created {
+		t.Fatalf("Esperava criar o administrador inicial, obteve %v", err)
+	}
+	if !admin.Roles.Contains(models.RoleAdmin) {
+		t.Fatalf("O administrador inicial deveria ter o papel admin: %v", admin.Roles)
+	}
+
+	// Reiniciar o servidor com a mesma configuração não cria outro usuário
+	again, created, err := handler.Handle(command)
+	if err != nil || created || again.ID != admin.ID {
+		t.Errorf("Esperava reaproveitar o administrador existente, obteve %v, %v", created, err)
+	}
+}
+
+func TestBootstrapAdminHandler_RefusesExistingAccount(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	handler := BootstrapAdminHandler{Repo: users, Hasher: shared.NewDefaultPasswordHasher(shared.NewArgon2Manager())}
+
+	// Uma conta comum criada no cadastro público com o cpf configurado não é promovida
+	existing := &models.User{CPF: "52998224725", FirstName: "Ana", LastName: "Souza", Roles: models.StringList{models.RoleUser}}
+	users.Store(existing)
+
+	_, _, err := handler.Handle(BootstrapAdminCommand{CPF: existing.CPF, FirstName: "Ana", LastName: "Souza", Password: "Senha-Do-Admin-123"})
+	if !errors.Is(err, ErrBootstrapAdminTaken) {
+		t.Fatalf("Esperava ErrBootstrapAdminTaken, obteve %v", err)
+	}
+	if existing.Roles.Contains(models.RoleAdmin) {
+		t.Error("A conta existente não deveria receber o papel admin")
+	}
+}
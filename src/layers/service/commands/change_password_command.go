@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/passwordpolicy"
+	"server/src/layers/domain/repository"
+)
+
+type ChangePasswordHandler struct {
+	Repo           repository.UserRepository
+	Hasher         shared.PasswordHasher
+	PasswordPolicy *passwordpolicy.Policy
+	History        *PasswordHistory
+}
+
+// ChangePasswordCommand representa a intenção de trocar a senha do usuário autenticado
+type ChangePasswordCommand struct {
+	UserID          uuid.UUID `json:"-"`
+	CurrentPassword string    `json:"CurrentPassword"`
+	NewPassword     string    `json:"NewPassword"`
+}
+
+// Validate realiza validações básicas no comando ChangePasswordCommand
+func (c *ChangePasswordCommand) Validate() error {
+	if c.CurrentPassword == "" {
+		return errors.New("CurrentPassword é necessário")
+	}
+	if c.NewPassword == "" {
+		return errors.New("NewPassword é necessário")
+	}
+	return nil
+}
+
+// Handle confere a senha atual e grava o hash da nova senha, guardando a anterior no histórico
+func (h *ChangePasswordHandler) Handle(command ChangePasswordCommand) error {
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return errors.New("usuário não encontrado")
+	}
+	if user.IsExternal() {
+		return ErrExternalPassword
+	}
+
+	ok, err := h.Hasher.VerifyPassword(command.CurrentPassword, user.Password)
+	if errors.Is(err, shared.ErrHasherBusy) {
+		return err
+	}
+	if !ok {
+		return errors.New("senha atual inválida")
+	}
+
+	if err := checkPasswordPolicy(h.PasswordPolicy, "NewPassword", command.NewPassword, passwordpolicy.SubjectFromUser(user)); err != nil {
+		return err
+	}
+
+	if err := h.History.CheckReuse(h.Hasher, user, "NewPassword", command.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := h.Hasher.HashPassword(command.NewPassword)
+	if err != nil {
+		return hasherError(err, "erro ao criptografar a senha")
+	}
+
+	return replacePassword(h.Repo, h.History, user, hashedPassword)
+}
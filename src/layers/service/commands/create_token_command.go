@@ -4,25 +4,65 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"log"
 	"server/src/commons/shared"
+	"server/src/layers/domain/authn"
+	"server/src/layers/domain/lockout"
+	"server/src/layers/domain/models"
 	"server/src/layers/domain/repository"
+	"strings"
+	"time"
 )
 
+var ErrInvalidCredentials = errors.New("cpf ou senha inválidos")
+
+// ErrInvalidEmailCredentials é o erro de credenciais inválidas no login pelo e-mail
+var ErrInvalidEmailCredentials = errors.New("e-mail ou senha inválidos")
+
+// ThrottledError indica que a conta ou o IP estão temporariamente bloqueados.
+// A mensagem é a mesma das credenciais inválidas para não revelar quais cpfs existem;
+// Credentials troca a redação quando o login usou outro identificador.
+type ThrottledError struct {
+	RetryAfter  time.Duration
+	Credentials error
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Credentials != nil {
+		return e.Credentials.Error()
+	}
+	return ErrInvalidCredentials.Error()
+}
+
 type CreateTokenHandler struct {
-	Repo         repository.UserRepository
-	ArgonManager *shared.Argon2Manager
-	JWT          *shared.JWTManager
+	Repo           repository.UserRepository
+	Tokens         repository.RefreshTokenRepository
+	Hasher         shared.PasswordHasher
+	JWT            *shared.JWTManager
+	Limiter        *lockout.Limiter // opcional; nil desativa o controle de tentativas
+	PasswordMaxAge time.Duration    // idade máxima da senha; zero desativa a expiração
+	// Sessions e History registram as sessões e o histórico de logins; nil desativa o registro
+	Sessions repository.SessionRepository
+	History  repository.SignInHistoryRepository
+	// Authenticators verifica as credenciais em ordem; vazio usa apenas a senha local
+	Authenticators authn.Chain
 }
 
-// CreateTokenCommand representa a intenção de criar um token para um usuário existente
+// CreateTokenCommand representa a intenção de criar um token para um usuário existente.
+// Identifier aceita o cpf ou o e-mail confirmado; Cpf continua aceito para os clientes existentes.
 type CreateTokenCommand struct {
-	CPF      string `json:"Cpf"`
-	Password string `json:"Password"`
+	Identifier string `json:"Identifier"`
+	CPF        string `json:"Cpf"`
+	Password   string `json:"Password"`
+	IP         string `json:"-"`
+	UserAgent  string `json:"-"`
 }
 
 type TokenResponse struct {
 	User SimplifiedUser `json:"User"`
 	Key  SimplifiedKey  `json:"Key"`
+	// PasswordChangeRequired indica que a senha expirou e o token de acesso só permite trocá-la
+	PasswordChangeRequired bool `json:"PasswordChangeRequired,omitempty"`
 }
 
 type SimplifiedUser struct {
@@ -30,6 +70,7 @@ type SimplifiedUser struct {
 	FirstName string    `json:"FirstName"`
 	LastName  string    `json:"LastName"`
 	CPF       string    `json:"Cpf"`
+	Email     string    `json:"Email,omitempty"`
 }
 
 type SimplifiedKey struct {
@@ -40,8 +81,8 @@ type SimplifiedKey struct {
 // Validate realiza validações básicas no comando CreateTokenCommand
 func (c *CreateTokenCommand) Validate() error {
 	fields := map[string]interface{}{
-		"Cpf":      c.CPF,
-		"Password": c.Password,
+		"Identifier": c.identifier(),
+		"Password":   c.Password,
 	}
 
 	for fieldName, value := range fields {
@@ -52,37 +93,227 @@ func (c *CreateTokenCommand) Validate() error {
 	return nil
 }
 
+// identifier retorna o identificador informado, dando preferência ao campo Identifier sobre o Cpf
+func (c *CreateTokenCommand) identifier() string {
+	if identifier := strings.TrimSpace(c.Identifier); identifier != "" {
+		return identifier
+	}
+	return strings.TrimSpace(c.CPF)
+}
+
 // Handle processa o comando CreateTokenCommand e gera um JWT para o usuário
 func (c *CreateTokenHandler) Handle(command CreateTokenCommand) (*TokenResponse, error) {
-	// Busca o usuário com base no cpf fornecido
-	user, err := c.Repo.FindByCPF(command.CPF)
-	if err != nil || user == nil {
-		return nil, errors.New("cpf ou senha inválidos")
+	// Busca o usuário pelo cpf ou pelo e-mail confirmado
+	user := findUserByIdentifier(c.Repo, command.identifier())
+
+	// As tentativas são contadas pelo cpf da conta, seja qual for o identificador usado no login;
+	// identificadores que só o provedor externo conhece são contados como informados
+	account := command.identifier()
+	if user != nil {
+		account = user.CPF
 	}
 
-	// Compara a senha fornecida com a hash armazenada usando argon2
-	match, err := c.ArgonManager.VerifyPassword(command.Password, user.Password)
-	if err != nil || !match {
-		return nil, errors.New("cpf ou senha inválidos")
+	// A tentativa é contada como falha antes da verificação, para que requisições paralelas não escapem do bloqueio
+	if err := reserveAttempt(c.Limiter, account, command.IP); err != nil {
+		return nil, credentialsError(err, command.identifier())
 	}
 
-	// Gera o JWT para o usuário
-	token, refreshToken, err := c.JWT.Generate(user.ID)
+	// Os provedores são consultados em ordem; o primeiro que reconhecer o identificador decide o login
+	authenticated, method, err := c.authenticators().Authenticate(command.identifier(), command.Password)
+	if errors.Is(err, shared.ErrHasherBusy) || errors.Is(err, authn.ErrUnavailable) {
+		releaseAttempt(c.Limiter, account, command.IP)
+		return nil, err
+	}
+	if err != nil {
+		if user != nil && errors.Is(err, authn.ErrInvalidCredentials) {
+			recordFailedSignIn(c.History, user.ID, method, models.SignInFailureInvalidPassword, command.IP, command.UserAgent)
+		}
+		if errors.Is(err, authn.ErrIncompleteIdentity) || errors.Is(err, authn.ErrAccountConflict) {
+			releaseAttempt(c.Limiter, account, command.IP)
+			return nil, err
+		}
+		return nil, credentialsError(ErrInvalidCredentials, command.identifier())
+	}
+
+	if err := recordSuccessfulAttempt(c.Limiter, account, command.IP); err != nil {
+		return nil, err
+	}
+
+	return c.IssueForUser(authenticated, method, command.IP, command.UserAgent)
+}
+
+// authenticators retorna a cadeia configurada ou, sem ela, apenas a verificação da senha local
+func (c *CreateTokenHandler) authenticators() authn.Chain {
+	if len(c.Authenticators) > 0 {
+		return c.Authenticators
+	}
+	return authn.Chain{NewLocalAuthenticator(c.Repo, c.Hasher)}
+}
+
+// IssueForUser conclui o login de um usuário já autenticado pelo método informado: com o segundo fator ativo
+// retorna o desafio em um TwoFactorRequiredError; caso contrário, emite os tokens e registra a nova sessão.
+func (c *CreateTokenHandler) IssueForUser(user *models.User, method, ip, userAgent string) (*TokenResponse, error) {
+	// Com o segundo fator ativo, o primeiro fator apenas libera um desafio de curta duração
+	if user.TOTPEnabled {
+		challenge, expiresAt, err := c.JWT.GenerateChallenge(user.ID, twoFactorChallengeDuration)
+		if err != nil {
+			return nil, errors.New("falha ao gerar o desafio")
+		}
+		return nil, &TwoFactorRequiredError{Challenge: TwoFactorChallenge{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresAt:         expiresAt,
+		}}
+	}
+
+	return c.StartSession(user, method, ip, userAgent)
+}
+
+// StartSession emite os tokens de uma nova sessão sem exigir o segundo fator; é o final comum do login,
+// usado diretamente após a verificação do segundo fator.
+func (c *CreateTokenHandler) StartSession(user *models.User, method, ip, userAgent string) (*TokenResponse, error) {
+	// Gera o JWT para o usuário iniciando uma nova família de refresh tokens, que identifica a sessão
+	familyID := uuid.New()
+	response, err := issueTokens(c.JWT, c.Tokens, user, familyID, time.Now(), c.PasswordMaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	startSession(c.Sessions, c.History, user.ID, familyID, method, ip, userAgent)
+	return response, nil
+}
+
+// findUserByIdentifier busca o usuário pelo e-mail, quando o identificador contém "@", ou pelo cpf.
+// E-mails ainda não confirmados não identificam o usuário no login.
+func findUserByIdentifier(repo repository.UserRepository, identifier string) *models.User {
+	if !strings.Contains(identifier, "@") {
+		user, err := repo.FindByCPF(identifier)
+		if err != nil {
+			return nil
+		}
+		return user
+	}
+
+	email, err := shared.NormalizeEmail(identifier)
+	if err != nil {
+		return nil
+	}
+
+	user, err := repo.FindByEmail(email)
+	if err != nil || user == nil || !user.EmailVerified() {
+		return nil
+	}
+	return user
+}
+
+// credentialsError usa a redação do e-mail nas credenciais inválidas e no bloqueio quando ele foi o identificador
+func credentialsError(err error, identifier string) error {
+	if !strings.Contains(identifier, "@") {
+		return err
+	}
+
+	var throttled *ThrottledError
+	if errors.As(err, &throttled) {
+		throttled.Credentials = ErrInvalidEmailCredentials
+		return throttled
+	}
+	if errors.Is(err, ErrInvalidCredentials) {
+		return ErrInvalidEmailCredentials
+	}
+	return err
+}
+
+// reserveAttempt contabiliza a tentativa antes da verificação das credenciais e a recusa com ThrottledError
+// enquanto a conta ou o IP estiverem bloqueados. Sem limiter, todas as tentativas são aceitas.
+func reserveAttempt(limiter *lockout.Limiter, account, ip string) error {
+	if limiter == nil {
+		return nil
+	}
+
+	retryAfter, err := limiter.Reserve(account, ip)
+	if err != nil {
+		return errors.New("falha ao registrar a tentativa de login")
+	}
+	if retryAfter > 0 {
+		return &ThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// releaseAttempt devolve a tentativa reservada quando o resultado não foi uma falha de credenciais.
+// Uma falha ao devolver apenas mantém a tentativa contada.
+func releaseAttempt(limiter *lockout.Limiter, account, ip string) {
+	if limiter == nil {
+		return
+	}
+
+	if err := limiter.Release(account, ip); err != nil {
+		log.Printf("falha ao devolver a tentativa de login: %v", err)
+	}
+}
+
+// recordSuccessfulAttempt devolve a tentativa reservada e zera as falhas da conta
+func recordSuccessfulAttempt(limiter *lockout.Limiter, account, ip string) error {
+	if limiter == nil {
+		return nil
+	}
+
+	if err := limiter.Release(account, ip); err != nil {
+		return errors.New("falha ao registrar a tentativa de login")
+	}
+	if err := limiter.RecordSuccess(account); err != nil {
+		return errors.New("falha ao registrar a tentativa de login")
+	}
+	return nil
+}
+
+// issueTokens gera um novo par de tokens na família informada e registra o refresh token emitido.
+// authTime é o momento do login que iniciou a família e é repetido nos tokens das renovações.
+// Com a senha expirada, o token de acesso fica restrito à troca de senha até que ela seja feita
+// e o refresh token seja trocado por um novo par.
+func issueTokens(jwt *shared.JWTManager, tokens repository.RefreshTokenRepository, user *models.User, familyID uuid.UUID, authTime time.Time, passwordMaxAge time.Duration) (*TokenResponse, error) {
+	subject := shared.TokenSubject{
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		Roles:       user.Roles,
+		Permissions: user.EffectivePermissions(),
+		AuthTime:    authTime,
+	}
+
+	passwordExpired := user.PasswordExpired(passwordMaxAge, time.Now())
+	if passwordExpired {
+		subject.Scope = shared.ScopePasswordChange
+	}
+
+	pair, err := jwt.GeneratePair(subject, familyID)
 	if err != nil {
 		return nil, errors.New("falha ao gerar o token")
 	}
 
+	err = tokens.Store(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  pair.FamilyID,
+		TokenID:   pair.RefreshTokenID,
+		ExpiresAt: pair.RefreshExpiresAt,
+		AuthTime:  authTime,
+	})
+	if err != nil {
+		return nil, errors.New("falha ao registrar o refresh token")
+	}
+
 	response := &TokenResponse{
 		User: SimplifiedUser{
 			ID:        user.ID,
 			FirstName: user.FirstName,
 			LastName:  user.LastName,
 			CPF:       user.CPF,
+			Email:     user.Email,
 		},
 		Key: SimplifiedKey{
-			Token:        token,
-			RefreshToken: refreshToken,
+			Token:        pair.Token,
+			RefreshToken: pair.RefreshToken,
 		},
+		PasswordChangeRequired: passwordExpired,
 	}
 
 	return response, nil
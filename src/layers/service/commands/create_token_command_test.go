@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/authn"
+	"server/src/layers/domain/lockout"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowAuthenticator recusa toda senha depois de uma pausa, como um hash lento, e conta as verificações feitas
+type slowAuthenticator struct {
+	calls int32
+}
+
+func (a *slowAuthenticator) Name() string {
+	return models.SignInMethodPassword
+}
+
+func (a *slowAuthenticator) Authenticate(identifier, password string) (*models.User, error) {
+	atomic.AddInt32(&a.calls, 1)
+	time.Sleep(20 * time.Millisecond)
+	return nil, authn.ErrInvalidCredentials
+}
+
+func newTestCreateTokenHandler(t *testing.T, users repository.UserRepository) *CreateTokenHandler {
+	return &CreateTokenHandler{
+		Repo:    users,
+		Tokens:  persistence.NewRefreshTokenRepository(setupDatabase(t, &models.RefreshToken{})),
+		Hasher:  newTestHasher(),
+		JWT:     shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour),
+		Limiter: lockout.NewLimiter(repository.NewMockLoginAttemptRepository(), lockout.Rule{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour}, lockout.Rule{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour}),
+	}
+}
+
+func TestCreateTokenHandler_InvalidCredentialsMessage(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	handler := newTestCreateTokenHandler(t, users)
+	user := newTestUser(t, users, handler.Hasher, "52998224725", "Senha-Correta-123")
+	verifiedAt := time.Now()
+	user.Email, user.EmailVerifiedAt = "ana@example.com", &verifiedAt
+
+	_, err := handler.Handle(CreateTokenCommand{CPF: "52998224725", Password: "errada"})
+	if !errors.Is(err, ErrInvalidCredentials) || err.Error() != "cpf ou senha inválidos" {
+		t.Errorf("Esperava a mensagem original das credenciais inválidas, obteve %v", err)
+	}
+
+	_, err = handler.Handle(CreateTokenCommand{Identifier: "ana@example.com", Password: "errada"})
+	if !errors.Is(err, ErrInvalidEmailCredentials) {
+		t.Errorf("Esperava a mensagem do login pelo e-mail, obteve %v", err)
+	}
+
+	// Bloqueada, a conta responde com a mesma redação da falha pelo identificador usado
+	_, err = handler.Handle(CreateTokenCommand{Identifier: "ana@example.com", Password: "errada"})
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) || err.Error() != ErrInvalidEmailCredentials.Error() {
+		t.Errorf("Esperava o bloqueio com a mensagem do e-mail, obteve %v", err)
+	}
+	if _, err := handler.Handle(CreateTokenCommand{CPF: "52998224725", Password: "Senha-Correta-123"}); err == nil || err.Error() != ErrInvalidCredentials.Error() {
+		t.Errorf("Esperava o bloqueio com a mensagem do cpf, obteve %v", err)
+	}
+}
+
+func TestCreateTokenHandler_ParallelAttemptsRespectLockout(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	handler := newTestCreateTokenHandler(t, users)
+	newTestUser(t, users, handler.Hasher, "52998224725", "Senha-Correta-123")
+
+	authenticator := &slowAuthenticator{}
+	handler.Authenticators = authn.Chain{authenticator}
+
+	var wg sync.WaitGroup
+	var throttled int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var locked *ThrottledError
+			if _, err := handler.Handle(CreateTokenCommand{CPF: "52998224725", Password: "errada", IP: "10.0.0.1"}); errors.As(err, &locked) {
+				atomic.AddInt32(&throttled, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Com MaxAttempts 2, só duas senhas chegam a ser verificadas, mesmo com as requisições simultâneas
+	if authenticator.calls != 2 || throttled != 8 {
+		t.Errorf("Esperava 2 verificações e 8 bloqueios, obteve %d e %d", authenticator.calls, throttled)
+	}
+}
+
+func TestCreateTokenHandler_SuccessReleasesAttempt(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	handler := newTestCreateTokenHandler(t, users)
+	newTestUser(t, users, handler.Hasher, "52998224725", "Senha-Correta-123")
+
+	if _, err := handler.Handle(CreateTokenCommand{CPF: "52998224725", Password: "errada", IP: "10.0.0.1"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Esperava credenciais inválidas, obteve %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := handler.Handle(CreateTokenCommand{CPF: "52998224725", Password: "Senha-Correta-123", IP: "10.0.0.1"}); err != nil {
+			t.Fatalf("Esperava o login com a senha correta, obteve %v", err)
+		}
+	}
+
+	// Os logins corretos não contam como falhas nem para a conta nem para o IP
+	if retryAfter, _ := handler.Limiter.RetryAfter("52998224725", "10.0.0.1"); retryAfter != 0 {
+		t.Errorf("Esperava a conta e o IP liberados, obteve %s", retryAfter)
+	}
+}
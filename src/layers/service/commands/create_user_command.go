@@ -3,14 +3,19 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"log"
 	"server/src/commons/shared"
 	"server/src/layers/domain/models"
+	"server/src/layers/domain/passwordpolicy"
 	"server/src/layers/domain/repository"
 )
 
 type CreateUserHandler struct {
-	Repo         repository.UserRepository
-	ArgonManager *shared.Argon2Manager
+	Repo           repository.UserRepository
+	Hasher         shared.PasswordHasher
+	PasswordPolicy *passwordpolicy.Policy
+	// Verification envia o link de confirmação quando o cadastro informa um e-mail; nil desativa o envio
+	Verification *EmailVerification
 }
 
 // CreateUserCommand representa a intenção de criar um novo usuário
@@ -19,6 +24,7 @@ type CreateUserCommand struct {
 	LastName  string `json:"LastName"`
 	CPF       string `json:"Cpf"`
 	Password  string `json:"Password"`
+	Email     string `json:"Email"` // opcional; precisa ser confirmado antes de ser usado no login
 }
 
 // Validate realiza validações básicas no comando CreateUserCommand
@@ -35,6 +41,12 @@ func (c *CreateUserCommand) Validate() error {
 			return fmt.Errorf("%s é necessário", fieldName)
 		}
 	}
+
+	if c.Email != "" {
+		if _, err := shared.NormalizeEmail(c.Email); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -45,10 +57,23 @@ func (h *CreateUserHandler) Handle(command CreateUserCommand) (*models.User, err
 		return nil, errors.New("cpf já cadastrado")
 	}
 
-	// Hash da senha com argon2
-	hashedPassword, err := h.ArgonManager.HashPassword(command.Password)
+	var email string
+	if command.Email != "" {
+		var err error
+		if email, err = checkEmailAvailable(h.Repo, command.Email, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	subject := passwordpolicy.Subject{CPF: command.CPF, FirstName: command.FirstName, LastName: command.LastName}
+	if err := checkPasswordPolicy(h.PasswordPolicy, "Password", command.Password, subject); err != nil {
+		return nil, err
+	}
+
+	// Hash da senha com o hasher principal (argon2)
+	hashedPassword, err := h.Hasher.HashPassword(command.Password)
 	if err != nil {
-		return nil, errors.New("erro ao criptografar a senha")
+		return nil, hasherError(err, "erro ao criptografar a senha")
 	}
 
 	// Convertendo 'command' para um 'User'
@@ -57,5 +82,18 @@ func (h *CreateUserHandler) Handle(command CreateUserCommand) (*models.User, err
 		return nil, err
 	}
 
-	return h.Repo.Store(newUser)
+	newUser.Email = email
+
+	storedUser, err := h.Repo.Store(newUser)
+	if err != nil {
+		return nil, err
+	}
+
+	// O cadastro não depende do envio; o usuário pode pedir um novo link depois
+	if email != "" && h.Verification != nil {
+		if err := h.Verification.Send(storedUser); err != nil {
+			log.Printf("falha ao enviar a confirmação de e-mail do usuário %s: %v", storedUser.ID, err)
+		}
+	}
+	return storedUser, nil
 }
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+var (
+	ErrEmailTaken               = errors.New("e-mail já cadastrado")
+	ErrEmailNotSet              = errors.New("nenhum e-mail cadastrado")
+	ErrEmailAlreadyVerified     = errors.New("e-mail já confirmado")
+	ErrInvalidEmailVerification = errors.New("link de confirmação inválido ou expirado")
+)
+
+type ChangeEmailHandler struct {
+	Repo         repository.UserRepository
+	Verification *EmailVerification
+}
+
+// ChangeEmailCommand representa a troca do e-mail do usuário autenticado
+type ChangeEmailCommand struct {
+	UserID uuid.UUID `json:"-"`
+	Email  string    `json:"Email"`
+}
+
+// Validate realiza validações básicas no comando ChangeEmailCommand
+func (c *ChangeEmailCommand) Validate() error {
+	if c.Email == "" {
+		return errors.New("Email é necessário")
+	}
+	_, err := shared.NormalizeEmail(c.Email)
+	return err
+}
+
+// Handle grava o novo e-mail como não confirmado e envia o link de confirmação.
+// Até a confirmação, o e-mail não pode ser usado no login.
+func (h *ChangeEmailHandler) Handle(command ChangeEmailCommand) (*models.User, error) {
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	email, err := checkEmailAvailable(h.Repo, command.Email, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if email == user.Email && user.EmailVerified() {
+		return user, nil
+	}
+
+	user.Email = email
+	user.EmailVerifiedAt = nil
+	if err := h.Repo.Update(user); err != nil {
+		return nil, errors.New("erro ao atualizar o e-mail")
+	}
+
+	if err := h.Verification.Send(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+type ResendEmailVerificationHandler struct {
+	Repo         repository.UserRepository
+	Verification *EmailVerification
+}
+
+// ResendEmailVerificationCommand representa o pedido de um novo link de confirmação do e-mail
+type ResendEmailVerificationCommand struct {
+	UserID uuid.UUID `json:"-"`
+}
+
+// Handle envia um novo link para o e-mail ainda não confirmado do usuário
+func (h *ResendEmailVerificationHandler) Handle(command ResendEmailVerificationCommand) error {
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	if user.Email == "" {
+		return ErrEmailNotSet
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	return h.Verification.Send(user)
+}
+
+type VerifyEmailHandler struct {
+	Repo repository.UserRepository
+	JWT  *shared.JWTManager
+}
+
+// VerifyEmailCommand representa a confirmação do e-mail pelo token do link enviado
+type VerifyEmailCommand struct {
+	Token string `json:"Token"`
+}
+
+// Validate realiza validações básicas no comando VerifyEmailCommand
+func (c *VerifyEmailCommand) Validate() error {
+	if c.Token == "" {
+		return errors.New("Token é necessário")
+	}
+	return nil
+}
+
+// Handle confirma o e-mail se o link for válido e ainda corresponder ao e-mail cadastrado.
+// Abrir o mesmo link novamente não altera a data da confirmação.
+func (h *VerifyEmailHandler) Handle(command VerifyEmailCommand) (*models.User, error) {
+	claims, err := h.JWT.VerifyEmailVerification(command.Token)
+	if err != nil {
+		return nil, ErrInvalidEmailVerification
+	}
+
+	user, err := h.Repo.FindByID(claims.UserID)
+	if err != nil || user == nil || user.Email == "" || user.Email != claims.Email {
+		return nil, ErrInvalidEmailVerification
+	}
+
+	if user.EmailVerified() {
+		return user, nil
+	}
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	if err := h.Repo.Update(user); err != nil {
+		return nil, errors.New("erro ao confirmar o e-mail")
+	}
+	return user, nil
+}
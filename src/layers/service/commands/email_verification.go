@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"errors"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/notification"
+	"server/src/layers/domain/repository"
+	"strings"
+	"time"
+)
+
+// EmailVerification emite o link assinado de confirmação do e-mail e o entrega ao usuário.
+// O link carrega o e-mail cadastrado, de modo que trocar o e-mail invalida os links já enviados.
+type EmailVerification struct {
+	JWT      *shared.JWTManager
+	Notifier notification.EmailVerificationNotifier
+	BaseURL  string // endereço público da api, usado para montar o link
+	TTL      time.Duration
+}
+
+// Send gera o link para o e-mail atual do usuário e o entrega pelo notificador
+func (v *EmailVerification) Send(user *models.User) error {
+	token, expiresAt, err := v.JWT.GenerateEmailVerification(user.ID, user.Email, v.TTL)
+	if err != nil {
+		return errors.New("erro ao gerar o link de confirmação")
+	}
+
+	link := strings.TrimRight(v.BaseURL, "/") + "/email/verify#token=" + url.QueryEscape(token)
+	if err := v.Notifier.NotifyEmailVerification(user, link, expiresAt); err != nil {
+		return errors.New("erro ao enviar o link de confirmação")
+	}
+	return nil
+}
+
+// checkEmailAvailable normaliza o e-mail e garante que ele não pertence a outro usuário
+func checkEmailAvailable(repo repository.UserRepository, email string, owner *models.User) (string, error) {
+	normalized, err := shared.NormalizeEmail(email)
+	if err != nil {
+		return "", err
+	}
+
+	existing, _ := repo.FindByEmail(normalized)
+	if existing != nil && (owner == nil || existing.ID != owner.ID) {
+		return "", ErrEmailTaken
+	}
+	return normalized, nil
+}
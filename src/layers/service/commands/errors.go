@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+)
+
+// hasherError preserva o erro de sobrecarga do Argon2Manager, que os handlers convertem em 503,
+// e substitui os demais pela mensagem informada
+func hasherError(err error, message string) error {
+	if errors.Is(err, shared.ErrHasherBusy) {
+		return err
+	}
+	return errors.New(message)
+}
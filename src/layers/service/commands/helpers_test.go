@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+)
+
+// setupDatabase abre um banco em memória exclusivo do teste e cria apenas as tabelas informadas
+func setupDatabase(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+url.QueryEscape(t.Name())+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Erro ao abrir o banco de dados: %v", err)
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("Erro na migração: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// newTestHasher usa parâmetros baixos do argon2 para manter os testes rápidos
+func newTestHasher() shared.PasswordHasher {
+	return shared.NewDefaultPasswordHasher(shared.NewArgon2ManagerWithParams(shared.Argon2Params{Memory: 8 * 1024, Threads: 1}))
+}
+
+// newTestUser cadastra um usuário com a senha informada no repositório
+func newTestUser(t *testing.T, repo repository.UserRepository, hasher shared.PasswordHasher, cpf, password string) *models.User {
+	t.Helper()
+
+	hashedPassword, err := hasher.HashPassword(password)
+	if err != nil {
+		t.Fatalf("Erro ao gerar o hash da senha: %v", err)
+	}
+
+	user, err := models.NewUser(cpf, "Ana", "Souza", hashedPassword)
+	if err != nil {
+		t.Fatalf("Erro ao criar o usuário: %v", err)
+	}
+	if _, err := repo.Store(user); err != nil {
+		t.Fatalf("Erro ao armazenar o usuário: %v", err)
+	}
+	return user
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"log"
+	"server/src/commons/shared"
+	"server/src/layers/domain/authn"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"sync"
+)
+
+// ErrExternalPassword indica que a senha da conta é verificada por um provedor externo e não pode ser alterada aqui
+var ErrExternalPassword = errors.New("a senha desta conta é gerenciada pelo provedor de identidade")
+
+// dummyPassword gera o hash verificado quando o identificador não tem senha local
+const dummyPassword = "senha-inexistente-para-equalizar-o-tempo"
+
+// dummyHashes guarda, por hasher, o hash de dummyPassword. Ele é gerado pelo próprio hasher para ter
+// os mesmos parâmetros e pepper das senhas armazenadas e, portanto, o mesmo custo de verificação.
+var dummyHashes sync.Map
+
+// LocalAuthenticator verifica a senha guardada no UserRepository. Usuários de provedores externos
+// não têm senha local e ficam para o próximo provedor da cadeia.
+type LocalAuthenticator struct {
+	Repo   repository.UserRepository
+	Hasher shared.PasswordHasher
+}
+
+// NewLocalAuthenticator cria um LocalAuthenticator.
+func NewLocalAuthenticator(repo repository.UserRepository, hasher shared.PasswordHasher) *LocalAuthenticator {
+	return &LocalAuthenticator{Repo: repo, Hasher: hasher}
+}
+
+// Name retorna o método registrado no histórico de logins.
+func (a *LocalAuthenticator) Name() string {
+	return models.SignInMethodPassword
+}
+
+// Authenticate busca o usuário pelo cpf ou pelo e-mail confirmado e compara a senha com a hash armazenada;
+// hashes legados são verificados pelo formato correspondente e refeitos com o hasher principal.
+func (a *LocalAuthenticator) Authenticate(identifier, password string) (*models.User, error) {
+	user := findUserByIdentifier(a.Repo, identifier)
+	if user == nil || user.IsExternal() {
+		// A senha é verificada mesmo assim, para que o tempo de resposta não revele quais contas existem
+		if _, err := a.Hasher.VerifyPassword(password, a.dummyHash()); errors.Is(err, shared.ErrHasherBusy) {
+			return nil, err
+		}
+		return nil, authn.ErrUnknownIdentity
+	}
+
+	match, err := a.Hasher.VerifyPassword(password, user.Password)
+	if errors.Is(err, shared.ErrHasherBusy) {
+		return nil, err
+	}
+	if err != nil || !match {
+		return nil, authn.ErrInvalidCredentials
+	}
+
+	a.rehashIfNeeded(user, password)
+	return user, nil
+}
+
+// dummyHash retorna o hash de dummyPassword gerado pelo hasher, criando-o na primeira consulta
+func (a *LocalAuthenticator) dummyHash() string {
+	if hash, ok := dummyHashes.Load(a.Hasher); ok {
+		return hash.(string)
+	}
+
+	hash, err := a.Hasher.HashPassword(dummyPassword)
+	if err != nil {
+		return ""
+	}
+	stored, _ := dummyHashes.LoadOrStore(a.Hasher, hash)
+	return stored.(string)
+}
+
+// rehashIfNeeded refaz com o hasher principal os hashes legados (bcrypt, scrypt, PBKDF2 ou salt$hash)
+// e os gerados com parâmetros desatualizados.
+// Uma falha não impede o login; o hash é refeito em uma próxima oportunidade.
+func (a *LocalAuthenticator) rehashIfNeeded(user *models.User, password string) {
+	if !a.Hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := a.Hasher.HashPassword(password)
+	if err == nil {
+		err = a.Repo.UpdatePassword(user.ID, hashedPassword)
+	}
+	if err != nil {
+		log.Printf("falha ao atualizar o hash da senha do usuário %s: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
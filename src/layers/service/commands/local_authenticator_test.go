@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/authn"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"testing"
+)
+
+// countingHasher conta as verificações de senha feitas pelo hasher envolvido
+type countingHasher struct {
+	shared.PasswordHasher
+	verifications int
+}
+
+func (h *countingHasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	h.verifications++
+	return h.PasswordHasher.VerifyPassword(password, encodedHash)
+}
+
+func TestLocalAuthenticator_VerifiesUnknownIdentities(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	hasher := &countingHasher{PasswordHasher: newTestHasher()}
+	newTestUser(t, users, hasher, "52998224725", "Senha-Correta-123")
+	users.Store(&models.User{CPF: "11144477735", AuthProvider: models.SignInMethodLDAP})
+
+	authenticator := NewLocalAuthenticator(users, hasher)
+
+	tests := []struct {
+		name       string
+		identifier string
+		wantErr    error
+	}{
+		{"Cpf desconhecido", "39053344705", authn.ErrUnknownIdentity},
+		{"Conta de provedor externo", "11144477735", authn.ErrUnknownIdentity},
+		{"Senha errada", "52998224725", authn.ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher.verifications = 0
+
+			if _, err := authenticator.Authenticate(tt.identifier, "Senha-Errada-456"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Esperava %v, obteve %v", tt.wantErr, err)
+			}
+			if hasher.verifications != 1 {
+				t.Errorf("Esperava uma verificação de senha para não revelar a conta, obteve %d", hasher.verifications)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+const (
+	// authorizationCodeSize é o número de bytes aleatórios de cada código de autorização
+	authorizationCodeSize = 32
+	// authorizationCodeDuration limita a janela entre o /oauth/authorize e a troca do código no /oauth/token
+	authorizationCodeDuration = 5 * time.Minute
+)
+
+// AuthorizeHandler emite os códigos de autorização. O escopo openid só é aceito quando o JWT assina com
+// chave assimétrica, pois o ID token precisa ser verificável pelo JWKS publicado.
+type AuthorizeHandler struct {
+	Repo     repository.UserRepository
+	Clients  repository.OAuthClientRepository
+	Codes    repository.AuthorizationCodeRepository
+	Consents repository.ConsentRepository
+	JWT      *shared.JWTManager
+}
+
+// AuthorizeCommand representa a autorização de um cliente pelo usuário autenticado.
+// Sem Approve, o código só é emitido se o usuário já consentiu com os escopos; com Approve, registra a decisão.
+// Nonce, Prompt e MaxAge são os parâmetros do OpenID Connect; AuthTime é o momento do login do usuário.
+type AuthorizeCommand struct {
+	UserID              uuid.UUID `json:"-"`
+	AuthTime            time.Time `json:"-"`
+	ResponseType        string    `json:"response_type"`
+	ClientID            string    `json:"client_id"`
+	RedirectURI         string    `json:"redirect_uri"`
+	Scope               string    `json:"scope"`
+	State               string    `json:"state"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	Nonce               string    `json:"nonce"`
+	Prompt              string    `json:"prompt"`
+	MaxAge              *int      `json:"max_age"`
+	Approve             *bool     `json:"approve"`
+}
+
+// AuthorizeResult indica para onde redirecionar o navegador ou, quando falta consentimento, o que apresentar ao usuário
+type AuthorizeResult struct {
+	RedirectURL     string          `json:"redirect_uri,omitempty"`
+	ConsentRequired *ConsentRequest `json:"consent,omitempty"`
+}
+
+// ConsentRequest descreve o pedido de acesso que o usuário precisa aprovar
+type ConsentRequest struct {
+	ClientID   string   `json:"client_id"`
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+}
+
+// Validate realiza validações básicas no comando AuthorizeCommand
+func (c *AuthorizeCommand) Validate() error {
+	if c.ClientID == "" {
+		return oauth.NewError(oauth.ErrInvalidRequest, "client_id é necessário")
+	}
+	return nil
+}
+
+// Handle valida o cliente e a URI de retorno e emite o código de autorização vinculado ao desafio PKCE.
+// Erros anteriores à validação da URI de retorno são retornados como *oauth.Error, pois não é seguro
+// redirecionar para ela; os demais são entregues ao cliente no redirecionamento.
+func (h *AuthorizeHandler) Handle(command AuthorizeCommand) (*AuthorizeResult, error) {
+	client, err := h.Clients.FindByClientID(command.ClientID)
+	if errors.Is(err, repository.ErrOAuthClientNotFound) {
+		return nil, oauth.NewError(oauth.ErrInvalidClient, "cliente não encontrado")
+	}
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrServerError, "erro ao consultar o cliente")
+	}
+
+	redirectURI := command.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return nil, oauth.NewError(oauth.ErrInvalidRequest, "redirect_uri não cadastrada para o cliente")
+	}
+
+	redirectError := func(code, description string) (*AuthorizeResult, error) {
+		return &AuthorizeResult{RedirectURL: buildRedirectURL(redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+		}, command.State)}, nil
+	}
+
+	if command.ResponseType != oauth.ResponseTypeCode {
+		return redirectError(oauth.ErrUnsupportedResponseType, "apenas response_type=code é suportado")
+	}
+	if !client.AllowsGrant(oauth.GrantAuthorizationCode) {
+		return redirectError(oauth.ErrUnauthorizedClient, "o cliente não pode usar authorization_code")
+	}
+	if command.CodeChallenge == "" || command.CodeChallengeMethod != oauth.CodeChallengeS256 {
+		return redirectError(oauth.ErrInvalidRequest, "code_challenge com code_challenge_method=S256 é obrigatório")
+	}
+
+	requested := oauth.ParseScope(command.Scope)
+	if len(requested) == 0 {
+		requested = client.Scopes
+	}
+	if !oauth.ContainsAll(client.Scopes, requested) {
+		return redirectError(oauth.ErrInvalidScope, "escopo não permitido para o cliente")
+	}
+	if oauth.ContainsAll(requested, []string{oauth.ScopeOpenID}) && !h.JWT.SupportsOpenID() {
+		return redirectError(oauth.ErrInvalidScope, shared.ErrOpenIDDisabled.Error())
+	}
+
+	// Sem uma sessão recente o bastante, o cliente precisa levar o usuário a um novo login
+	if command.MaxAge != nil && time.Since(command.AuthTime) > time.Duration(*command.MaxAge)*time.Second {
+		return redirectError(oauth.ErrLoginRequired, "o login do usuário é mais antigo que max_age")
+	}
+
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return redirectError(oauth.ErrAccessDenied, "usuário não encontrado")
+	}
+
+	// O usuário só pode delegar as permissões que possui
+	scopes := oauth.GrantScopes(requested, user)
+	if len(scopes) == 0 {
+		return redirectError(oauth.ErrInvalidScope, "o usuário não possui os escopos solicitados")
+	}
+
+	if command.Approve == nil {
+		consented, err := h.hasConsent(user.ID, client.ClientID, scopes)
+		if err != nil {
+			return redirectError(oauth.ErrServerError, "erro ao consultar o consentimento")
+		}
+		if command.Prompt == oauth.PromptConsent {
+			consented = false
+		}
+		if !consented && command.Prompt == oauth.PromptNone {
+			return redirectError(oauth.ErrConsentRequired, "o usuário ainda não autorizou o cliente")
+		}
+		if !consented {
+			return &AuthorizeResult{ConsentRequired: &ConsentRequest{
+				ClientID:   client.ClientID,
+				ClientName: client.Name,
+				Scopes:     scopes,
+			}}, nil
+		}
+	} else if !*command.Approve {
+		return redirectError(oauth.ErrAccessDenied, "o usuário negou o acesso")
+	} else if err := h.saveConsent(user.ID, client.ClientID, scopes); err != nil {
+		return redirectError(oauth.ErrServerError, "erro ao registrar o consentimento")
+	}
+
+	code, err := shared.GenerateRandomToken(authorizationCodeSize)
+	if err != nil {
+		return redirectError(oauth.ErrServerError, "erro ao gerar o código de autorização")
+	}
+
+	err = h.Codes.Store(&models.OAuthAuthorizationCode{
+		CodeHash:            shared.HashToken(code),
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         redirectURI,
+		Scope:               oauth.FormatScope(scopes),
+		CodeChallenge:       command.CodeChallenge,
+		CodeChallengeMethod: command.CodeChallengeMethod,
+		Nonce:               command.Nonce,
+		AuthTime:            command.AuthTime,
+		ExpiresAt:           time.Now().Add(authorizationCodeDuration),
+	})
+	if err != nil {
+		return redirectError(oauth.ErrServerError, "erro ao gravar o código de autorização")
+	}
+
+	return &AuthorizeResult{RedirectURL: buildRedirectURL(redirectURI, url.Values{"code": {code}}, command.State)}, nil
+}
+
+// hasConsent informa se o usuário já autorizou todos os escopos para o cliente
+func (h *AuthorizeHandler) hasConsent(userID uuid.UUID, clientID string, scopes []string) (bool, error) {
+	consent, err := h.Consents.Find(userID, clientID)
+	if errors.Is(err, repository.ErrConsentNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return oauth.ContainsAll(consent.Scopes, scopes), nil
+}
+
+// saveConsent soma os escopos aprovados aos que o usuário já havia concedido ao cliente
+func (h *AuthorizeHandler) saveConsent(userID uuid.UUID, clientID string, scopes []string) error {
+	granted := scopes
+	consent, err := h.Consents.Find(userID, clientID)
+	if err == nil {
+		granted = oauth.ParseScope(oauth.FormatScope(append(append([]string{}, consent.Scopes...), scopes...)))
+	} else if !errors.Is(err, repository.ErrConsentNotFound) {
+		return err
+	}
+
+	return h.Consents.Save(&models.OAuthConsent{
+		UserID:   userID,
+		ClientID: clientID,
+		Scopes:   models.StringList(granted),
+	})
+}
+
+// buildRedirectURL acrescenta os parâmetros e o state à URI de retorno, preservando a query já cadastrada
+func buildRedirectURL(redirectURI string, params url.Values, state string) string {
+	parsed, _ := url.Parse(redirectURI)
+
+	query := parsed.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+const testRedirectURI = "https://app.example.com/callback"
+
+// newTestOIDCJWT assina com uma chave ES256, necessária para emitir ID tokens
+func newTestOIDCJWT(t *testing.T) *shared.JWTManager {
+	t.Helper()
+
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signingKey, err := shared.NewSigningKey("chave-de-teste", "", privateKey)
+	if err != nil {
+		t.Fatalf("Erro ao criar a chave de assinatura: %v", err)
+	}
+	return shared.NewJWTManagerWithConfig(shared.JWTConfig{SigningKey: signingKey, TokenDuration: 15 * time.Minute, RefreshDuration: 24 * time.Hour})
+}
+
+// newTestAuthorizeHandler cadastra um cliente público com authorization_code e um usuário comum
+func newTestAuthorizeHandler(t *testing.T, jwt *shared.JWTManager) (*AuthorizeHandler, *models.User) {
+	t.Helper()
+
+	db := setupDatabase(t, &models.OAuthClient{}, &models.OAuthAuthorizationCode{}, &models.OAuthConsent{})
+	users := repository.NewMockUserRepository()
+	user := &models.User{CPF: "52998224725", FirstName: "Ana", LastName: "Souza", Roles: models.StringList{models.RoleUser}}
+	users.Store(user)
+
+	clients := persistence.NewOAuthClientRepository(db)
+	err := clients.Store(&models.OAuthClient{
+		ClientID:     "spa",
+		Name:         "SPA",
+		RedirectURIs: models.StringList{testRedirectURI},
+		Scopes:       models.StringList{oauth.ScopeOpenID, oauth.ScopeProfile, models.PermissionProfileRead},
+		GrantTypes:   models.StringList{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken},
+		Public:       true,
+	})
+	if err != nil {
+		t.Fatalf("Erro ao cadastrar o cliente: %v", err)
+	}
+
+	return &AuthorizeHandler{
+		Repo:     users,
+		Clients:  clients,
+		Codes:    persistence.NewAuthorizationCodeRepository(db),
+		Consents: persistence.NewConsentRepository(db),
+		JWT:      jwt,
+	}, user
+}
+
+// authorizeCode aprova a autorização e retorna o código entregue na URI de retorno
+func authorizeCode(t *testing.T, handler *AuthorizeHandler, user *models.User, scope, verifier string) string {
+	t.Helper()
+
+	approve := true
+	result, err := handler.Handle(AuthorizeCommand{
+		UserID:              user.ID,
+		AuthTime:            time.Now(),
+		ResponseType:        oauth.ResponseTypeCode,
+		ClientID:            "spa",
+		RedirectURI:         testRedirectURI,
+		Scope:               scope,
+		CodeChallenge:       oauth.CodeChallenge(verifier),
+		CodeChallengeMethod: oauth.CodeChallengeS256,
+		Nonce:               "nonce-de-teste",
+		Approve:             &approve,
+	})
+	if err != nil {
+		t.Fatalf("Erro na autorização: %v", err)
+	}
+
+	redirect, _ := url.Parse(result.RedirectURL)
+	code := redirect.Query().Get("code")
+	if code == "" {
+		t.Fatalf("Esperava o código na URI de retorno, obteve %s", result.RedirectURL)
+	}
+	return code
+}
+
+func TestAuthorizeHandler_OpenIDRequiresAsymmetricKey(t *testing.T) {
+	handler, user := newTestAuthorizeHandler(t, shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour))
+
+	approve := true
+	result, err := handler.Handle(AuthorizeCommand{
+		UserID:              user.ID,
+		AuthTime:            time.Now(),
+		ResponseType:        oauth.ResponseTypeCode,
+		ClientID:            "spa",
+		RedirectURI:         testRedirectURI,
+		Scope:               oauth.ScopeOpenID + " " + oauth.ScopeProfile,
+		CodeChallenge:       oauth.CodeChallenge("verificador-de-teste-com-mais-de-43-caracteres-0123456789"),
+		CodeChallengeMethod: oauth.CodeChallengeS256,
+		Approve:             &approve,
+	})
+	if err != nil {
+		t.Fatalf("Erro na autorização: %v", err)
+	}
+
+	redirect, _ := url.Parse(result.RedirectURL)
+	if redirect.Query().Get("error") != oauth.ErrInvalidScope || redirect.Query().Get("code") != "" {
+		t.Errorf("Esperava invalid_scope para openid com chave simétrica, obteve %s", result.RedirectURL)
+	}
+
+	// Os demais escopos continuam disponíveis sem o OpenID Connect
+	authorizeCode(t, handler, user, models.PermissionProfileRead, "verificador-de-teste-com-mais-de-43-caracteres-0123456789")
+}
+
+func TestAuthorizeHandler_OpenIDWithAsymmetricKey(t *testing.T) {
+	handler, user := newTestAuthorizeHandler(t, newTestOIDCJWT(t))
+
+	authorizeCode(t, handler, user, oauth.ScopeOpenID+" "+oauth.ScopeProfile, "verificador-de-teste-com-mais-de-43-caracteres-0123456789")
+}
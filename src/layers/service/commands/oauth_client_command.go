@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+)
+
+const (
+	// oauthClientIDSize e oauthClientSecretSize são os números de bytes aleatórios do client_id e do segredo
+	oauthClientIDSize     = 16
+	oauthClientSecretSize = 32
+)
+
+var ErrOAuthClientNotFound = errors.New("cliente oauth não encontrado")
+
+type RegisterOAuthClientHandler struct {
+	Clients repository.OAuthClientRepository
+}
+
+// RegisterOAuthClientCommand representa o cadastro de uma aplicação cliente do servidor de autorização
+type RegisterOAuthClientCommand struct {
+	Name         string   `json:"Name"`
+	RedirectURIs []string `json:"RedirectURIs"`
+	Scopes       []string `json:"Scopes"`
+	GrantTypes   []string `json:"GrantTypes"`
+	Public       bool     `json:"Public"` // clientes públicos não recebem segredo e só usam authorization_code com PKCE
+}
+
+// RegisteredOAuthClient devolve o segredo em claro junto com o cliente; ele não pode ser consultado depois
+type RegisteredOAuthClient struct {
+	*models.OAuthClient
+	ClientSecret string `json:"ClientSecret,omitempty"`
+}
+
+// Validate realiza validações básicas no comando RegisterOAuthClientCommand
+func (c *RegisterOAuthClientCommand) Validate() error {
+	if c.Name == "" {
+		return errors.New("Name é necessário")
+	}
+	if len(c.Scopes) == 0 {
+		return errors.New("Scopes é necessário")
+	}
+	if len(c.GrantTypes) == 0 {
+		return errors.New("GrantTypes é necessário")
+	}
+
+	for _, grantType := range c.GrantTypes {
+		switch grantType {
+		case oauth.GrantAuthorizationCode, oauth.GrantRefreshToken:
+		case oauth.GrantClientCredentials:
+			if c.Public {
+				return errors.New("clientes públicos não podem usar client_credentials")
+			}
+		default:
+			return fmt.Errorf("tipo de concessão não suportado: %s", grantType)
+		}
+	}
+
+	if models.StringList(c.GrantTypes).Contains(oauth.GrantAuthorizationCode) && len(c.RedirectURIs) == 0 {
+		return errors.New("RedirectURIs é necessário para authorization_code")
+	}
+	for _, redirectURI := range c.RedirectURIs {
+		if !validRedirectURI(redirectURI) {
+			return fmt.Errorf("URI de retorno inválida: %s", redirectURI)
+		}
+	}
+
+	return nil
+}
+
+// validRedirectURI exige uma URI absoluta e sem fragmento, como determina a RFC 6749
+func validRedirectURI(redirectURI string) bool {
+	parsed, err := url.Parse(redirectURI)
+	return err == nil && parsed.Scheme != "" && parsed.Host != "" && parsed.Fragment == ""
+}
+
+// Handle gera o client_id e, para clientes confidenciais, o segredo, gravando apenas o seu hash
+func (h *RegisterOAuthClientHandler) Handle(command RegisterOAuthClientCommand) (*RegisteredOAuthClient, error) {
+	clientID, err := shared.GenerateRandomToken(oauthClientIDSize)
+	if err != nil {
+		return nil, errors.New("erro ao gerar o client_id")
+	}
+
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Name:         command.Name,
+		RedirectURIs: models.StringList(command.RedirectURIs),
+		Scopes:       models.StringList(oauth.ParseScope(oauth.FormatScope(command.Scopes))),
+		GrantTypes:   models.StringList(command.GrantTypes),
+		Public:       command.Public,
+	}
+
+	var secret string
+	if !command.Public {
+		secret, err = shared.GenerateRandomToken(oauthClientSecretSize)
+		if err != nil {
+			return nil, errors.New("erro ao gerar o segredo do cliente")
+		}
+		client.SecretHash = shared.HashToken(secret)
+	}
+
+	if err := h.Clients.Store(client); err != nil {
+		return nil, errors.New("erro ao gravar o cliente oauth")
+	}
+
+	return &RegisteredOAuthClient{OAuthClient: client, ClientSecret: secret}, nil
+}
+
+type AuthenticateOAuthClientHandler struct {
+	Clients repository.OAuthClientRepository
+}
+
+// AuthenticateOAuthClientCommand representa uma requisição autenticada pelas credenciais de um cliente oauth
+type AuthenticateOAuthClientCommand struct {
+	ClientID     string `json:"-"`
+	ClientSecret string `json:"-"`
+}
+
+// Handle autentica o cliente e retorna o principal que o representa. Apenas clientes confidenciais
+// exercem os próprios escopos como permissões, como nos tokens da concessão client_credentials.
+// Os erros são *oauth.Error.
+func (h *AuthenticateOAuthClientHandler) Handle(command AuthenticateOAuthClientCommand) (*models.Principal, error) {
+	if command.ClientID == "" {
+		return nil, oauth.NewError(oauth.ErrInvalidClient, "client_id é necessário")
+	}
+
+	client, err := authenticateOAuthClient(h.Clients, command.ClientID, command.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &models.Principal{ClientID: client.ClientID}
+	if !client.Public {
+		principal.Permissions = client.Scopes
+	}
+	return principal, nil
+}
+
+type DeleteOAuthClientHandler struct {
+	Clients repository.OAuthClientRepository
+}
+
+// DeleteOAuthClientCommand representa a remoção de um cliente; seus refresh tokens deixam de ser aceitos
+type DeleteOAuthClientCommand struct {
+	ClientID string `json:"ClientID"`
+}
+
+// Validate realiza validações básicas no comando DeleteOAuthClientCommand
+func (c *DeleteOAuthClientCommand) Validate() error {
+	if c.ClientID == "" {
+		return errors.New("ClientID é necessário")
+	}
+	return nil
+}
+
+// Handle remove o cliente informado
+func (h *DeleteOAuthClientHandler) Handle(command DeleteOAuthClientCommand) error {
+	err := h.Clients.Delete(command.ClientID)
+	if errors.Is(err, repository.ErrOAuthClientNotFound) {
+		return ErrOAuthClientNotFound
+	}
+	if err != nil {
+		return errors.New("erro ao remover o cliente oauth")
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// oauthTokenType é o tipo de token informado nas respostas do /oauth/token
+const oauthTokenType = "Bearer"
+
+type OAuthTokenHandler struct {
+	Repo           repository.UserRepository
+	Clients        repository.OAuthClientRepository
+	Codes          repository.AuthorizationCodeRepository
+	Tokens         repository.RefreshTokenRepository
+	JWT            *shared.JWTManager
+	PasswordMaxAge time.Duration // idade máxima da senha; zero desativa a expiração
+}
+
+// OAuthTokenCommand representa uma requisição ao endpoint /oauth/token (RFC 6749, seção 4)
+type OAuthTokenCommand struct {
+	GrantType    string `json:"grant_type"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Code         string `json:"code"`
+	RedirectURI  string `json:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// OAuthTokenResponse é a resposta de sucesso do /oauth/token. O ID token só é emitido com o escopo openid
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// Validate realiza validações básicas no comando OAuthTokenCommand
+func (c *OAuthTokenCommand) Validate() error {
+	if c.GrantType == "" {
+		return oauth.NewError(oauth.ErrInvalidRequest, "grant_type é necessário")
+	}
+	if c.ClientID == "" {
+		return oauth.NewError(oauth.ErrInvalidClient, "client_id é necessário")
+	}
+	return nil
+}
+
+// Handle autentica o cliente e emite tokens conforme o tipo de concessão. Todos os erros são *oauth.Error.
+func (h *OAuthTokenHandler) Handle(command OAuthTokenCommand) (*OAuthTokenResponse, error) {
+	switch command.GrantType {
+	case oauth.GrantAuthorizationCode, oauth.GrantRefreshToken, oauth.GrantClientCredentials:
+	default:
+		return nil, oauth.NewError(oauth.ErrUnsupportedGrantType, "grant_type não suportado")
+	}
+
+	client, err := authenticateOAuthClient(h.Clients, command.ClientID, command.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(command.GrantType) {
+		return nil, oauth.NewError(oauth.ErrUnauthorizedClient, "o cliente não pode usar este grant_type")
+	}
+
+	switch command.GrantType {
+	case oauth.GrantAuthorizationCode:
+		return h.exchangeCode(client, command)
+	case oauth.GrantRefreshToken:
+		return h.refresh(client, command)
+	default:
+		return h.clientCredentials(client, command)
+	}
+}
+
+// authenticateOAuthClient exige o segredo dos clientes confidenciais; clientes públicos são identificados apenas pelo client_id
+func authenticateOAuthClient(clients repository.OAuthClientRepository, clientID, secret string) (*models.OAuthClient, error) {
+	client, err := clients.FindByClientID(clientID)
+	if errors.Is(err, repository.ErrOAuthClientNotFound) {
+		return nil, oauth.NewError(oauth.ErrInvalidClient, "cliente inválido")
+	}
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrServerError, "erro ao consultar o cliente")
+	}
+
+	if !client.Public {
+		if secret == "" || subtle.ConstantTimeCompare([]byte(shared.HashToken(secret)), []byte(client.SecretHash)) != 1 {
+			return nil, oauth.NewError(oauth.ErrInvalidClient, "cliente inválido")
+		}
+	}
+	return client, nil
+}
+
+// exchangeCode troca o código de autorização por tokens, conferindo a URI de retorno e o code_verifier do PKCE
+func (h *OAuthTokenHandler) exchangeCode(client *models.OAuthClient, command OAuthTokenCommand) (*OAuthTokenResponse, error) {
+	if command.Code == "" || command.CodeVerifier == "" {
+		return nil, oauth.NewError(oauth.ErrInvalidRequest, "code e code_verifier são necessários")
+	}
+
+	code, err := h.Codes.FindByHash(shared.HashToken(command.Code))
+	if err != nil || code.ClientID != client.ClientID || !code.IsUsable(time.Now()) {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "código de autorização inválido ou expirado")
+	}
+	if command.RedirectURI != code.RedirectURI {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "redirect_uri diferente da usada na autorização")
+	}
+	if !oauth.VerifyCodeChallenge(command.CodeVerifier, code.CodeChallenge, code.CodeChallengeMethod) {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "code_verifier inválido")
+	}
+
+	// Marca o código como usado; se outra requisição o consumiu primeiro, ele não é trocado novamente
+	marked, err := h.Codes.MarkUsed(code.ID)
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrServerError, "erro ao consumir o código de autorização")
+	}
+	if !marked {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "código de autorização inválido ou expirado")
+	}
+
+	user, err := h.Repo.FindByID(code.UserID)
+	if err != nil || user == nil {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "usuário não encontrado")
+	}
+
+	return h.issueUserTokens(client, user, oauth.ParseScope(code.Scope), uuid.New(), code.AuthTime, code.Nonce)
+}
+
+// refresh rotaciona o refresh token do cliente, permitindo reduzir o escopo concedido
+func (h *OAuthTokenHandler) refresh(client *models.OAuthClient, command OAuthTokenCommand) (*OAuthTokenResponse, error) {
+	if command.RefreshToken == "" {
+		return nil, oauth.NewError(oauth.ErrInvalidRequest, "refresh_token é necessário")
+	}
+
+	stored, err := consumeRefreshToken(h.JWT, h.Tokens, command.RefreshToken, client.ClientID)
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, err.Error())
+	}
+
+	scopes := oauth.ParseScope(stored.Scope)
+	if requested := oauth.ParseScope(command.Scope); len(requested) > 0 {
+		if !oauth.ContainsAll(scopes, requested) {
+			return nil, oauth.NewError(oauth.ErrInvalidScope, "o escopo excede o concedido originalmente")
+		}
+		scopes = requested
+	}
+
+	user, err := h.Repo.FindByID(stored.UserID)
+	if err != nil || user == nil {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "usuário não encontrado")
+	}
+	if user.PasswordExpired(h.PasswordMaxAge, time.Now()) {
+		return nil, oauth.NewError(oauth.ErrInvalidGrant, "a senha do usuário expirou")
+	}
+
+	return h.issueUserTokens(client, user, scopes, stored.FamilyID, stored.AuthenticatedAt(), "")
+}
+
+// clientCredentials emite um token de acesso em nome do próprio cliente, sem refresh token
+func (h *OAuthTokenHandler) clientCredentials(client *models.OAuthClient, command OAuthTokenCommand) (*OAuthTokenResponse, error) {
+	if client.Public {
+		return nil, oauth.NewError(oauth.ErrUnauthorizedClient, "clientes públicos não podem usar client_credentials")
+	}
+
+	scopes := oauth.ParseScope(command.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !oauth.ContainsAll(client.Scopes, scopes) {
+		return nil, oauth.NewError(oauth.ErrInvalidScope, "escopo não permitido para o cliente")
+	}
+
+	scope := oauth.FormatScope(scopes)
+	token, err := h.JWT.GenerateAccessToken(shared.TokenSubject{
+		Permissions: scopes,
+		Scope:       scope,
+		ClientID:    client.ClientID,
+	})
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrServerError, "falha ao gerar o token")
+	}
+
+	return &OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   oauthTokenType,
+		ExpiresIn:   int64(h.JWT.TokenDuration().Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// issueUserTokens emite o par de tokens do usuário para o cliente. As permissões são limitadas aos escopos
+// que o usuário ainda possui e os papéis não são repassados, para que o token nunca exceda o consentimento.
+// Com o escopo openid, também emite o ID token com o nonce da autorização e o momento do login.
+func (h *OAuthTokenHandler) issueUserTokens(client *models.OAuthClient, user *models.User, scopes []string, familyID uuid.UUID, authTime time.Time, nonce string) (*OAuthTokenResponse, error) {
+	granted := oauth.GrantScopes(scopes, user)
+	if len(granted) == 0 {
+		return nil, oauth.NewError(oauth.ErrInvalidScope, "o usuário não possui mais os escopos concedidos")
+	}
+	permissions := oauth.Intersect(granted, user.EffectivePermissions())
+	scope := oauth.FormatScope(granted)
+
+	pair, err := h.JWT.GeneratePair(shared.TokenSubject{
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		Permissions: permissions,
+		Scope:       scope,
+		ClientID:    client.ClientID,
+		AuthTime:    authTime,
+	}, familyID)
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrServerError, "falha ao gerar o token")
+	}
+
+	err = h.Tokens.Store(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  pair.FamilyID,
+		TokenID:   pair.RefreshTokenID,
+		ExpiresAt: pair.RefreshExpiresAt,
+		ClientID:  client.ClientID,
+		Scope:     scope,
+		AuthTime:  authTime,
+	})
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrServerError, "falha ao registrar o refresh token")
+	}
+
+	response := &OAuthTokenResponse{
+		AccessToken: pair.Token,
+		TokenType:   oauthTokenType,
+		ExpiresIn:   int64(h.JWT.TokenDuration().Seconds()),
+		Scope:       scope,
+	}
+	if client.AllowsGrant(oauth.GrantRefreshToken) {
+		response.RefreshToken = pair.RefreshToken
+	}
+
+	if oauth.ContainsAll(granted, []string{oauth.ScopeOpenID}) {
+		info := oauth.UserInfoFor(user, granted)
+		response.IDToken, err = h.JWT.GenerateIDToken(shared.IDTokenSubject{
+			UserID:     user.ID,
+			ClientID:   client.ClientID,
+			Nonce:      nonce,
+			AuthTime:   authTime,
+			Name:       info.Name,
+			GivenName:  info.GivenName,
+			FamilyName: info.FamilyName,
+			CPF:        info.CPF,
+		})
+		if err != nil {
+			return nil, oauth.NewError(oauth.ErrServerError, "falha ao gerar o id token")
+		}
+	}
+
+	return response, nil
+}
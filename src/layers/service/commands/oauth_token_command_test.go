@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+// oauthErrorCode extrai o código do erro OAuth retornado pelo handler
+func oauthErrorCode(err error) string {
+	var oauthErr *oauth.Error
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestOAuthTokenHandler_ExchangeCode(t *testing.T) {
+	const (
+		redirectURI = "https://app.example.com/callback"
+		code        = "codigo-de-autorizacao"
+		verifier    = "verificador-de-teste-com-mais-de-43-caracteres-0123456789"
+	)
+
+	db := setupDatabase(t, &models.OAuthClient{}, &models.OAuthAuthorizationCode{}, &models.RefreshToken{})
+	users := repository.NewMockUserRepository()
+	user := &models.User{CPF: "52998224725", Roles: models.StringList{models.RoleUser}}
+	users.Store(user)
+
+	clients := persistence.NewOAuthClientRepository(db)
+	clients.Store(&models.OAuthClient{
+		ClientID:     "spa",
+		Name:         "SPA",
+		RedirectURIs: models.StringList{redirectURI},
+		Scopes:       models.StringList{models.PermissionProfileRead},
+		GrantTypes:   models.StringList{oauth.GrantAuthorizationCode},
+		Public:       true,
+	})
+	codes := persistence.NewAuthorizationCodeRepository(db)
+	codes.Store(&models.OAuthAuthorizationCode{
+		CodeHash:            shared.HashToken(code),
+		ClientID:            "spa",
+		UserID:              user.ID,
+		RedirectURI:         redirectURI,
+		Scope:               models.PermissionProfileRead,
+		CodeChallenge:       oauth.CodeChallenge(verifier),
+		CodeChallengeMethod: oauth.CodeChallengeS256,
+		ExpiresAt:           time.Now().Add(time.Minute),
+	})
+
+	handler := OAuthTokenHandler{
+		Repo:    users,
+		Clients: clients,
+		Codes:   codes,
+		Tokens:  persistence.NewRefreshTokenRepository(db),
+		JWT:     shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour),
+	}
+	exchange := func(verifier string) (*OAuthTokenResponse, error) {
+		return handler.Handle(OAuthTokenCommand{
+			GrantType:    oauth.GrantAuthorizationCode,
+			ClientID:     "spa",
+			Code:         code,
+			RedirectURI:  redirectURI,
+			CodeVerifier: verifier,
+		})
+	}
+
+	tests := []struct {
+		name     string
+		verifier string
+		wantErr  string
+	}{
+		{"code_verifier diferente do desafio", "outro-verificador-com-mais-de-43-caracteres-0123456789", oauth.ErrInvalidGrant},
+		{"Troca com o code_verifier correto", verifier, ""},
+		{"Código reutilizado", verifier, oauth.ErrInvalidGrant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := exchange(tt.verifier)
+			if oauthErrorCode(err) != tt.wantErr {
+				t.Fatalf("Esperava o erro %q, obteve %v", tt.wantErr, err)
+			}
+			if err == nil && (response.AccessToken == "" || response.Scope != models.PermissionProfileRead) {
+				t.Errorf("Resposta inesperada: %+v", response)
+			}
+		})
+	}
+}
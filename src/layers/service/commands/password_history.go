@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/passwordpolicy"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// PasswordHistory impede que o usuário repita as últimas senhas e registra cada troca.
+// Size conta a senha atual: com 1 apenas a senha atual é recusada e zero desativa a verificação.
+type PasswordHistory struct {
+	Repo repository.PasswordHistoryRepository
+	Size int
+}
+
+// CheckReuse compara a nova senha com a atual e com as anteriores guardadas no histórico.
+// Cada comparação é um cálculo completo do hash, por isso Size deve ser pequeno.
+func (h *PasswordHistory) CheckReuse(hasher shared.PasswordHasher, user *models.User, field, password string) error {
+	if h == nil || h.Size <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.Password}
+	if h.Repo != nil && h.Size > 1 {
+		entries, err := h.Repo.FindRecentByUser(user.ID, h.Size-1)
+		if err != nil {
+			return errors.New("erro ao consultar o histórico de senhas")
+		}
+		for _, entry := range entries {
+			hashes = append(hashes, entry.Password)
+		}
+	}
+
+	for _, hash := range hashes {
+		match, err := hasher.VerifyPassword(password, hash)
+		if errors.Is(err, shared.ErrHasherBusy) {
+			return err
+		}
+		if match {
+			return &passwordpolicy.ValidationError{Errors: []passwordpolicy.FieldError{{
+				Field:   field,
+				Code:    passwordpolicy.CodeReused,
+				Message: fmt.Sprintf("a senha não pode repetir nenhuma das últimas %d senhas", h.Size),
+			}}}
+		}
+	}
+
+	return nil
+}
+
+// replacePassword grava a nova senha com a data da troca e move a senha anterior para o histórico
+func replacePassword(repo repository.UserRepository, history *PasswordHistory, user *models.User, hashedPassword string) error {
+	if err := repo.ChangePassword(user.ID, hashedPassword, time.Now()); err != nil {
+		return errors.New("erro ao atualizar a senha")
+	}
+
+	if history == nil || history.Repo == nil || history.Size <= 1 {
+		return nil
+	}
+
+	if err := history.Repo.Store(&models.PasswordHistory{UserID: user.ID, Password: user.Password}); err != nil {
+		return errors.New("erro ao registrar o histórico de senhas")
+	}
+	if err := history.Repo.PruneForUser(user.ID, history.Size-1); err != nil {
+		return errors.New("erro ao registrar o histórico de senhas")
+	}
+
+	return nil
+}
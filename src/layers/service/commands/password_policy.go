@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"errors"
+	"server/src/layers/domain/passwordpolicy"
+)
+
+// checkPasswordPolicy aplica a política de senhas, quando configurada, à senha informada no campo.
+// Os motivos de recusa são retornados como *passwordpolicy.ValidationError
+func checkPasswordPolicy(policy *passwordpolicy.Policy, field, password string, subject passwordpolicy.Subject) error {
+	if policy == nil {
+		return nil
+	}
+
+	err := policy.Check(field, password, subject)
+	var validation *passwordpolicy.ValidationError
+	if err != nil && !errors.As(err, &validation) {
+		return errors.New("erro ao validar a senha")
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/notification"
+	"server/src/layers/domain/passwordpolicy"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// resetTokenSize é o número de bytes aleatórios do token de redefinição de senha
+const resetTokenSize = 32
+
+var ErrInvalidResetToken = errors.New("token de redefinição inválido ou expirado")
+
+type RequestPasswordResetHandler struct {
+	Repo     repository.UserRepository
+	Resets   repository.PasswordResetRepository
+	Notifier notification.PasswordResetNotifier
+	TTL      time.Duration
+}
+
+// RequestPasswordResetCommand representa o pedido de um token de redefinição de senha
+type RequestPasswordResetCommand struct {
+	CPF string `json:"Cpf"`
+}
+
+// Validate realiza validações básicas no comando RequestPasswordResetCommand
+func (c *RequestPasswordResetCommand) Validate() error {
+	if c.CPF == "" {
+		return errors.New("Cpf é necessário")
+	}
+	return nil
+}
+
+// Handle gera um token de uso único, guarda apenas o seu hash e o entrega no e-mail confirmado do usuário.
+// Um cpf desconhecido não gera erro, para que a resposta não revele quais cpfs estão cadastrados;
+// contas sem e-mail confirmado, que não podem receber o token, e contas de provedores externos,
+// que não têm senha local, também são ignoradas.
+func (h *RequestPasswordResetHandler) Handle(command RequestPasswordResetCommand) error {
+	user, err := h.Repo.FindByCPF(command.CPF)
+	if err != nil || user == nil || user.IsExternal() || !user.EmailVerified() {
+		return nil
+	}
+
+	token, err := shared.GenerateRandomToken(resetTokenSize)
+	if err != nil {
+		return errors.New("erro ao gerar o token de redefinição")
+	}
+
+	// Apenas o último token solicitado permanece válido
+	if err := h.Resets.InvalidateForUser(user.ID); err != nil {
+		return errors.New("erro ao gerar o token de redefinição")
+	}
+
+	record := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: shared.HashToken(token),
+		ExpiresAt: time.Now().Add(h.TTL),
+	}
+	if err := h.Resets.Store(record); err != nil {
+		return errors.New("erro ao gerar o token de redefinição")
+	}
+
+	if err := h.Notifier.NotifyPasswordReset(user, token, record.ExpiresAt); err != nil {
+		return errors.New("erro ao enviar o token de redefinição")
+	}
+
+	return nil
+}
+
+type ConfirmPasswordResetHandler struct {
+	Repo           repository.UserRepository
+	Resets         repository.PasswordResetRepository
+	Tokens         repository.RefreshTokenRepository
+	Revocations    repository.TokenRevocationRepository
+	Hasher         shared.PasswordHasher
+	PasswordPolicy *passwordpolicy.Policy
+	History        *PasswordHistory
+	JWT            *shared.JWTManager
+}
+
+// ConfirmPasswordResetCommand representa a troca de senha usando um token de redefinição
+type ConfirmPasswordResetCommand struct {
+	Token       string `json:"Token"`
+	NewPassword string `json:"NewPassword"`
+}
+
+// Validate realiza validações básicas no comando ConfirmPasswordResetCommand
+func (c *ConfirmPasswordResetCommand) Validate() error {
+	if c.Token == "" {
+		return errors.New("Token é necessário")
+	}
+	if c.NewPassword == "" {
+		return errors.New("NewPassword é necessário")
+	}
+	return nil
+}
+
+// Handle consome o token, grava a nova senha e encerra todas as sessões existentes do usuário
+func (h *ConfirmPasswordResetHandler) Handle(command ConfirmPasswordResetCommand) error {
+	record, err := h.Resets.FindByHash(shared.HashToken(command.Token))
+	if err != nil || !record.IsUsable(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := h.Repo.FindByID(record.UserID)
+	if err != nil || user == nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := checkPasswordPolicy(h.PasswordPolicy, "NewPassword", command.NewPassword, passwordpolicy.SubjectFromUser(user)); err != nil {
+		return err
+	}
+
+	if err := h.History.CheckReuse(h.Hasher, user, "NewPassword", command.NewPassword); err != nil {
+		return err
+	}
+
+	// O hash é calculado antes de consumir o token para que uma sobrecarga não o invalide
+	hashedPassword, err := h.Hasher.HashPassword(command.NewPassword)
+	if err != nil {
+		return hasherError(err, "erro ao criptografar a senha")
+	}
+
+	marked, err := h.Resets.MarkUsed(record.ID)
+	if err != nil || !marked {
+		return ErrInvalidResetToken
+	}
+
+	if err := replacePassword(h.Repo, h.History, user, hashedPassword); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := h.Revocations.RevokeAllForUser(record.UserID, now, now.Add(h.JWT.TokenDuration())); err != nil {
+		return errors.New("falha ao encerrar as sessões")
+	}
+	if err := h.Tokens.RevokeAllForUser(record.UserID); err != nil {
+		return errors.New("falha ao encerrar as sessões")
+	}
+
+	return nil
+}
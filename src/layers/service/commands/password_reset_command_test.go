@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+// resetNotifier guarda o último token entregue, no lugar do envio ao usuário
+type resetNotifier struct {
+	token string
+}
+
+func (n *resetNotifier) NotifyPasswordReset(user *models.User, token string, expiresAt time.Time) error {
+	n.token = token
+	return nil
+}
+
+func TestConfirmPasswordResetHandler_RevokesSessions(t *testing.T) {
+	db := setupDatabase(t, &models.RefreshToken{}, &models.PasswordResetToken{})
+	users := repository.NewMockUserRepository()
+	verifiedAt := time.Now()
+	user := &models.User{CPF: "52998224725", FirstName: "Ana", LastName: "Souza", Email: "ana@example.com", EmailVerifiedAt: &verifiedAt}
+	users.Store(user)
+
+	jwt := shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour)
+	hasher := shared.NewDefaultPasswordHasher(shared.NewArgon2Manager())
+	tokens := persistence.NewRefreshTokenRepository(db)
+	resets := persistence.NewPasswordResetRepository(db)
+	revocations := persistence.NewMemoryTokenRevocationRepository()
+	notifier := &resetNotifier{}
+
+	request := RequestPasswordResetHandler{Repo: users, Resets: resets, Notifier: notifier, TTL: time.Hour}
+	confirm := ConfirmPasswordResetHandler{Repo: users, Resets: resets, Tokens: tokens, Revocations: revocations, Hasher: hasher, JWT: jwt}
+
+	login, err := issueTokens(jwt, tokens, user, uuid.New(), time.Now(), 0)
+	if err != nil {
+		t.Fatalf("Erro ao emitir os tokens do login: %v", err)
+	}
+	claims, _ := jwt.Verify(login.Key.Token)
+
+	if err := request.Handle(RequestPasswordResetCommand{CPF: user.CPF}); err != nil || notifier.token == "" {
+		t.Fatalf("Esperava o envio do token de redefinição, obteve %v", err)
+	}
+	if err := confirm.Handle(ConfirmPasswordResetCommand{Token: notifier.token, NewPassword: "Senha-Nova-456"}); err != nil {
+		t.Fatalf("Erro ao redefinir a senha: %v", err)
+	}
+
+	if match, _ := hasher.VerifyPassword("Senha-Nova-456", user.Password); !match {
+		t.Error("A nova senha deveria ter sido gravada")
+	}
+
+	// As sessões abertas antes da redefinição são encerradas
+	if revoked, _ := revocations.IsRevoked(claims.Id, user.ID, claims.IssuedTime()); !revoked {
+		t.Error("O token de acesso emitido antes da redefinição deveria estar revogado")
+	}
+	refresh := RefreshTokenHandler{Repo: users, Tokens: tokens, JWT: jwt}
+	if _, err := refresh.Handle(RefreshTokenCommand{RefreshToken: login.Key.RefreshToken}); err == nil {
+		t.Error("O refresh token emitido antes da redefinição não deveria ser aceito")
+	}
+
+	// O token de redefinição é de uso único
+	if err := confirm.Handle(ConfirmPasswordResetCommand{Token: notifier.token, NewPassword: "Senha-Outra-789"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Esperava recusar o token de redefinição já usado, obteve %v", err)
+	}
+}
+
+func TestRequestPasswordResetHandler_Ignored(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	users.Store(&models.User{CPF: "52998224725", FirstName: "Ana"})
+	users.Store(&models.User{CPF: "11144477735", FirstName: "Bia", Email: "bia@example.com"})
+
+	tests := []struct {
+		name string
+		cpf  string
+	}{
+		{"Cpf desconhecido", "39053344705"},
+		{"Conta sem e-mail", "52998224725"},
+		{"Conta com e-mail não confirmado", "11144477735"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &resetNotifier{}
+			request := RequestPasswordResetHandler{
+				Repo:     users,
+				Resets:   persistence.NewPasswordResetRepository(setupDatabase(t, &models.PasswordResetToken{})),
+				Notifier: notifier,
+				TTL:      time.Hour,
+			}
+
+			// A resposta é a mesma de uma conta que recebe o token, mas nada é enviado
+			if err := request.Handle(RequestPasswordResetCommand{CPF: tt.cpf}); err != nil {
+				t.Fatalf("O pedido não deveria gerar erro, obteve %v", err)
+			}
+			if notifier.token != "" {
+				t.Error("Nenhum token deveria ser enviado")
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/notification"
+	"server/src/layers/domain/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// loginCodeDigits é o tamanho do código de login sem senha
+	loginCodeDigits = 6
+	// loginTokenSize é o número de bytes aleatórios do token do link de login
+	loginTokenSize = 32
+	// maxLoginCodeAttempts é o número de códigos errados que invalida o pedido
+	maxLoginCodeAttempts = 5
+)
+
+var ErrInvalidLoginCode = errors.New("código ou link de acesso inválido ou expirado")
+
+// canSignInWithoutPassword informa se o usuário pode entrar por link ou código: é preciso um e-mail
+// confirmado para recebê-los e a permissão de login sem senha.
+func canSignInWithoutPassword(user *models.User) bool {
+	return user.EmailVerified() && models.StringList(user.EffectivePermissions()).Contains(models.PermissionPasswordlessSignIn)
+}
+
+// hashLoginCode calcula o hash do código vinculado ao usuário, já que o mesmo código pode ser sorteado para outras contas
+func hashLoginCode(user *models.User, code string) string {
+	return shared.HashToken(user.ID.String() + ":" + code)
+}
+
+type RequestLoginCodeHandler struct {
+	Repo        repository.UserRepository
+	Codes       repository.LoginCodeRepository
+	Notifier    notification.LoginCodeNotifier
+	BaseURL     string // endereço público da api, usado para montar o link
+	TTL         time.Duration
+	MaxRequests int           // pedidos aceitos por usuário dentro de Window
+	Window      time.Duration // janela da contagem de pedidos
+}
+
+// RequestLoginCodeCommand representa o pedido de um link e código de login enviados por e-mail
+type RequestLoginCodeCommand struct {
+	Identifier string `json:"Identifier"`
+}
+
+// Validate realiza validações básicas no comando RequestLoginCodeCommand
+func (c *RequestLoginCodeCommand) Validate() error {
+	if strings.TrimSpace(c.Identifier) == "" {
+		return errors.New("Identifier é necessário")
+	}
+	return nil
+}
+
+// Handle gera o link e o código, guarda apenas os seus hashes e os envia ao e-mail confirmado do usuário.
+// Contas inexistentes, sem permissão ou acima do limite de pedidos não geram erro, para que a resposta
+// não revele quais contas existem ou podem entrar sem senha.
+func (h *RequestLoginCodeHandler) Handle(command RequestLoginCodeCommand) error {
+	user := findUserByIdentifier(h.Repo, strings.TrimSpace(command.Identifier))
+	if user == nil || !canSignInWithoutPassword(user) {
+		return nil
+	}
+
+	now := time.Now()
+	requests, err := h.Codes.CountSince(user.ID, now.Add(-h.Window))
+	if err != nil {
+		return errors.New("erro ao gerar o código de acesso")
+	}
+	if requests >= int64(h.MaxRequests) {
+		log.Printf("limite de pedidos de login sem senha atingido para o usuário %s", user.ID)
+		return nil
+	}
+
+	token, err := shared.GenerateRandomToken(loginTokenSize)
+	if err != nil {
+		return errors.New("erro ao gerar o código de acesso")
+	}
+	code, err := shared.GenerateNumericCode(loginCodeDigits)
+	if err != nil {
+		return errors.New("erro ao gerar o código de acesso")
+	}
+
+	// Apenas o último pedido permanece válido
+	if err := h.Codes.InvalidateForUser(user.ID); err != nil {
+		return errors.New("erro ao gerar o código de acesso")
+	}
+
+	record := &models.LoginCode{
+		UserID:    user.ID,
+		TokenHash: shared.HashToken(token),
+		CodeHash:  hashLoginCode(user, code),
+		ExpiresAt: now.Add(h.TTL),
+	}
+	if err := h.Codes.Store(record); err != nil {
+		return errors.New("erro ao gerar o código de acesso")
+	}
+
+	// O link abre a página que confirma o login por POST; o token vai no fragmento, que não chega aos logs do servidor
+	link := strings.TrimRight(h.BaseURL, "/") + "/sign-in/passwordless/link#token=" + url.QueryEscape(token)
+	if err := h.Notifier.NotifyLoginCode(user, code, link, record.ExpiresAt); err != nil {
+		return errors.New("erro ao enviar o código de acesso")
+	}
+
+	return nil
+}
+
+type RedeemLoginCodeHandler struct {
+	Repo  repository.UserRepository
+	Codes repository.LoginCodeRepository
+	// SignIn emite os tokens como no login com senha e controla as tentativas pelo seu Limiter
+	SignIn *CreateTokenHandler
+}
+
+// RedeemLoginCodeCommand representa o uso do link (Token) ou do código (Identifier e Code) recebidos por e-mail
+type RedeemLoginCodeCommand struct {
+	Token      string `json:"Token"`
+	Identifier string `json:"Identifier"`
+	Code       string `json:"Code"`
+	IP         string `json:"-"`
+	UserAgent  string `json:"-"`
+}
+
+// Validate realiza validações básicas no comando RedeemLoginCodeCommand
+func (c *RedeemLoginCodeCommand) Validate() error {
+	if c.Token != "" {
+		return nil
+	}
+	if strings.TrimSpace(c.Identifier) == "" || strings.TrimSpace(c.Code) == "" {
+		return errors.New("Token ou Identifier e Code são necessários")
+	}
+	return nil
+}
+
+// Handle consome o pedido de login e emite os tokens, ou o desafio do segundo fator quando ativo
+func (h *RedeemLoginCodeHandler) Handle(command RedeemLoginCodeCommand) (*TokenResponse, error) {
+	if command.Token != "" {
+		return h.redeemLink(command)
+	}
+	return h.redeemCode(command)
+}
+
+// redeemLink consome o pedido pelo token do link, que tem entropia suficiente para dispensar o controle de tentativas
+func (h *RedeemLoginCodeHandler) redeemLink(command RedeemLoginCodeCommand) (*TokenResponse, error) {
+	record, err := h.Codes.FindByTokenHash(shared.HashToken(command.Token))
+	if err != nil || !record.IsUsable(time.Now()) {
+		return nil, ErrInvalidLoginCode
+	}
+
+	user, err := h.Repo.FindByID(record.UserID)
+	if err != nil || user == nil || !canSignInWithoutPassword(user) {
+		return nil, ErrInvalidLoginCode
+	}
+
+	marked, err := h.Codes.MarkUsed(record.ID)
+	if err != nil || !marked {
+		return nil, ErrInvalidLoginCode
+	}
+
+	return h.SignIn.IssueForUser(user, models.SignInMethodMagicLink, command.IP, command.UserAgent)
+}
+
+// redeemCode consome o pedido pelo código de 6 dígitos. As falhas contam para o bloqueio da conta e do IP
+// como as senhas erradas, e o pedido é descartado após maxLoginCodeAttempts códigos errados.
+func (h *RedeemLoginCodeHandler) redeemCode(command RedeemLoginCodeCommand) (*TokenResponse, error) {
+	identifier := strings.TrimSpace(command.Identifier)
+	user := findUserByIdentifier(h.Repo, identifier)
+
+	account := identifier
+	if user != nil {
+		account = user.CPF
+	}
+
+	// Como no login com senha, a tentativa é contada antes da comparação e devolvida apenas no sucesso
+	if err := reserveAttempt(h.SignIn.Limiter, account, command.IP); err != nil {
+		return nil, err
+	}
+
+	if user == nil || !canSignInWithoutPassword(user) {
+		return nil, ErrInvalidLoginCode
+	}
+
+	record, err := h.Codes.FindActiveByUser(user.ID, time.Now())
+	if err != nil {
+		return nil, ErrInvalidLoginCode
+	}
+
+	expected := []byte(record.CodeHash)
+	if subtle.ConstantTimeCompare(expected, []byte(hashLoginCode(user, strings.TrimSpace(command.Code)))) != 1 {
+		attempts, err := h.Codes.RecordAttempt(record.ID)
+		if err == nil && attempts >= maxLoginCodeAttempts {
+			h.Codes.MarkUsed(record.ID)
+		}
+		recordFailedSignIn(h.SignIn.History, user.ID, models.SignInMethodEmailCode, models.SignInFailureInvalidCode, command.IP, command.UserAgent)
+		return nil, ErrInvalidLoginCode
+	}
+
+	marked, err := h.Codes.MarkUsed(record.ID)
+	if err != nil || !marked {
+		return nil, ErrInvalidLoginCode
+	}
+
+	if err := recordSuccessfulAttempt(h.SignIn.Limiter, account, command.IP); err != nil {
+		return nil, err
+	}
+
+	return h.SignIn.IssueForUser(user, models.SignInMethodEmailCode, command.IP, command.UserAgent)
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"errors"
+	"net/url"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loginCodeNotifier guarda o último código e link entregues, no lugar do envio por e-mail
+type loginCodeNotifier struct {
+	code string
+	link string
+}
+
+func (n *loginCodeNotifier) NotifyLoginCode(user *models.User, code, link string, expiresAt time.Time) error {
+	n.code, n.link = code, link
+	return nil
+}
+
+// newTestPasswordless cadastra um usuário do backoffice com e-mail confirmado e os handlers de pedido e uso do login sem senha.
+// Sem Limiter, apenas o limite de códigos errados do pedido é exercitado.
+func newTestPasswordless(t *testing.T) (*RequestLoginCodeHandler, *RedeemLoginCodeHandler, *loginCodeNotifier) {
+	t.Helper()
+
+	db := setupDatabase(t, &models.LoginCode{}, &models.RefreshToken{})
+	users := repository.NewMockUserRepository()
+	verifiedAt := time.Now()
+	users.Store(&models.User{
+		CPF:             "52998224725",
+		Email:           "ana@example.com",
+		EmailVerifiedAt: &verifiedAt,
+		Roles:           models.StringList{models.RoleBackoffice},
+	})
+
+	codes := persistence.NewLoginCodeRepository(db)
+	notifier := &loginCodeNotifier{}
+	request := &RequestLoginCodeHandler{
+		Repo:        users,
+		Codes:       codes,
+		Notifier:    notifier,
+		BaseURL:     "https://api.example.com",
+		TTL:         15 * time.Minute,
+		MaxRequests: 10,
+		Window:      time.Hour,
+	}
+	signIn := &CreateTokenHandler{
+		Repo:   users,
+		Tokens: persistence.NewRefreshTokenRepository(db),
+		JWT:    shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour),
+	}
+	return request, &RedeemLoginCodeHandler{Repo: users, Codes: codes, SignIn: signIn}, notifier
+}
+
+func TestRedeemLoginCodeHandler_Link(t *testing.T) {
+	request, redeem, notifier := newTestPasswordless(t)
+
+	if err := request.Handle(RequestLoginCodeCommand{Identifier: "ana@example.com"}); err != nil {
+		t.Fatalf("Erro ao pedir o link de acesso: %v", err)
+	}
+
+	// O token vai no fragmento do link, lido pela página que confirma o login
+	link, err := url.Parse(notifier.link)
+	if err != nil || !strings.HasPrefix(link.Fragment, "token=") {
+		t.Fatalf("Esperava o token no fragmento do link, obteve %s", notifier.link)
+	}
+	token := strings.TrimPrefix(link.Fragment, "token=")
+
+	response, err := redeem.Handle(RedeemLoginCodeCommand{Token: token})
+	if err != nil || response.Key.Token == "" {
+		t.Fatalf("Esperava entrar pelo link, obteve %v", err)
+	}
+	if _, err := redeem.Handle(RedeemLoginCodeCommand{Token: token}); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Errorf("Esperava recusar o link já usado, obteve %v", err)
+	}
+}
+
+func TestRedeemLoginCodeHandler_CodeIsSingleUse(t *testing.T) {
+	request, redeem, notifier := newTestPasswordless(t)
+
+	if err := request.Handle(RequestLoginCodeCommand{Identifier: "52998224725"}); err != nil {
+		t.Fatalf("Erro ao pedir o código de acesso: %v", err)
+	}
+
+	command := RedeemLoginCodeCommand{Identifier: "ana@example.com", Code: notifier.code}
+	if _, err := redeem.Handle(command); err != nil {
+		t.Fatalf("Esperava entrar com o código, obteve %v", err)
+	}
+	if _, err := redeem.Handle(command); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Errorf("Esperava recusar o código já usado, obteve %v", err)
+	}
+}
+
+func TestRedeemLoginCodeHandler_DiscardsAfterMaxAttempts(t *testing.T) {
+	request, redeem, notifier := newTestPasswordless(t)
+
+	if err := request.Handle(RequestLoginCodeCommand{Identifier: "ana@example.com"}); err != nil {
+		t.Fatalf("Erro ao pedir o código de acesso: %v", err)
+	}
+
+	wrong := "000000"
+	if notifier.code == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < maxLoginCodeAttempts; i++ {
+		if _, err := redeem.Handle(RedeemLoginCodeCommand{Identifier: "ana@example.com", Code: wrong}); !errors.Is(err, ErrInvalidLoginCode) {
+			t.Fatalf("Esperava recusar o código errado na tentativa %d, obteve %v", i+1, err)
+		}
+	}
+
+	// Esgotadas as tentativas, nem o código correto é aceito
+	if _, err := redeem.Handle(RedeemLoginCodeCommand{Identifier: "ana@example.com", Code: notifier.code}); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Errorf("Esperava descartar o pedido após %d códigos errados, obteve %v", maxLoginCodeAttempts, err)
+	}
+}
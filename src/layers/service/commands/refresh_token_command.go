@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"log"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token inválido")
+	ErrRefreshTokenReused  = errors.New("refresh token já utilizado, sessão encerrada")
+)
+
+type RefreshTokenHandler struct {
+	Repo           repository.UserRepository
+	Tokens         repository.RefreshTokenRepository
+	JWT            *shared.JWTManager
+	PasswordMaxAge time.Duration // idade máxima da senha; zero desativa a expiração
+	// Sessions registra a última atividade da sessão a cada renovação; nil desativa o registro
+	Sessions repository.SessionRepository
+}
+
+// RefreshTokenCommand representa a intenção de trocar um refresh token por um novo par de tokens
+type RefreshTokenCommand struct {
+	RefreshToken string `json:"RefreshToken"`
+}
+
+// Validate realiza validações básicas no comando RefreshTokenCommand
+func (c *RefreshTokenCommand) Validate() error {
+	if c.RefreshToken == "" {
+		return errors.New("RefreshToken é necessário")
+	}
+	return nil
+}
+
+// Handle valida o refresh token, rotaciona-o dentro da mesma família e emite um novo par de tokens.
+// Se um refresh token já consumido for reapresentado, toda a família é revogada.
+// Refresh tokens emitidos para clientes OAuth só são aceitos no /oauth/token.
+func (h *RefreshTokenHandler) Handle(command RefreshTokenCommand) (*TokenResponse, error) {
+	stored, err := consumeRefreshToken(h.JWT, h.Tokens, command.RefreshToken, "")
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.Repo.FindByID(stored.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	response, err := issueTokens(h.JWT, h.Tokens, user, stored.FamilyID, stored.AuthenticatedAt(), h.PasswordMaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	h.touchSession(stored.FamilyID)
+	return response, nil
+}
+
+// touchSession registra a renovação como atividade da sessão; uma falha não impede a renovação
+func (h *RefreshTokenHandler) touchSession(familyID uuid.UUID) {
+	if h.Sessions == nil {
+		return
+	}
+
+	session, err := h.Sessions.FindByFamilyID(familyID)
+	if err == nil {
+		err = h.Sessions.Touch(session.ID, time.Now())
+	}
+	if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		log.Printf("falha ao registrar a atividade da sessão %s: %v", familyID, err)
+	}
+}
+
+// consumeRefreshToken valida o refresh token emitido para o cliente informado (vazio para o próprio servidor)
+// e o marca como usado, retornando o registro para que a família seja rotacionada.
+func consumeRefreshToken(jwt *shared.JWTManager, tokens repository.RefreshTokenRepository, refreshToken, clientID string) (*models.RefreshToken, error) {
+	claims, err := jwt.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	stored, err := tokens.FindByTokenID(claims.Id)
+	if err != nil || stored.UserID != claims.UserID || stored.FamilyID != claims.FamilyID || stored.ClientID != clientID {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.IsConsumed() {
+		return nil, revokeFamily(tokens, stored.FamilyID)
+	}
+
+	if stored.IsExpired(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Marca o token como usado; se outra requisição o consumiu primeiro, trata como reutilização
+	marked, err := tokens.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, errors.New("falha ao rotacionar o refresh token")
+	}
+	if !marked {
+		return nil, revokeFamily(tokens, stored.FamilyID)
+	}
+
+	return stored, nil
+}
+
+// revokeFamily encerra a família após detectar a reutilização de um refresh token
+func revokeFamily(tokens repository.RefreshTokenRepository, familyID uuid.UUID) error {
+	log.Printf("reutilização de refresh token detectada, revogando a família %s", familyID)
+	if err := tokens.RevokeFamily(familyID); err != nil {
+		log.Printf("falha ao revogar a família %s: %v", familyID, err)
+	}
+	return ErrRefreshTokenReused
+}
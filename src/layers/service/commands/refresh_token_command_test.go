@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokenHandler_RotatesAndDetectsReuse(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	user := &models.User{CPF: "52998224725", FirstName: "Ana", LastName: "Souza"}
+	users.Store(user)
+
+	jwt := shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour)
+	tokens := persistence.NewRefreshTokenRepository(setupDatabase(t, &models.RefreshToken{}))
+	handler := RefreshTokenHandler{Repo: users, Tokens: tokens, JWT: jwt}
+
+	login, err := issueTokens(jwt, tokens, user, uuid.New(), time.Now(), 0)
+	if err != nil {
+		t.Fatalf("Erro ao emitir os tokens do login: %v", err)
+	}
+	first := login.Key.RefreshToken
+
+	rotated, err := handler.Handle(RefreshTokenCommand{RefreshToken: first})
+	if err != nil {
+		t.Fatalf("Esperava renovar com o refresh token do login, obteve %v", err)
+	}
+	second := rotated.Key.RefreshToken
+	if second == "" || second == first {
+		t.Fatalf("Esperava um novo refresh token na renovação")
+	}
+
+	// Reapresentar o token já rotacionado encerra a família inteira, inclusive o token mais recente
+	if _, err := handler.Handle(RefreshTokenCommand{RefreshToken: first}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Esperava detectar a reutilização do refresh token, obteve %v", err)
+	}
+	if _, err := handler.Handle(RefreshTokenCommand{RefreshToken: second}); err == nil {
+		t.Fatalf("O refresh token mais recente da família revogada não deveria ser aceito")
+	}
+}
+
+func TestRefreshTokenHandler_RejectsInvalidTokens(t *testing.T) {
+	jwt := shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour)
+	tokens := persistence.NewRefreshTokenRepository(setupDatabase(t, &models.RefreshToken{}))
+	handler := RefreshTokenHandler{Repo: repository.NewMockUserRepository(), Tokens: tokens, JWT: jwt}
+
+	// Um refresh token bem assinado mas nunca registrado também é recusado
+	unknown, _ := jwt.GeneratePair(shared.TokenSubject{UserID: uuid.New()}, uuid.New())
+	otherKey, _ := shared.NewJWTManager("outro-segredo", 15*time.Minute, 24*time.Hour).GeneratePair(shared.TokenSubject{UserID: uuid.New()}, uuid.New())
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "token-invalido"},
+		{"access token", unknown.Token},
+		{"not stored", unknown.RefreshToken},
+		{"other signing key", otherKey.RefreshToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := handler.Handle(RefreshTokenCommand{RefreshToken: tt.token}); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("Esperava ErrInvalidRefreshToken, obteve %v", err)
+			}
+		})
+	}
+}
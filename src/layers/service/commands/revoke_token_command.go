@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/introspection"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+type RevokeTokenHandler struct {
+	Inspector   *introspection.Inspector
+	Revocations repository.TokenRevocationRepository
+	Tokens      repository.RefreshTokenRepository
+	Keys        repository.APIKeyRepository
+}
+
+// RevokeTokenCommand representa a revogação de um token pelo /oauth/revoke (RFC 7009)
+type RevokeTokenCommand struct {
+	Token         string            `json:"token"`
+	TokenTypeHint string            `json:"token_type_hint"`
+	Requester     *models.Principal `json:"-"`
+}
+
+// Validate realiza validações básicas no comando RevokeTokenCommand
+func (c *RevokeTokenCommand) Validate() error {
+	if c.Token == "" {
+		return oauth.NewError(oauth.ErrInvalidRequest, "token é necessário")
+	}
+	return nil
+}
+
+// Handle revoga o token. Tokens desconhecidos ou já inativos não geram erro, conforme a RFC 7009.
+// Sem a permissão tokens:revoke, clientes só revogam os tokens emitidos para eles e chaves de api apenas a si mesmas.
+// Os erros de autorização são *oauth.Error.
+func (h *RevokeTokenHandler) Handle(command RevokeTokenCommand) error {
+	token, err := h.Inspector.Inspect(command.Token, command.TokenTypeHint)
+	if errors.Is(err, introspection.ErrUnknownToken) {
+		return nil
+	}
+	if err != nil {
+		return oauth.NewError(oauth.ErrServerError, "erro ao consultar o token")
+	}
+
+	if !canRevoke(command.Requester, token) {
+		return oauth.NewError(oauth.ErrUnauthorizedClient, "o token não pertence ao solicitante")
+	}
+	if !token.Active {
+		return nil
+	}
+
+	if err := h.revoke(token); err != nil {
+		return oauth.NewError(oauth.ErrServerError, "falha ao revogar o token")
+	}
+	return nil
+}
+
+func canRevoke(requester *models.Principal, token *introspection.Token) bool {
+	switch {
+	case requester == nil:
+		return false
+	case requester.HasPermission(models.PermissionTokensRevoke):
+		return true
+	case requester.ClientID != "":
+		return token.ClientID == requester.ClientID
+	case requester.APIKeyID != "":
+		return token.Type == oauth.TokenTypeAPIKey && token.ID == requester.APIKeyID
+	default:
+		return false
+	}
+}
+
+// revoke invalida o token; tokens de acesso e refresh tokens levam junto a família de refresh tokens
+func (h *RevokeTokenHandler) revoke(token *introspection.Token) error {
+	switch token.Type {
+	case oauth.TokenTypeAPIKey:
+		id, err := uuid.Parse(token.ID)
+		if err != nil {
+			return err
+		}
+		_, err = h.Keys.Revoke(id, token.UserID, time.Now())
+		return err
+	case oauth.TokenTypeAccessToken:
+		if err := h.Revocations.Revoke(token.ID, token.UserID, token.ExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	if token.FamilyID == uuid.Nil {
+		return nil
+	}
+	return h.Tokens.RevokeFamily(token.FamilyID)
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/introspection"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestRevokeTokenHandler_Handle(t *testing.T) {
+	db := setupDatabase(t, &models.RefreshToken{})
+	jwt := shared.NewJWTManager("segredo-de-teste", 15*time.Minute, 24*time.Hour)
+	inspector := &introspection.Inspector{
+		JWT:           jwt,
+		Revocations:   persistence.NewMemoryTokenRevocationRepository(),
+		RefreshTokens: persistence.NewRefreshTokenRepository(db),
+	}
+	handler := RevokeTokenHandler{Inspector: inspector, Revocations: inspector.Revocations, Tokens: inspector.RefreshTokens}
+
+	issue := func(t *testing.T, clientID string) string {
+		token, err := jwt.GenerateAccessToken(shared.TokenSubject{UserID: uuid.New(), Permissions: []string{models.PermissionProfileRead}, Scope: models.PermissionProfileRead, ClientID: clientID})
+		if err != nil {
+			t.Fatalf("Erro ao gerar o token: %v", err)
+		}
+		return token
+	}
+	client := &models.Principal{ClientID: "app-a"}
+	admin := &models.Principal{UserID: uuid.New(), Permissions: []string{models.PermissionTokensRevoke}}
+
+	tests := []struct {
+		name        string
+		token       func(t *testing.T) string
+		requester   *models.Principal
+		wantErr     string
+		wantRevoked bool
+	}{
+		{"Token desconhecido é ignorado", func(t *testing.T) string { return "token-desconhecido" }, client, "", false},
+		{"Token de outro cliente", func(t *testing.T) string { return issue(t, "app-b") }, client, oauth.ErrUnauthorizedClient, false},
+		{"Token do próprio cliente", func(t *testing.T) string { return issue(t, "app-a") }, client, "", true},
+		{"Token de outro cliente com tokens:revoke", func(t *testing.T) string { return issue(t, "app-b") }, admin, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token(t)
+			err := handler.Handle(RevokeTokenCommand{Token: token, Requester: tt.requester})
+			if oauthErrorCode(err) != tt.wantErr {
+				t.Fatalf("Esperava o erro %q, obteve %v", tt.wantErr, err)
+			}
+
+			described, err := inspector.Inspect(token, "")
+			if err != nil {
+				return
+			}
+			if described.Active == tt.wantRevoked {
+				t.Errorf("Esperava token revogado = %v, obteve ativo = %v", tt.wantRevoked, described.Active)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("sessão não encontrada")
+	ErrSessionRevoked  = errors.New("sessão encerrada")
+)
+
+type TrackSessionHandler struct {
+	Sessions repository.SessionRepository
+	// Interval evita gravar a última atividade a cada requisição; zero grava sempre
+	Interval time.Duration
+}
+
+// TrackSessionCommand representa uma requisição autenticada por um token de acesso da família informada
+type TrackSessionCommand struct {
+	FamilyID uuid.UUID `json:"-"`
+	At       time.Time `json:"-"`
+}
+
+// Handle recusa os tokens de sessões revogadas e registra a última atividade da sessão.
+// Famílias sem sessão registrada, como as dos clientes OAuth, são aceitas.
+func (h *TrackSessionHandler) Handle(command TrackSessionCommand) error {
+	if command.FamilyID == uuid.Nil {
+		return nil
+	}
+
+	session, err := h.Sessions.FindByFamilyID(command.FamilyID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if session.IsRevoked() {
+		return ErrSessionRevoked
+	}
+
+	if command.At.Sub(session.LastSeenAt) < h.Interval {
+		return nil
+	}
+	return h.Sessions.Touch(session.ID, command.At)
+}
+
+type RevokeSessionHandler struct {
+	Sessions repository.SessionRepository
+	Tokens   repository.RefreshTokenRepository
+}
+
+// RevokeSessionCommand representa o encerramento de uma sessão do usuário informado
+type RevokeSessionCommand struct {
+	UserID    uuid.UUID         `json:"-"`
+	SessionID uuid.UUID         `json:"-"`
+	Requester *models.Principal `json:"-"`
+}
+
+// Validate realiza validações básicas no comando RevokeSessionCommand
+func (c *RevokeSessionCommand) Validate() error {
+	if c.UserID == uuid.Nil || c.SessionID == uuid.Nil {
+		return errors.New("sessão inválida")
+	}
+	return nil
+}
+
+// Handle revoga a sessão e a família de refresh tokens dela. Os tokens de acesso da sessão passam a ser
+// recusados pelo middleware de autenticação. Sessões de outros usuários exigem a permissão sessions:write.
+func (h *RevokeSessionHandler) Handle(command RevokeSessionCommand) error {
+	if !canAccessSessions(command.Requester, command.UserID, models.PermissionSessionsWrite) {
+		return models.ErrAccessDenied
+	}
+
+	session, err := h.Sessions.FindByID(command.SessionID)
+	if errors.Is(err, repository.ErrSessionNotFound) || (err == nil && session.UserID != command.UserID) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return errors.New("erro ao buscar a sessão")
+	}
+
+	if _, err := h.Sessions.Revoke(session.ID, time.Now()); err != nil {
+		return errors.New("falha ao encerrar a sessão")
+	}
+	if err := h.Tokens.RevokeFamily(session.FamilyID); err != nil {
+		return errors.New("falha ao encerrar a sessão")
+	}
+	return nil
+}
+
+// canAccessSessions libera as sessões do próprio usuário e, com a permissão informada, as de qualquer usuário
+func canAccessSessions(requester *models.Principal, userID uuid.UUID, permission string) bool {
+	if requester == nil {
+		return false
+	}
+	return requester.UserID == userID || requester.HasPermission(permission)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestTrackSessionHandler_Handle(t *testing.T) {
+	sessions := persistence.NewSessionRepository(setupDatabase(t, &models.Session{}))
+	handler := TrackSessionHandler{Sessions: sessions}
+
+	active := &models.Session{UserID: uuid.New(), FamilyID: uuid.New(), LastSeenAt: time.Now().Add(-time.Hour)}
+	revokedAt := time.Now()
+	revoked := &models.Session{UserID: uuid.New(), FamilyID: uuid.New(), LastSeenAt: time.Now(), RevokedAt: &revokedAt}
+	sessions.Store(active)
+	sessions.Store(revoked)
+
+	tests := []struct {
+		name     string
+		familyID uuid.UUID
+		wantErr  error
+	}{
+		{"Sessão ativa", active.FamilyID, nil},
+		{"Sessão revogada", revoked.FamilyID, ErrSessionRevoked},
+		{"Família sem sessão registrada", uuid.New(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := handler.Handle(TrackSessionCommand{FamilyID: tt.familyID, At: time.Now()}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Esperava %v, obteve %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRevokeSessionHandler_Handle(t *testing.T) {
+	db := setupDatabase(t, &models.Session{}, &models.RefreshToken{})
+	sessions := persistence.NewSessionRepository(db)
+	handler := RevokeSessionHandler{Sessions: sessions, Tokens: persistence.NewRefreshTokenRepository(db)}
+
+	owner := uuid.New()
+	intruder := &models.Principal{UserID: uuid.New()}
+	newSession := func() *models.Session {
+		session := &models.Session{UserID: owner, FamilyID: uuid.New(), LastSeenAt: time.Now()}
+		sessions.Store(session)
+		return session
+	}
+
+	tests := []struct {
+		name      string
+		requester *models.Principal
+		userID    uuid.UUID
+		wantErr   error
+	}{
+		{"Outro usuário sem sessions:write", intruder, owner, models.ErrAccessDenied},
+		// O solicitante informa o próprio ID na rota com a sessão de outro usuário
+		{"Sessão de outro usuário na rota do solicitante", intruder, intruder.UserID, ErrSessionNotFound},
+		{"O próprio usuário", &models.Principal{UserID: owner}, owner, nil},
+		{"Administrador com sessions:write", &models.Principal{UserID: uuid.New(), Permissions: []string{models.PermissionSessionsWrite}}, owner, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newSession()
+			err := handler.Handle(RevokeSessionCommand{UserID: tt.userID, SessionID: session.ID, Requester: tt.requester})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Esperava %v, obteve %v", tt.wantErr, err)
+			}
+
+			stored, _ := sessions.FindByID(session.ID)
+			if stored.IsRevoked() != (tt.wantErr == nil) {
+				t.Errorf("Estado da sessão inesperado: revogada = %v", stored.IsRevoked())
+			}
+		})
+	}
+}
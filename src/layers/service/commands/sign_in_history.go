@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"github.com/google/uuid"
+	"log"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+// maxUserAgentLength limita o user agent gravado nas sessões e no histórico
+const maxUserAgentLength = 512
+
+// startSession registra a sessão da nova família de refresh tokens e o login bem-sucedido no histórico.
+// Os registros são opcionais (repositórios nil) e uma falha ao gravá-los não impede o login.
+func startSession(sessions repository.SessionRepository, history repository.SignInHistoryRepository, userID, familyID uuid.UUID, method, ip, userAgent string) {
+	userAgent = truncateUserAgent(userAgent)
+	event := &models.SignInEvent{UserID: userID, Method: method, Success: true, IP: ip, UserAgent: userAgent}
+
+	if sessions != nil {
+		session := &models.Session{UserID: userID, FamilyID: familyID, IP: ip, UserAgent: userAgent, LastSeenAt: time.Now()}
+		if err := sessions.Store(session); err != nil {
+			log.Printf("falha ao registrar a sessão do usuário %s: %v", userID, err)
+		} else {
+			event.SessionID = &session.ID
+		}
+	}
+
+	recordSignIn(history, event)
+}
+
+// recordFailedSignIn registra no histórico uma tentativa recusada em uma conta existente
+func recordFailedSignIn(history repository.SignInHistoryRepository, userID uuid.UUID, method, reason, ip, userAgent string) {
+	recordSignIn(history, &models.SignInEvent{
+		UserID:        userID,
+		Method:        method,
+		FailureReason: reason,
+		IP:            ip,
+		UserAgent:     truncateUserAgent(userAgent),
+	})
+}
+
+func recordSignIn(history repository.SignInHistoryRepository, event *models.SignInEvent) {
+	if history == nil {
+		return
+	}
+	if err := history.Store(event); err != nil {
+		log.Printf("falha ao registrar o login do usuário %s: %v", event.UserID, err)
+	}
+}
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
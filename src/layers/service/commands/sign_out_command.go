@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+type SignOutHandler struct {
+	Tokens      repository.RefreshTokenRepository
+	Revocations repository.TokenRevocationRepository
+	JWT         *shared.JWTManager
+}
+
+// SignOutCommand representa a intenção de encerrar a sessão atual ou todas as sessões do usuário
+type SignOutCommand struct {
+	Token       string `json:"-"`
+	AllSessions bool   `json:"-"`
+}
+
+// Validate realiza validações básicas no comando SignOutCommand
+func (c *SignOutCommand) Validate() error {
+	if c.Token == "" {
+		return errors.New("Token é necessário")
+	}
+	return nil
+}
+
+// Handle revoga o token de acesso informado e a família de refresh tokens correspondente.
+// Com AllSessions, revoga todos os tokens já emitidos para o usuário.
+func (h *SignOutHandler) Handle(command SignOutCommand) error {
+	claims, err := h.JWT.Verify(command.Token)
+	if err != nil {
+		return errors.New("token inválido")
+	}
+
+	if command.AllSessions {
+		now := time.Now()
+		if err := h.Revocations.RevokeAllForUser(claims.UserID, now, now.Add(h.JWT.TokenDuration())); err != nil {
+			return errors.New("falha ao encerrar as sessões")
+		}
+		if err := h.Tokens.RevokeAllForUser(claims.UserID); err != nil {
+			return errors.New("falha ao encerrar as sessões")
+		}
+		return nil
+	}
+
+	if err := h.Revocations.Revoke(claims.Id, claims.UserID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return errors.New("falha ao encerrar a sessão")
+	}
+	if err := h.Tokens.RevokeFamily(claims.FamilyID); err != nil {
+		return errors.New("falha ao encerrar a sessão")
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"github.com/google/uuid"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// twoFactorChallengeDuration é o prazo para informar o segundo fator após a senha ser aceita
+	twoFactorChallengeDuration = 5 * time.Minute
+	recoveryCodeCount          = 10
+	recoveryCodeSize           = 6 // bytes aleatórios por código, 10 caracteres em base32
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("autenticação em dois fatores já está ativa")
+	ErrTwoFactorNotEnrolled    = errors.New("autenticação em dois fatores não foi iniciada")
+	ErrInvalidTwoFactorCode    = errors.New("código de verificação inválido")
+)
+
+// TwoFactorRequiredError indica que a senha foi aceita, mas o login só é concluído com o segundo fator.
+type TwoFactorRequiredError struct {
+	Challenge TwoFactorChallenge
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "autenticação em dois fatores necessária"
+}
+
+// TwoFactorChallenge é devolvido no lugar do TokenResponse quando o usuário tem o segundo fator ativo
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool      `json:"TwoFactorRequired"`
+	ChallengeToken    string    `json:"ChallengeToken"`
+	ExpiresAt         time.Time `json:"ExpiresAt"`
+}
+
+// TwoFactorEnrollment contém o segredo e a URI otpauth para cadastro no aplicativo autenticador
+type TwoFactorEnrollment struct {
+	Secret string `json:"Secret"`
+	URI    string `json:"URI"`
+}
+
+// RecoveryCodesResponse lista os códigos de recuperação, exibidos uma única vez
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"RecoveryCodes"`
+}
+
+type EnrollTwoFactorHandler struct {
+	Repo   repository.UserRepository
+	Issuer string
+}
+
+// EnrollTwoFactorCommand representa o início do cadastro do segundo fator
+type EnrollTwoFactorCommand struct {
+	UserID uuid.UUID `json:"-"`
+}
+
+// Validate realiza validações básicas no comando EnrollTwoFactorCommand
+func (c *EnrollTwoFactorCommand) Validate() error {
+	if c.UserID == uuid.Nil {
+		return errors.New("ID é necessário")
+	}
+	return nil
+}
+
+// Handle gera um novo segredo TOTP, que fica pendente até ser confirmado com um código válido
+func (h *EnrollTwoFactorHandler) Handle(command EnrollTwoFactorCommand) (*TwoFactorEnrollment, error) {
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := shared.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("erro ao gerar o segredo")
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := h.Repo.Update(user); err != nil {
+		return nil, errors.New("erro ao atualizar o usuário")
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    shared.TOTPURI(h.Issuer, user.CPF, secret),
+	}, nil
+}
+
+type ConfirmTwoFactorHandler struct {
+	Repo          repository.UserRepository
+	RecoveryCodes repository.RecoveryCodeRepository
+	Hasher        shared.PasswordHasher
+}
+
+// ConfirmTwoFactorCommand representa a confirmação do cadastro com um código do aplicativo autenticador
+type ConfirmTwoFactorCommand struct {
+	UserID uuid.UUID `json:"-"`
+	Code   string    `json:"Code"`
+}
+
+// Validate realiza validações básicas no comando ConfirmTwoFactorCommand
+func (c *ConfirmTwoFactorCommand) Validate() error {
+	if c.Code == "" {
+		return errors.New("Code é necessário")
+	}
+	return nil
+}
+
+// Handle ativa o segundo fator e gera os códigos de recuperação
+func (h *ConfirmTwoFactorHandler) Handle(command ConfirmTwoFactorCommand) (*RecoveryCodesResponse, error) {
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := shared.VerifyTOTP(user.TOTPSecret, command.Code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, records, err := h.generateRecoveryCodes()
+	if err != nil {
+		return nil, hasherError(err, "erro ao gerar os códigos de recuperação")
+	}
+
+	if err := h.RecoveryCodes.ReplaceForUser(user.ID, records); err != nil {
+		return nil, errors.New("erro ao gravar os códigos de recuperação")
+	}
+
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	if err := h.Repo.Update(user); err != nil {
+		return nil, errors.New("erro ao atualizar o usuário")
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// generateRecoveryCodes cria os códigos em texto puro e os registros com os respectivos hashes
+func (h *ConfirmTwoFactorHandler) generateRecoveryCodes() ([]string, []*models.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*models.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		buffer := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(buffer); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buffer))
+		hash, err := h.Hasher.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+		records = append(records, &models.RecoveryCode{CodeHash: hash})
+	}
+
+	return codes, records, nil
+}
+
+type CompleteTwoFactorHandler struct {
+	Repo          repository.UserRepository
+	RecoveryCodes repository.RecoveryCodeRepository
+	Hasher        shared.PasswordHasher
+	// SignIn verifica o desafio, emite os tokens como no login com senha e controla as tentativas pelo seu Limiter
+	SignIn *CreateTokenHandler
+}
+
+// CompleteTwoFactorCommand conclui o login com o desafio recebido em /sign-in e um código TOTP ou de recuperação
+type CompleteTwoFactorCommand struct {
+	ChallengeToken string `json:"ChallengeToken"`
+	Code           string `json:"Code"`
+	RecoveryCode   string `json:"RecoveryCode"`
+	IP             string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+// Validate realiza validações básicas no comando CompleteTwoFactorCommand
+func (c *CompleteTwoFactorCommand) Validate() error {
+	if c.ChallengeToken == "" {
+		return errors.New("ChallengeToken é necessário")
+	}
+	if c.Code == "" && c.RecoveryCode == "" {
+		return errors.New("Code ou RecoveryCode é necessário")
+	}
+	return nil
+}
+
+// Handle valida o desafio e o segundo fator e emite os tokens da nova sessão
+func (h *CompleteTwoFactorHandler) Handle(command CompleteTwoFactorCommand) (*TokenResponse, error) {
+	claims, err := h.SignIn.JWT.VerifyChallenge(command.ChallengeToken)
+	if err != nil {
+		return nil, errors.New("desafio inválido ou expirado")
+	}
+
+	user, err := h.Repo.FindByID(claims.UserID)
+	if err != nil || user == nil || !user.TOTPEnabled {
+		return nil, errors.New("desafio inválido ou expirado")
+	}
+
+	// O código é contado como falha antes da verificação, para que requisições paralelas não escapem do bloqueio
+	if err := reserveAttempt(h.SignIn.Limiter, user.CPF, command.IP); err != nil {
+		return nil, err
+	}
+
+	var verified bool
+	method := models.SignInMethodTOTP
+	if command.Code != "" {
+		verified, err = h.verifyCode(user, command.Code)
+	} else {
+		method = models.SignInMethodRecoveryCode
+		verified, err = h.useRecoveryCode(user, command.RecoveryCode)
+	}
+	if err != nil {
+		releaseAttempt(h.SignIn.Limiter, user.CPF, command.IP)
+		return nil, err
+	}
+
+	if !verified {
+		recordFailedSignIn(h.SignIn.History, user.ID, method, models.SignInFailureInvalidCode, command.IP, command.UserAgent)
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := recordSuccessfulAttempt(h.SignIn.Limiter, user.CPF, command.IP); err != nil {
+		return nil, err
+	}
+
+	return h.SignIn.StartSession(user, method, command.IP, command.UserAgent)
+}
+
+// verifyCode confere o código TOTP e registra o intervalo usado para que ele não seja aceito novamente.
+// O registro só avança o intervalo, de modo que requisições paralelas com o mesmo código aceitam apenas uma.
+func (h *CompleteTwoFactorHandler) verifyCode(user *models.User, code string) (bool, error) {
+	step, ok := shared.VerifyTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	advanced, err := h.Repo.AdvanceTOTPStep(user.ID, step)
+	if err != nil {
+		return false, errors.New("erro ao atualizar o usuário")
+	}
+	return advanced, nil
+}
+
+// useRecoveryCode procura um código de recuperação correspondente e o consome
+func (h *CompleteTwoFactorHandler) useRecoveryCode(user *models.User, code string) (bool, error) {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	records, err := h.RecoveryCodes.FindUnusedByUser(user.ID)
+	if err != nil {
+		return false, errors.New("falha ao consultar os códigos de recuperação")
+	}
+
+	for _, record := range records {
+		match, err := h.Hasher.VerifyPassword(normalized, record.CodeHash)
+		if errors.Is(err, shared.ErrHasherBusy) {
+			return false, err
+		}
+		if match {
+			marked, err := h.RecoveryCodes.MarkUsed(record.ID)
+			if err != nil {
+				return false, errors.New("falha ao consumir o código de recuperação")
+			}
+			return marked, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"errors"
+	"server/src/commons/shared"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+// challengeFor faz o login com senha de um usuário com o segundo fator ativo e retorna o desafio recebido
+func challengeFor(t *testing.T, signIn *CreateTokenHandler, cpf, password string) string {
+	t.Helper()
+
+	_, err := signIn.Handle(CreateTokenCommand{CPF: cpf, Password: password})
+	var required *TwoFactorRequiredError
+	if !errors.As(err, &required) {
+		t.Fatalf("Esperava o desafio do segundo fator, obteve %v", err)
+	}
+	return required.Challenge.ChallengeToken
+}
+
+func TestCompleteTwoFactorHandler_CodeIsSingleUse(t *testing.T) {
+	users := repository.NewMockUserRepository()
+	signIn := newTestCreateTokenHandler(t, users)
+	// As sessões ativas são as que ainda têm refresh token válido, por isso ficam no mesmo banco
+	db := setupDatabase(t, &models.RefreshToken{}, &models.Session{})
+	signIn.Tokens = persistence.NewRefreshTokenRepository(db)
+	signIn.Sessions = persistence.NewSessionRepository(db)
+	handler := &CompleteTwoFactorHandler{Repo: users, Hasher: signIn.Hasher, SignIn: signIn}
+
+	user := newTestUser(t, users, signIn.Hasher, "52998224725", "Senha-Correta-123")
+	secret, err := shared.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Erro ao gerar o segredo TOTP: %v", err)
+	}
+	user.TOTPSecret, user.TOTPEnabled = secret, true
+	code, _ := shared.TOTPCode(secret, time.Now())
+
+	response, err := handler.Handle(CompleteTwoFactorCommand{ChallengeToken: challengeFor(t, signIn, user.CPF, "Senha-Correta-123"), Code: code})
+	if err != nil {
+		t.Fatalf("Esperava concluir o login com o código, obteve %v", err)
+	}
+	if response.Key.Token == "" || response.Key.RefreshToken == "" {
+		t.Fatalf("Esperava os tokens da nova sessão, obteve %+v", response)
+	}
+
+	sessions, _ := signIn.Sessions.FindActiveByUser(user.ID, time.Now())
+	if len(sessions) != 1 {
+		t.Fatalf("Esperava uma sessão registrada pelo login com segundo fator, obteve %d", len(sessions))
+	}
+
+	// O mesmo código, já usado, é recusado em um novo desafio
+	_, err = handler.Handle(CompleteTwoFactorCommand{ChallengeToken: challengeFor(t, signIn, user.CPF, "Senha-Correta-123"), Code: code})
+	if !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("Esperava recusar o código reutilizado, obteve %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/lockout"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+)
+
+type UnlockUserHandler struct {
+	Repo    repository.UserRepository
+	Limiter *lockout.Limiter
+}
+
+// UnlockUserCommand representa a intenção de liberar uma conta bloqueada por excesso de tentativas
+type UnlockUserCommand struct {
+	UserID    uuid.UUID         `json:"-"`
+	Requester *models.Principal `json:"-"`
+}
+
+// Validate realiza validações básicas no comando UnlockUserCommand
+func (c *UnlockUserCommand) Validate() error {
+	if c.UserID == uuid.Nil {
+		return errors.New("ID é necessário")
+	}
+	return nil
+}
+
+// Handle remove o bloqueio e as falhas acumuladas da conta e exige a permissão users:write
+func (h *UnlockUserHandler) Handle(command UnlockUserCommand) error {
+	if command.Requester == nil || !command.Requester.HasPermission(models.PermissionUsersWrite) {
+		return models.ErrAccessDenied
+	}
+
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	if err := h.Limiter.Unlock(user.CPF); err != nil {
+		return errors.New("erro ao desbloquear o usuário")
+	}
+
+	return nil
+}
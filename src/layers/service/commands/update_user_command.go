@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/policy"
+	"server/src/layers/domain/repository"
+	"strings"
+)
+
+type UpdateUserHandler struct {
+	Repo   repository.UserRepository
+	Policy *policy.Engine
+}
+
+// UpdateUserCommand representa a intenção de alterar os dados de perfil de um usuário.
+// Campos nulos são mantidos inalterados.
+type UpdateUserCommand struct {
+	UserID    uuid.UUID         `json:"-"`
+	FirstName *string           `json:"FirstName"`
+	LastName  *string           `json:"LastName"`
+	Requester *models.Principal `json:"-"`
+}
+
+// Validate realiza validações básicas no comando UpdateUserCommand
+func (c *UpdateUserCommand) Validate() error {
+	if c.FirstName == nil && c.LastName == nil {
+		return errors.New("ao menos um campo deve ser informado")
+	}
+	if c.FirstName != nil && strings.TrimSpace(*c.FirstName) == "" {
+		return errors.New("Nome deve ser informado")
+	}
+	if c.LastName != nil && strings.TrimSpace(*c.LastName) == "" {
+		return errors.New("Sobrenome deve ser informado")
+	}
+	return nil
+}
+
+// Handle altera o perfil se as políticas permitirem a alteração do registro
+func (h *UpdateUserHandler) Handle(command UpdateUserCommand) (*models.User, error) {
+	if command.Requester == nil {
+		return nil, models.ErrAccessDenied
+	}
+
+	user, err := h.Repo.FindByID(command.UserID)
+	if err != nil || user == nil {
+		resource := policy.Resource{Type: policy.ResourceUser, ID: command.UserID.String()}
+		if authErr := h.Policy.Authorize(command.Requester, policy.ActionUsersUpdate, resource); authErr != nil {
+			return nil, authErr
+		}
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	if err := h.Policy.Authorize(command.Requester, policy.ActionUsersUpdate, policy.UserResource(user)); err != nil {
+		return nil, err
+	}
+
+	if command.FirstName != nil {
+		user.FirstName = strings.TrimSpace(*command.FirstName)
+	}
+	if command.LastName != nil {
+		user.LastName = strings.TrimSpace(*command.LastName)
+	}
+
+	if err := h.Repo.Update(user); err != nil {
+		return nil, errors.New("erro ao atualizar o usuário")
+	}
+
+	return user, nil
+}
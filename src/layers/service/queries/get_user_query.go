@@ -4,52 +4,90 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"server/src/layers/domain/models"
+	"server/src/layers/domain/policy"
 	"server/src/layers/domain/repository"
 )
 
 type GetUserQueryHandler struct {
-	Repo repository.UserRepository
+	Repo   repository.UserRepository
+	Policy *policy.Engine
 }
 
 // GetUserByIDQuery representa a consulta para obter um usuário pelo ID
 type GetUserByIDQuery struct {
-	UserID uuid.UUID `json:"ID"`
+	UserID    uuid.UUID         `json:"ID"`
+	Requester *models.Principal `json:"-"` // usuário autenticado que realiza a consulta
 }
 
 // GetUserByCPFQuery representa a consulta para obter um usuário pelo cpf
 type GetUserByCPFQuery struct {
-	CPF string `json:"Cpf"`
+	CPF       string            `json:"Cpf"`
+	Requester *models.Principal `json:"-"`
 }
 
 // GetAllUsersQuery representa uma consulta para obter todos os usuários
 type GetAllUsersQuery struct {
-	Limit  int `json:"Limit"`  // limita o número de resultados retornados
-	Offset int `json:"Offset"` // permite paginação dos resultados
+	Limit     int               `json:"Limit"`  // limita o número de resultados retornados
+	Offset    int               `json:"Offset"` // permite paginação dos resultados
+	Requester *models.Principal `json:"-"`
 }
 
+// GetUserByIDHandle retorna o usuário se as políticas permitirem a leitura do registro
 func (g *GetUserQueryHandler) GetUserByIDHandle(query GetUserByIDQuery) (*models.User, error) {
+	if query.Requester == nil {
+		return nil, models.ErrAccessDenied
+	}
+
 	user, err := g.Repo.FindByID(query.UserID)
 	if err != nil {
+		// Sem acesso ao registro, não revela se o usuário existe
+		resource := policy.Resource{Type: policy.ResourceUser, ID: query.UserID.String()}
+		if authErr := g.Policy.Authorize(query.Requester, policy.ActionUsersRead, resource); authErr != nil {
+			return nil, authErr
+		}
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return nil, errors.New("usuário não encontrado")
 		}
 		return nil, errors.New("erro interno do servidor")
 	}
+
+	if err := g.Policy.Authorize(query.Requester, policy.ActionUsersRead, policy.UserResource(user)); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+// GetUserByCPFHandle retorna o usuário se as políticas permitirem a leitura do registro
 func (g *GetUserQueryHandler) GetUserByCPFHandle(query GetUserByCPFQuery) (*models.User, error) {
+	if query.Requester == nil {
+		return nil, models.ErrAccessDenied
+	}
+
 	user, err := g.Repo.FindByCPF(query.CPF)
 	if err != nil {
+		// Sem acesso ao registro, não revela se o cpf existe
+		resource := policy.Resource{Type: policy.ResourceUser}
+		if authErr := g.Policy.Authorize(query.Requester, policy.ActionUsersRead, resource); authErr != nil {
+			return nil, authErr
+		}
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return nil, errors.New("usuário não encontrado")
 		}
 		return nil, errors.New("erro interno do servidor")
 	}
+
+	if err := g.Policy.Authorize(query.Requester, policy.ActionUsersRead, policy.UserResource(user)); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+// GetAllUsersHandle lista os usuários se as políticas permitirem a listagem
 func (g *GetUserQueryHandler) GetAllUsersHandle(query GetAllUsersQuery) ([]*models.User, error) {
+	if err := g.Policy.Authorize(query.Requester, policy.ActionUsersList, policy.Resource{Type: policy.ResourceUser}); err != nil {
+		return nil, err
+	}
+
 	users, err := g.Repo.FindAllWithPagination(query.Limit, query.Offset)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,69 @@
+package queries
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/introspection"
+	"server/src/layers/domain/models"
+)
+
+type IntrospectTokenQueryHandler struct {
+	Inspector *introspection.Inspector
+}
+
+// IntrospectTokenQuery representa a consulta do estado de um token pelo /oauth/introspect (RFC 7662)
+type IntrospectTokenQuery struct {
+	Token         string            `json:"token"`
+	TokenTypeHint string            `json:"token_type_hint"`
+	Requester     *models.Principal `json:"-"`
+}
+
+// TokenIntrospection é a resposta da RFC 7662. Tokens inativos ou desconhecidos informam apenas active=false
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	Audience  string `json:"aud,omitempty"`
+}
+
+// Handle descreve o token se o solicitante tiver a permissão tokens:introspect
+func (h *IntrospectTokenQueryHandler) Handle(query IntrospectTokenQuery) (*TokenIntrospection, error) {
+	if query.Requester == nil || !query.Requester.HasPermission(models.PermissionTokensIntrospect) {
+		return nil, models.ErrAccessDenied
+	}
+
+	token, err := h.Inspector.Inspect(query.Token, query.TokenTypeHint)
+	if errors.Is(err, introspection.ErrUnknownToken) {
+		return &TokenIntrospection{Active: false}, nil
+	}
+	if err != nil {
+		return nil, errors.New("erro ao consultar o token")
+	}
+	if !token.Active {
+		return &TokenIntrospection{Active: false}, nil
+	}
+
+	described := &TokenIntrospection{
+		Active:    true,
+		Scope:     token.Scope,
+		ClientID:  token.ClientID,
+		TokenType: token.Type,
+		IssuedAt:  token.IssuedAt.Unix(),
+		TokenID:   token.ID,
+		Issuer:    token.Issuer,
+		Audience:  token.Audience,
+	}
+	if token.UserID != uuid.Nil {
+		described.Subject = token.UserID.String()
+	}
+	if !token.ExpiresAt.IsZero() {
+		described.ExpiresAt = token.ExpiresAt.Unix()
+	}
+	return described, nil
+}
@@ -0,0 +1,26 @@
+package queries
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+)
+
+type ListAPIKeysQueryHandler struct {
+	Keys repository.APIKeyRepository
+}
+
+// ListAPIKeysQuery representa a consulta das chaves de api do usuário autenticado
+type ListAPIKeysQuery struct {
+	UserID uuid.UUID `json:"-"`
+}
+
+// Handle lista as chaves do usuário, incluindo as revogadas e expiradas, sem o valor das chaves
+func (h *ListAPIKeysQueryHandler) Handle(query ListAPIKeysQuery) ([]*models.APIKey, error) {
+	keys, err := h.Keys.FindByUser(query.UserID)
+	if err != nil {
+		return nil, errors.New("erro ao listar as chaves de api")
+	}
+	return keys, nil
+}
@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"errors"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+)
+
+type ListOAuthClientsQueryHandler struct {
+	Clients repository.OAuthClientRepository
+}
+
+// ListOAuthClientsQuery representa a consulta dos clientes oauth cadastrados
+type ListOAuthClientsQuery struct{}
+
+// Handle lista os clientes cadastrados, sem os hashes dos segredos
+func (h *ListOAuthClientsQueryHandler) Handle(query ListOAuthClientsQuery) ([]*models.OAuthClient, error) {
+	clients, err := h.Clients.FindAll()
+	if err != nil {
+		return nil, errors.New("erro ao listar os clientes oauth")
+	}
+	return clients, nil
+}
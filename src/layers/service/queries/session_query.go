@@ -0,0 +1,108 @@
+package queries
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/repository"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+type ListSessionsQueryHandler struct {
+	Sessions repository.SessionRepository
+}
+
+// ListSessionsQuery representa a consulta das sessões ativas de um usuário
+type ListSessionsQuery struct {
+	UserID    uuid.UUID         `json:"-"`
+	Requester *models.Principal `json:"-"`
+}
+
+// SessionSummary é uma sessão ativa; Current indica a sessão do token usado na consulta
+type SessionSummary struct {
+	*models.Session
+	Current bool `json:"Current"`
+}
+
+// Handle lista as sessões ativas do usuário. Sessões de outros usuários exigem a permissão sessions:read
+func (h *ListSessionsQueryHandler) Handle(query ListSessionsQuery) ([]SessionSummary, error) {
+	if !canReadSessions(query.Requester, query.UserID) {
+		return nil, models.ErrAccessDenied
+	}
+
+	sessions, err := h.Sessions.FindActiveByUser(query.UserID, time.Now())
+	if err != nil {
+		return nil, errors.New("erro ao listar as sessões")
+	}
+
+	summaries := make([]SessionSummary, 0, len(sessions))
+	for _, session := range sessions {
+		summaries = append(summaries, SessionSummary{
+			Session: session,
+			Current: session.FamilyID == query.Requester.FamilyID,
+		})
+	}
+	return summaries, nil
+}
+
+type SignInHistoryQueryHandler struct {
+	History repository.SignInHistoryRepository
+}
+
+// SignInHistoryQuery representa a consulta paginada do histórico de logins de um usuário
+type SignInHistoryQuery struct {
+	UserID    uuid.UUID         `json:"-"`
+	Limit     int               `json:"Limit"`  // padrão 20, no máximo 100
+	Offset    int               `json:"Offset"` // permite paginação dos resultados
+	Requester *models.Principal `json:"-"`
+}
+
+// SignInHistoryPage é uma página do histórico de logins, incluindo as tentativas recusadas
+type SignInHistoryPage struct {
+	Items  []*models.SignInEvent `json:"Items"`
+	Total  int64                 `json:"Total"`
+	Limit  int                   `json:"Limit"`
+	Offset int                   `json:"Offset"`
+}
+
+// Handle retorna uma página do histórico. O histórico de outros usuários exige a permissão sessions:read
+func (h *SignInHistoryQueryHandler) Handle(query SignInHistoryQuery) (*SignInHistoryPage, error) {
+	if !canReadSessions(query.Requester, query.UserID) {
+		return nil, models.ErrAccessDenied
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, total, err := h.History.FindByUser(query.UserID, limit, offset)
+	if err != nil {
+		return nil, errors.New("erro ao consultar o histórico de logins")
+	}
+	if events == nil {
+		events = []*models.SignInEvent{}
+	}
+
+	return &SignInHistoryPage{Items: events, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// canReadSessions libera as sessões e o histórico do próprio usuário e, com sessions:read, os de qualquer usuário
+func canReadSessions(requester *models.Principal, userID uuid.UUID) bool {
+	if requester == nil {
+		return false
+	}
+	return requester.UserID == userID || requester.HasPermission(models.PermissionSessionsRead)
+}
@@ -0,0 +1,73 @@
+package queries
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"server/src/layers/domain/models"
+	"server/src/layers/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestListSessionsQueryHandler_Handle(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:list_sessions?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Erro ao abrir o banco de dados: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Session{}, &models.RefreshToken{}); err != nil {
+		t.Fatalf("Erro na migração: %v", err)
+	}
+
+	sessions := persistence.NewSessionRepository(db)
+	handler := ListSessionsQueryHandler{Sessions: sessions}
+
+	ownerID := uuid.New()
+	families := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, familyID := range families {
+		if err := sessions.Store(&models.Session{UserID: ownerID, FamilyID: familyID, LastSeenAt: time.Now()}); err != nil {
+			t.Fatalf("Erro ao registrar a sessão: %v", err)
+		}
+		db.Create(&models.RefreshToken{UserID: ownerID, FamilyID: familyID, TokenID: uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour)})
+	}
+
+	t.Run("Dono lista as próprias sessões e a atual", func(t *testing.T) {
+		summaries, err := handler.Handle(ListSessionsQuery{UserID: ownerID, Requester: &models.Principal{UserID: ownerID, FamilyID: families[0]}})
+		if err != nil || len(summaries) != 2 {
+			t.Fatalf("Esperado 2 sessões, obteve %d (%v)", len(summaries), err)
+		}
+
+		current := 0
+		for _, summary := range summaries {
+			if summary.Current {
+				current++
+				if summary.FamilyID != families[0] {
+					t.Errorf("Esperado que a sessão atual fosse a do token, obteve a família %s", summary.FamilyID)
+				}
+			}
+		}
+		if current != 1 {
+			t.Errorf("Esperado exatamente uma sessão atual, obteve %d", current)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		requester *models.Principal
+		expected  error
+	}{
+		{"Sem principal", nil, models.ErrAccessDenied},
+		{"Outro usuário sem sessions:read", &models.Principal{UserID: uuid.New(), Permissions: models.RolePermissions[models.RoleUser]}, models.ErrAccessDenied},
+		{"Outro usuário com sessions:read", &models.Principal{UserID: uuid.New(), Permissions: []string{models.PermissionSessionsRead}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.Handle(ListSessionsQuery{UserID: ownerID, Requester: tt.requester})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Esperado erro %v, obteve: %v", tt.expected, err)
+			}
+		})
+	}
+}
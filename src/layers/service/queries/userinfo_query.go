@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"server/src/layers/domain/models"
+	"server/src/layers/domain/oauth"
+	"server/src/layers/domain/repository"
+)
+
+type UserInfoQueryHandler struct {
+	Repo repository.UserRepository
+}
+
+// UserInfoQuery representa a consulta do /userinfo com o token de acesso de um cliente OAuth
+type UserInfoQuery struct {
+	Requester *models.Principal `json:"-"`
+}
+
+// Handle retorna as claims de identidade liberadas pelos escopos do token, que precisa incluir openid
+func (h *UserInfoQueryHandler) Handle(query UserInfoQuery) (*oauth.UserInfo, error) {
+	scopes := oauth.ParseScope(query.Requester.Scope)
+	if !oauth.ContainsAll(scopes, []string{oauth.ScopeOpenID}) {
+		return nil, oauth.NewError(oauth.ErrInsufficientScope, "o token não inclui o escopo openid")
+	}
+
+	user, err := h.Repo.FindByID(query.Requester.UserID)
+	if err != nil || user == nil {
+		return nil, oauth.NewError(oauth.ErrInvalidToken, "usuário não encontrado")
+	}
+
+	info := oauth.UserInfoFor(user, scopes)
+	return &info, nil
+}